@@ -0,0 +1,63 @@
+package bitarray
+
+import "context"
+
+// AcquireN allocates n free bits like n calls of MarkFree and returns their
+// indexes, all of them or none: if fewer than n bits can be allocated, it
+// waits for bits to be freed by the other methods, without polling, until
+// ctx is done, and then returns its error. Like MarkFree, it skips the
+// fenced bits, but it bypasses the rate limit and the instrumentation.
+// It returns nil for a non-positive n.
+func (b *BitArray) AcquireN(ctx context.Context, n int) ([]int64, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	for {
+		b.lock()
+
+		if b.allocatable() >= int64(n) {
+			res := make([]int64, n)
+
+			for k := range res {
+				index, cursor := b.peek()
+				b.advance(cursor)
+				b.claim(index)
+				res[k] = b.out(index)
+			}
+
+			b.unlock()
+
+			return res, nil
+		}
+
+		if b.released == nil {
+			b.released, b.wanted = make(chan struct{}), int64(n)
+		} else {
+			b.wanted = min(b.wanted, int64(n))
+		}
+
+		released := b.released
+		b.unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// allocatable returns the number of bits MarkFree can allocate: the clear
+// bits outside the fence. Callers hold the read lock.
+func (b *BitArray) allocatable() int64 {
+	n := b.capacity.Get64() - b.count.Get64()
+
+	for _, r := range b.fence {
+		if from, to := b.clamp(r.From, r.To); from < to {
+			n -= (to - from) - b.countRange(from, to)
+		}
+	}
+
+	return n
+}
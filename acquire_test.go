@@ -0,0 +1,66 @@
+package bitarray
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayAcquireN(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10)
+
+	res, err := b.AcquireN(context.Background(), 4)
+	assert.NoError(err)
+	assert.Equal([]int64{0, 1, 2, 3}, res)
+
+	res, err = b.AcquireN(context.Background(), 0)
+	assert.NoError(err)
+	assert.Nil(res)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	res, err = b.AcquireN(ctx, 7)
+	assert.True(errors.Is(err, context.DeadlineExceeded))
+	assert.Nil(res)
+	assert.Equal(4, b.Len())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Mark(9) // not enough
+		time.Sleep(10 * time.Millisecond)
+		b.UnmarkAll(1, 2)
+	}()
+
+	res, err = b.AcquireN(context.Background(), 7)
+	assert.NoError(err)
+	assert.Equal([]int64{1, 2, 4, 5, 6, 7, 8}, res)
+	assert.True(b.IsFull())
+}
+
+func TestBitArrayAcquireNFenced(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10, WithBaseOffset(100))
+	b.Exclude(0, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := b.AcquireN(ctx, 6)
+	assert.True(errors.Is(err, context.Canceled))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Include(0, 1)
+	}()
+
+	res, err := b.AcquireN(context.Background(), 6)
+	assert.NoError(err)
+	assert.Equal([]int64{100, 105, 106, 107, 108, 109}, res)
+}
@@ -0,0 +1,46 @@
+package bitarray
+
+import "fmt"
+
+// CacheLineSize is the cache line size of common CPUs, an alignment for
+// WithAlignment.
+const CacheLineSize = 64
+
+// WithAlignment aligns the block storage to the specified number of bytes, a
+// power of two like CacheLineSize or os.Getpagesize(), and pads it to a
+// multiple of it, so the storage of an array never shares a cache line or a
+// page with other data. For a Sharded array it keeps the shards from false
+// sharing. The storage is over-allocated by up to one alignment, also from a
+// BlockSource. Zero leaves the storage unaligned; other values that are not
+// a power of two are rejected by the constructor.
+func WithAlignment(bytes int) Option {
+	return func(c *config) {
+		c.align = int64(bytes)
+	}
+}
+
+// checkAlignment reports an error unless the alignment is zero or a power of
+// two.
+func checkAlignment(align int64) error {
+	if align < 0 || align&(align-1) != 0 {
+		return fmt.Errorf("bitarray: alignment %d is not a power of two", align)
+	}
+
+	return nil
+}
+
+// allocAligned allocates n blocks starting at a multiple of the alignment,
+// with the capacity of the slice padded to a multiple of it.
+func (b *BitArray) allocAligned(n int64) []BitBlock {
+	per := max(b.align/(blockSize/8), 1) // blocks per alignment
+	padded := (n + per - 1) / per * per
+
+	raw := b.allocRaw(padded + per)
+	off := int64(0)
+
+	if rem := int64(blocksAddr(raw) % uintptr(b.align)); rem != 0 {
+		off = (b.align - rem) / (blockSize / 8)
+	}
+
+	return raw[off : off+n : off+padded]
+}
@@ -0,0 +1,58 @@
+package bitarray
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAlignment(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, align := range []int{8, CacheLineSize, os.Getpagesize()} {
+		b := NewBitArray(1000, WithAlignment(align), WithAutoGrow())
+		assert.Zero(blocksAddr(b.blocks)%uintptr(align), align)
+		assert.Zero(int64(cap(b.blocks))*blockSize/8%int64(align), align)
+		assert.Equal(int64(len(b.blocks)), b.size)
+
+		b.Mark(999)
+		b.Mark(100000)
+		assert.Zero(blocksAddr(b.blocks)%uintptr(align), align)
+		assert.Equal("999,100000", b.FormatRanges())
+		assert.NoError(b.Validate())
+	}
+
+	opt := WithAlignment(48) // validated by the constructor
+	_, err := NewBitArrayE(100, opt)
+	assert.Error(err)
+	assert.Panics(func() { NewBitArray(100, WithAlignment(-8)) })
+	assert.NoError(NewBitArray(100, WithAlignment(0)).Validate())
+}
+
+func TestWithAlignmentSource(t *testing.T) {
+	assert := assert.New(t)
+
+	var requested []int64
+	source := func(n int64) []BitBlock {
+		requested = append(requested, n)
+		return make([]BitBlock, n)
+	}
+
+	b := NewBitArray(100, WithBlockSource(source), WithAlignment(CacheLineSize))
+	assert.Len(requested, 1)
+	assert.Greater(requested[0], b.size)
+	assert.Zero(blocksAddr(b.blocks) % CacheLineSize)
+}
+
+func TestWithAlignmentSharded(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSharded(1000, 4, WithAlignment(CacheLineSize))
+
+	for i := range s.Shards() {
+		blocks := s.Shard(i).blocks
+		assert.Zero(blocksAddr(blocks) % CacheLineSize)
+		assert.Zero(int64(cap(blocks)) * blockSize / 8 % CacheLineSize)
+	}
+}
@@ -0,0 +1,89 @@
+package bitarray
+
+import (
+	"context"
+	"fmt"
+)
+
+// ID is a constraint that permits the integer types of the IDs of an
+// Allocator, including named ones like type PortID uint32.
+type ID interface {
+	~int64 | ~int32 | ~uint32
+}
+
+// Allocator allocates IDs of type T from a BitArray, so IDs of different
+// spaces, e.g. type ConnID int64 and type PortID uint32, cannot be mixed up
+// at compile time.
+type Allocator[T ID] struct {
+	b *BitArray
+}
+
+// NewAllocator creates an Allocator of capacity IDs, the bits of a BitArray
+// created with the options like by NewBitArray. WithBaseOffset sets the first
+// ID. It panics with an error wrapping ErrCapacity if the capacity is invalid
+// or the IDs do not fit into T.
+func NewAllocator[T ID](capacity int64, opts ...Option) *Allocator[T] {
+	b := NewBitArray(capacity, opts...)
+
+	if capacity > 0 && (!fits[T](b.out(0)) || !fits[T](b.out(capacity-1))) {
+		panic(fmt.Errorf("%w: IDs %d-%d do not fit into %T", ErrCapacity, b.out(0), b.out(capacity-1), T(0)))
+	}
+
+	return &Allocator[T]{b: b}
+}
+
+// fits reports whether v is a value of T.
+func fits[T ID](v int64) bool {
+	return int64(T(v)) == v
+}
+
+// Acquire allocates a free ID like MarkFree. It returns ErrFull if there is
+// none.
+func (a *Allocator[T]) Acquire() (T, error) {
+	if index := a.b.MarkFree(); index != BitBlockNotFound {
+		return T(index), nil
+	}
+
+	return 0, ErrFull
+}
+
+// AcquireN allocates n free IDs, all of them or none, waiting for IDs to be
+// released until ctx is done like BitArray.AcquireN.
+func (a *Allocator[T]) AcquireN(ctx context.Context, n int) ([]T, error) {
+	indices, err := a.b.AcquireN(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]T, len(indices))
+	for k, index := range indices {
+		res[k] = T(index)
+	}
+
+	return res, nil
+}
+
+// Release frees the ID and reports whether it was allocated.
+func (a *Allocator[T]) Release(id T) bool {
+	return a.b.Set(int64(id), bitBlockUnmark)
+}
+
+// Held reports whether the ID is allocated.
+func (a *Allocator[T]) Held(id T) bool {
+	return a.b.Get(int64(id))
+}
+
+// Len64 returns the number of allocated IDs.
+func (a *Allocator[T]) Len64() int64 {
+	return a.b.Len64()
+}
+
+// Cap64 returns the number of IDs.
+func (a *Allocator[T]) Cap64() int64 {
+	return a.b.Cap64()
+}
+
+// BitArray returns the array holding the allocated IDs, e.g. to persist it.
+func (a *Allocator[T]) BitArray() *BitArray {
+	return a.b
+}
@@ -0,0 +1,65 @@
+package bitarray
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type listenPort uint32
+
+func TestAllocator(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewAllocator[listenPort](3, WithBaseOffset(1024))
+	assert.EqualValues(3, a.Cap64())
+
+	id, err := a.Acquire()
+	assert.NoError(err)
+	assert.Equal(listenPort(1024), id)
+	assert.True(a.Held(1024))
+
+	ids, err := a.AcquireN(context.Background(), 2)
+	assert.NoError(err)
+	assert.Equal([]listenPort{1025, 1026}, ids)
+
+	_, err = a.Acquire()
+	assert.True(errors.Is(err, ErrFull))
+	assert.EqualValues(3, a.Len64())
+
+	assert.True(a.Release(1025))
+	assert.False(a.Release(1025))
+	assert.False(a.Release(5000))
+	assert.False(a.Held(1025))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = a.AcquireN(ctx, 2)
+	assert.True(errors.Is(err, context.Canceled))
+	assert.Equal(2, a.BitArray().Len())
+}
+
+func TestAllocatorReleaseGrow(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewAllocator[listenPort](3, WithAutoGrow())
+	assert.False(a.Release(5000))
+	assert.EqualValues(3, a.Cap64())
+}
+
+func TestNewAllocatorRange(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NotPanics(func() { NewAllocator[int32](10, WithBaseOffset(math.MaxInt32-9)) })
+	assert.NotPanics(func() { NewAllocator[uint32](10, WithBaseOffset(math.MaxUint32-9)) })
+	assert.NotPanics(func() { NewAllocator[int64](0, WithBaseOffset(-1)) })
+
+	assert.Panics(func() { NewAllocator[int32](10, WithBaseOffset(math.MaxInt32-8)) })
+	assert.Panics(func() { NewAllocator[uint32](10, WithBaseOffset(math.MaxUint32-8)) })
+	assert.Panics(func() { NewAllocator[uint32](10, WithBaseOffset(-1)) })
+	assert.Panics(func() { NewAllocator[int64](-1) })
+}
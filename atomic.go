@@ -0,0 +1,212 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrConflict is returned when a bit does not have the value an operation
+// requires.
+var ErrConflict = errors.New("bitarray: conflicting bit value")
+
+// GetAndSet sets the bit at the specified index to the specified value and
+// returns its previous value, under a single lock acquisition. Indexes are
+// handled like by Set; the previous value of an index that is out of range
+// is false.
+func (b *BitArray) GetAndSet(index int64, mark bool) (previous bool) {
+	previous, _ = b.GetAndSetE(index, mark)
+	return
+}
+
+// GetAndSetE is like GetAndSet but returns the error of SetE.
+func (b *BitArray) GetAndSetE(index int64, mark bool) (previous bool, err error) {
+	b.lock()
+	defer b.unlock()
+
+	var changed bool
+
+	index = b.in(index)
+
+	if changed, err = b.set(index, mark); changed {
+		previous = !mark
+	} else if err == nil && index >= 0 && index < b.capacity.Get64() {
+		previous = mark
+	}
+
+	return
+}
+
+// CompareAndSet sets the bit at the specified index to new if its value is
+// old and reports whether it did, as a single atomic transition. If old and
+// new are equal, it only reports whether the bit has that value. Indexes are
+// handled like by Set.
+func (b *BitArray) CompareAndSet(index int64, old, new bool) (swapped bool) {
+	b.lock()
+	defer b.unlock()
+
+	index = b.in(index)
+
+	if old == new {
+		ok, _ := b.checkIndex(index, false)
+		return ok && b.bit(index) == old
+	}
+
+	swapped, _ = b.set(index, new)
+
+	return
+}
+
+// Swap exchanges the values of the bits at the specified indexes under a
+// single lock acquisition, so no reader observes both or neither of them set
+// in between. Nothing is changed unless both indexes are in range. An
+// allocation tracked by WithLeakTracking moves with its bit, like by Move.
+func (b *BitArray) Swap(i, j int64) {
+	b.lock()
+	defer b.unlock()
+
+	i, j = b.in(i), b.in(j)
+
+	if ok, _ := b.checkIndex(i, false); !ok {
+		return
+	}
+
+	if ok, _ := b.checkIndex(j, false); !ok {
+		return
+	}
+
+	if vi, vj := b.bit(i), b.bit(j); vi != vj {
+		ri, ti := b.held[i]
+		rj, tj := b.held[j]
+
+		b.set(i, vj)
+		b.set(j, vi)
+
+		if ti {
+			b.held[j] = ri
+		}
+
+		if tj {
+			b.held[i] = rj
+		}
+	}
+}
+
+// Move atomically clears the bit at index from and sets the bit at index to,
+// relocating an allocation. It returns an error wrapping ErrConflict if the
+// bit at from is clear or the bit at to is already set, and an error
+// wrapping ErrOutOfRange if an index is out of range, in which cases nothing
+// is changed. An allocation tracked by WithLeakTracking keeps its record.
+func (b *BitArray) Move(from, to int64) error {
+	b.lock()
+	defer b.unlock()
+
+	from, to = b.in(from), b.in(to)
+
+	for _, index := range [...]int64{from, to} {
+		if ok, err := b.checkIndex(index, index == to); !ok {
+			if err == nil {
+				err = ErrOutOfRange
+			}
+
+			return fmt.Errorf("%w: %d", err, index)
+		}
+	}
+
+	switch {
+	case !b.bit(from):
+		return fmt.Errorf("%w: bit %d is clear", ErrConflict, from)
+
+	case b.bit(to):
+		return fmt.Errorf("%w: bit %d is set", ErrConflict, to)
+	}
+
+	r, tracked := b.held[from]
+
+	b.set(from, bitBlockUnmark)
+	b.set(to, bitBlockMark)
+
+	if tracked {
+		b.held[to] = r
+	}
+
+	return nil
+}
+
+// TryMarkMask sets the bits of mask in the 64-bit word with the specified
+// index, like ForEachWord the bits [64*wordIndex, 64*wordIndex+64) counted
+// from the base offset, if all of them are clear, and reports whether it
+// did. It fails if a bit of the mask lies beyond the capacity or, like
+// MarkFree, in a reserved or excluded range, which lets callers claim small
+// aligned groups of bits at once. The claimed bits are tracked by
+// WithLeakTracking like allocations of MarkFree.
+func (b *BitArray) TryMarkMask(wordIndex int64, mask uint64) bool {
+	b.lock()
+	defer b.unlock()
+
+	if mask&^b.wordMask(wordIndex) != 0 || mask&b.fenceWord(wordIndex) != 0 {
+		return false
+	}
+
+	w := b.word(wordIndex)
+	if w&mask != 0 {
+		return false
+	}
+
+	b.setWord(wordIndex, w|mask)
+
+	if b.held != nil {
+		for m := mask; m != 0; m &= m - 1 {
+			b.track(wordIndex*wordSize + int64(bits.TrailingZeros64(m)))
+		}
+	}
+
+	return true
+}
+
+// fenceWord returns the bits of the 64-bit word k that MarkFree skips.
+func (b *BitArray) fenceWord(k int64) (w uint64) {
+	if b.fence == nil {
+		return 0
+	}
+
+	for n := int64(0); n < wordSize/blockSize; n++ {
+		w |= uint64(b.fence.mask(k*(wordSize/blockSize)+n)) << (n * blockSize)
+	}
+
+	return
+}
+
+// wordMask returns the bits of the 64-bit word k that lie within the
+// capacity.
+func (b *BitArray) wordMask(k int64) uint64 {
+	switch start, capacity := k*wordSize, b.capacity.Get64(); {
+	case k < 0 || start >= capacity:
+		return 0
+	case start+wordSize <= capacity:
+		return 1<<wordSize - 1
+	default:
+		return 1<<(capacity-start) - 1
+	}
+}
+
+// ApplyWord sets the bits of or and clears the bits of andNot in the 64-bit
+// word with the specified index, andNot taking precedence, and adjusts the
+// counter, e.g. to apply 64 decoded flags at once. Bits beyond the capacity
+// are ignored.
+func (b *BitArray) ApplyWord(wordIndex int64, or, andNot uint64) {
+	b.lock()
+	defer b.unlock()
+
+	m := b.wordMask(wordIndex)
+	if m == 0 {
+		return
+	}
+
+	w := b.word(wordIndex)
+	b.setWord(wordIndex, (w|or)&^andNot&m)
+
+	if i := wordIndex * (wordSize / blockSize); w&andNot != 0 && i < b.curIndex {
+		b.curIndex = i // move pointer closer to the beginning
+	}
+}
@@ -0,0 +1,188 @@
+package bitarray
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayGetAndSet(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	assert.False(b.GetAndSet(5, true))
+	assert.True(b.GetAndSet(5, true))
+	assert.True(b.GetAndSet(5, false))
+	assert.False(b.GetAndSet(5, false))
+	assert.False(b.GetAndSet(100, true))
+	assert.Equal(0, b.Len())
+
+	_, err := b.GetAndSetE(100, true)
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	g := NewBitArray(10, WithAutoGrow())
+	assert.False(g.GetAndSet(50, true))
+	assert.True(g.GetAndSet(50, true))
+}
+
+func TestBitArrayGetAndSetConcurrent(t *testing.T) {
+	b := NewBitArray(100)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		winners int
+	)
+
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if !b.GetAndSet(7, true) {
+				mu.Lock()
+				winners++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 1, winners)
+}
+
+func TestBitArrayCompareAndSet(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	assert.True(b.CompareAndSet(3, false, true))
+	assert.False(b.CompareAndSet(3, false, true))
+	assert.True(b.CompareAndSet(3, true, true))
+	assert.False(b.CompareAndSet(3, false, false))
+	assert.True(b.CompareAndSet(3, true, false))
+	assert.False(b.CompareAndSet(3, true, false))
+	assert.True(b.CompareAndSet(3, false, false))
+	assert.False(b.CompareAndSet(100, false, true))
+	assert.False(b.CompareAndSet(100, false, false))
+	assert.Equal(0, b.Len())
+}
+
+func TestBitArraySwap(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 3)
+	b.Swap(3, 70)
+	assert.Equal("70", b.FormatRanges())
+	b.Swap(3, 70)
+	assert.Equal("3", b.FormatRanges())
+	b.Swap(3, 3)
+	assert.Equal("3", b.FormatRanges())
+
+	b.Mark(4)
+	b.Swap(3, 4)
+	assert.Equal("3-4", b.FormatRanges())
+
+	b.Swap(3, 100)
+	assert.Equal("3-4", b.FormatRanges())
+	assert.Equal(2, b.Len())
+	assert.NoError(b.Validate())
+}
+
+func TestBitArraySwapHeld(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithLeakTracking())
+	assert.Equal(int64(0), b.MarkFree())
+
+	b.Swap(0, 50)
+	held := b.HeldLongerThan(0)
+	if assert.Len(held, 1) {
+		assert.Equal(int64(50), held[0].Index)
+	}
+
+	b.Swap(20, 50)
+	held = b.HeldLongerThan(0)
+	if assert.Len(held, 1) {
+		assert.Equal(int64(20), held[0].Index)
+	}
+}
+
+func TestBitArrayMove(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithLeakTracking())
+	assert.Equal(int64(0), b.MarkFree())
+	b.Mark(1)
+
+	assert.NoError(b.Move(0, 50))
+	assert.Equal("1,50", b.FormatRanges())
+
+	held := b.HeldLongerThan(0)
+	if assert.Len(held, 1) {
+		assert.Equal(int64(50), held[0].Index)
+	}
+
+	assert.True(errors.Is(b.Move(0, 60), ErrConflict))
+	assert.True(errors.Is(b.Move(1, 50), ErrConflict))
+	assert.True(errors.Is(b.Move(1, 100), ErrOutOfRange))
+	assert.True(errors.Is(b.Move(-1, 2), ErrNegativeIndex))
+	assert.Equal("1,50", b.FormatRanges())
+	assert.Equal(2, b.Len())
+}
+
+func TestBitArrayTryMarkMask(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	assert.True(b.TryMarkMask(0, 0xf))
+	assert.False(b.TryMarkMask(0, 0x18))
+	assert.True(b.TryMarkMask(0, 0xf0))
+	assert.True(b.TryMarkMask(1, 0xf<<32))
+	assert.False(b.TryMarkMask(1, 1<<36))
+	assert.False(b.TryMarkMask(2, 1))
+	assert.False(b.TryMarkMask(-1, 1))
+	assert.Equal("0-7,96-99", b.FormatRanges())
+	assert.Equal(12, b.Len())
+	assert.NoError(b.Validate())
+}
+
+func TestBitArrayTryMarkMaskFenced(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(200, WithReservedRanges(Range{64, 68}), WithLeakTracking(), WithStats())
+	b.Exclude(130, 131)
+	b.ResetStats()
+
+	assert.False(b.TryMarkMask(1, 1<<3))
+	assert.False(b.TryMarkMask(2, 1<<2))
+	assert.True(b.TryMarkMask(1, 1<<4|1<<5))
+	assert.True(b.TryMarkMask(2, 1<<3))
+	assert.Equal("64-69,131", b.FormatRanges())
+	assert.Equal(Stats{Marks: 3}, b.Stats())
+
+	held := b.HeldLongerThan(0)
+	if assert.Len(held, 3) {
+		assert.Equal(int64(68), held[0].Index)
+		assert.Contains(held[0].Caller, "TestBitArrayTryMarkMaskFenced")
+	}
+
+	b = NewBitArray(200, WithBaseOffset(1000))
+	assert.True(b.TryMarkMask(1, 1))
+	assert.True(b.Get(1064))
+}
+
+func TestBitArrayApplyWord(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 0, 1, 70)
+	b.ApplyWord(0, 0xf0, 0x1)
+	assert.Equal("1,4-7,70", b.FormatRanges())
+	b.ApplyWord(1, ^uint64(0), 1<<6)
+	assert.Equal("1,4-7,64-69,71-99", b.FormatRanges())
+	b.ApplyWord(2, 1, 0)
+	b.ApplyWord(-1, 1, 0)
+	assert.Equal(40, b.Len())
+	assert.NoError(b.Validate())
+}
@@ -0,0 +1,34 @@
+package bitarray
+
+// WithBaseOffset makes the array address its bits by external indexes
+// starting at base, e.g. port numbers from 1024 or VLAN IDs from 1, so the
+// bit of index base+i is the i-th bit of the storage. The capacity still
+// counts the bits, so an array of ports 1024-65535 is created by
+// NewBitArray(65536-1024, WithBaseOffset(1024)).
+//
+// The offset applies to the methods taking or returning a single index:
+// Set, Get, Mark, Unmark and their variants, MarkFree, PeekFree, the batch,
+// atomic and search methods, Select, the iterators, Tx, Watch and the
+// indexes reported by the leak tracking and the instrumentation. Indexes
+// below the base are rejected like negative ones. Ranges, the text formats,
+// the encodings and the other arrays produced from the array, like by
+// CloneWithCapacity or Freeze, are relative to the first bit.
+func WithBaseOffset(base int64) Option {
+	return func(c *config) {
+		c.base = base
+	}
+}
+
+// in maps an external index to the position of its bit.
+func (b *BitArray) in(index int64) int64 {
+	return index - b.base
+}
+
+// out maps the position of a bit to its external index.
+func (b *BitArray) out(index int64) int64 {
+	if index == BitBlockNotFound {
+		return index
+	}
+
+	return index + b.base
+}
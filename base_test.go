@@ -0,0 +1,65 @@
+package bitarray
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithBaseOffset(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithBaseOffset(1024))
+
+	assert.Equal(int64(1024), b.MarkFree())
+	assert.Equal(int64(1025), b.PeekFree())
+	assert.Equal("0", b.FormatRanges())
+
+	b.Mark(1030)
+	assert.True(b.Get(1030))
+	assert.False(b.Get(6))
+	assert.Equal("0,6", b.FormatRanges())
+
+	_, err := b.SetE(1000, true)
+	assert.True(errors.Is(err, ErrNegativeIndex))
+	_, err = b.SetE(1124, true)
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	assert.Equal(int64(1024), b.First())
+	assert.Equal(int64(1030), b.Last())
+	assert.Equal(int64(1025), b.FirstClear())
+	assert.Equal(int64(1030), b.Select(1))
+	assert.Equal([]int64{1024, 1030}, slices.Collect(b.SetBits()))
+	assert.Equal([]int64{1030, 1024}, slices.Collect(b.SetBitsDesc()))
+	assert.Equal([]bool{true, false}, b.GetMany([]int64{1024, 1025}))
+
+	assert.NoError(b.Move(1030, 1031))
+	assert.Equal("0,7", b.FormatRanges())
+
+	var got []int64
+	b.ForEachInRange(1025, 1040, func(index int64) bool {
+		got = append(got, index)
+		return true
+	})
+	assert.Equal([]int64{1031}, got)
+}
+
+func TestWithBaseOffsetTx(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10, WithBaseOffset(1))
+
+	assert.NoError(b.Update(func(tx *Tx) error {
+		index, err := tx.Reserve()
+		assert.Equal(int64(1), index)
+		assert.True(tx.Get(1))
+
+		assert.NoError(tx.Mark(10))
+		assert.Error(tx.Mark(11))
+
+		return err
+	}))
+	assert.Equal("0,9", b.FormatRanges())
+}
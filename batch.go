@@ -0,0 +1,158 @@
+package bitarray
+
+// MarkAll sets the bits at the specified indexes to true under a single
+// lock acquisition and returns the number of bits that actually changed.
+// Indexes out of range are handled like in Mark.
+func (b *BitArray) MarkAll(indices ...int64) int {
+	return b.setAll(indices, bitBlockMark)
+}
+
+// UnmarkAll sets the bits at the specified indexes to false under a single
+// lock acquisition and returns the number of bits that actually changed.
+// Indexes out of range are handled like in Unmark.
+func (b *BitArray) UnmarkAll(indices ...int64) int {
+	return b.setAll(indices, bitBlockUnmark)
+}
+
+func (b *BitArray) setAll(indices []int64, mark bool) (n int) {
+	b.lock()
+	defer b.unlock()
+
+	for _, index := range indices {
+		if changed, _ := b.set(b.in(index), mark); changed {
+			n++
+		}
+	}
+
+	return
+}
+
+// UnmarkMany is like UnmarkAll but clears the bits of the consecutive
+// indexes falling in the same block with a single mask and adjusts the count
+// once, which makes releasing many slots cheap, especially when indices is
+// sorted. It returns the number of bits that actually changed.
+func (b *BitArray) UnmarkMany(indices []int64) (changed int) {
+	b.lock()
+	defer b.unlock()
+
+	if b.journal != nil || b.logger != nil {
+		// both record every bit
+		for _, index := range indices {
+			if c, _ := b.set(b.in(index), bitBlockUnmark); c {
+				changed++
+			}
+		}
+
+		return
+	}
+
+	var (
+		n int64
+		m BitBlock
+	)
+
+	cur := int64(-1)
+
+	for _, index := range indices {
+		index = b.in(index)
+
+		if ok, _ := b.checkIndex(index, false); !ok {
+			continue
+		}
+
+		i, j := bitIndexAndNum(index)
+		if i != cur {
+			if m != 0 {
+				n += b.unmarkBlock(cur, m)
+			}
+
+			cur, m = i, 0
+		}
+
+		m |= mask(j)
+
+		if b.held != nil {
+			delete(b.held, index)
+		}
+	}
+
+	if m != 0 {
+		n += b.unmarkBlock(cur, m)
+	}
+
+	b.count.Add64(-n)
+
+	if b.tally != nil {
+		b.tally.unmarks.Add(n)
+	}
+
+	return int(n)
+}
+
+// unmarkBlock clears the bits of m in block i and returns how many were set.
+// The caller updates the count. Callers hold the write lock.
+func (b *BitArray) unmarkBlock(i int64, m BitBlock) int64 {
+	old := b.blocks[i]
+
+	n := (old & m).popcount()
+	if n == 0 {
+		return 0
+	}
+
+	b.blocks[i] = old &^ m
+
+	if b.counts != nil {
+		b.cache(i, -n)
+	}
+
+	if i < b.curIndex {
+		b.curIndex = i
+	}
+
+	return n
+}
+
+// GetMany returns the values of the bits at the specified indexes, read
+// under a single lock acquisition. Indexes out of range are reported as
+// false.
+func (b *BitArray) GetMany(indices []int64) []bool {
+	b.rlock()
+	defer b.runlock()
+
+	res := make([]bool, len(indices))
+
+	for n, index := range indices {
+		res[n], _ = b.get(b.in(index))
+	}
+
+	return res
+}
+
+// AnyOf reports whether any of the bits at the specified indexes is set.
+func (b *BitArray) AnyOf(indices []int64) bool {
+	b.rlock()
+	defer b.runlock()
+
+	for _, index := range indices {
+		if res, _ := b.get(b.in(index)); res {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllOf reports whether all the bits at the specified indexes are set.
+// It returns true if indices is empty.
+func (b *BitArray) AllOf(indices []int64) bool {
+	b.rlock()
+	defer b.runlock()
+
+	for _, index := range indices {
+		if res, _ := b.get(b.in(index)); !res {
+			return false
+		}
+	}
+
+	return true
+}
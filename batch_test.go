@@ -0,0 +1,72 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayMarkAll(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	b.Mark(5)
+
+	assert.Equal(3, b.MarkAll(1, 5, 64, 99, 100, -1))
+	assert.Equal(4, b.Len())
+	assert.True(b.Get(64))
+
+	assert.Equal(2, b.UnmarkAll(1, 2, 64))
+	assert.Equal(2, b.Len())
+	assert.False(b.Get(1))
+	assert.True(b.Get(99))
+
+	assert.Zero(b.MarkAll())
+}
+
+func TestBitArrayUnmarkMany(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(1000, WithCountCache())
+	for i := 0; i < 1000; i++ {
+		b.MarkFree()
+	}
+
+	assert.Equal(5, b.UnmarkMany([]int64{999, 3, 1, 2, 2, 700, 1000, -1, 3}))
+	assert.Equal(995, b.Len())
+	assert.EqualValues(995, b.CountRange(0, 1000))
+	assert.False(b.Get(700))
+	assert.True(b.Get(4))
+
+	assert.EqualValues(1, b.MarkFree())
+	assert.Zero(b.UnmarkMany(nil))
+
+	b = NewBitArray(100, WithBaseOffset(1000))
+	b.Mark(1064)
+
+	assert.Equal(1, b.UnmarkMany([]int64{1064, 1063, 5}))
+	assert.Zero(b.Len())
+}
+
+func TestBitArrayGetMany(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 64)
+
+	assert.Equal([]bool{false, true, true, false}, b.GetMany([]int64{0, 1, 64, 100}))
+	assert.Empty(b.GetMany(nil))
+}
+
+func TestBitArrayAnyOfAllOf(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 64)
+
+	assert.True(b.AnyOf([]int64{0, 64}))
+	assert.False(b.AnyOf([]int64{0, 2, 1000}))
+	assert.False(b.AnyOf(nil))
+
+	assert.True(b.AllOf([]int64{1, 64}))
+	assert.False(b.AllOf([]int64{1, 2}))
+	assert.True(b.AllOf(nil))
+}
@@ -5,26 +5,63 @@
 package bitarray
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
 	"sync"
-	"unsafe"
+	"time"
 
 	"github.com/aermolaev/atomicvalue"
 )
 
 // BitArray array of binary values.
+//
+// The zero value is an empty BitArray with no capacity, ready to use: the
+// storage is allocated lazily by Grow, or by the first write when the array
+// is configured to grow automatically. Writes to an empty array report
+// ErrOutOfRange. A BitArray must not be copied after first use.
 type BitArray struct {
+	// The atomically accessed counters come first, where they are 64-bit
+	// aligned on 32-bit platforms too.
+	capacity atomicvalue.Int
+	count    atomicvalue.Int
+	pins     atomicvalue.Int // open ReadTxns sharing the blocks
+
 	mu       sync.RWMutex
 	blocks   []BitBlock
 	curIndex int64
+	rotor    int64 // first block of the next scan, see WithRoundRobin
+	rotate   int64 // blocks per region of WithRoundRobin, 0 if it is off
+	scan     ScanStrategy
 	size     int64
-	capacity int64
-	count    atomicvalue.Int
+	policy   RangePolicy
+	mismatch CapacityPolicy
+	locking  LockStrategy
+	source   BlockSource
+	align    int64 // bytes, see WithAlignment
+	held     map[int64]heldRecord
+	logger   *slog.Logger
+	inst     Instrumentation
+	journal  journal
+	limiter  *limiter
+	counts   []uint16 // set bits per superblock, see WithCountCache
+	monitor  *monitor
+	tally    *tally // see WithStats
+	epoch    uint64 // number of Resets, see Epoch
+	shares   uint64 // number of times the blocks were shared, see BeginRead
+
+	base     int64
+	tiers    [][]Range
+	reserved []Range
+	excluded rangeSet
+	fence    rangeSet // the reserved and excluded indexes MarkFree skips
+
+	released chan struct{} // closed for the waiters of AcquireN
+	wanted   int64         // fewest free bits a waiter of AcquireN needs
 }
 
-type BitBlock uint64
-
 const (
-	blockSize    = int64(unsafe.Sizeof(BitBlock(0)) * 8)
 	bitBlockFull = (1 << blockSize) - 1
 
 	bitBlockMark   = true
@@ -33,86 +70,390 @@ const (
 	BitBlockNotFound = -1
 )
 
+// MaxCapacity is the largest capacity of a BitArray, the number of bits
+// whose storage the platform can address: about 2^63 on 64-bit platforms and
+// 2^34 on 32-bit ones, where arrays may be larger than int can represent.
+const MaxCapacity = min(math.MaxInt/(blockSize/8)-1, math.MaxInt64/blockSize-1) * blockSize
+
+var (
+	// ErrOutOfRange is returned when an index lies beyond the capacity.
+	ErrOutOfRange = errors.New("bitarray: index out of range")
+
+	// ErrNegativeIndex is returned when an index is negative. It wraps
+	// ErrOutOfRange.
+	ErrNegativeIndex = fmt.Errorf("%w: negative index", ErrOutOfRange)
+
+	// ErrCapacity is returned for a capacity that is negative or exceeds
+	// MaxCapacity.
+	ErrCapacity = errors.New("bitarray: invalid capacity")
+)
+
 // NewBitArray creates and initializes a new BitArray using capacity as its
-// initial capacity.
-func NewBitArray(capacity int64) *BitArray {
+// initial capacity. The options customize the behavior of the array. It
+// panics with an error wrapping ErrCapacity if the capacity is negative or
+// exceeds MaxCapacity; NewBitArrayE reports it instead.
+func NewBitArray(capacity int64, opts ...Option) *BitArray {
+	b, err := NewBitArrayE(capacity, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// NewBitArrayE is like NewBitArray but returns an error wrapping ErrCapacity
+// if the capacity is negative or exceeds MaxCapacity, and an error if the
+// alignment of WithAlignment is invalid.
+func NewBitArrayE(capacity int64, opts ...Option) (*BitArray, error) {
+	if err := checkCapacity(capacity); err != nil {
+		return nil, err
+	}
+
+	var c config
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if err := checkAlignment(c.align); err != nil {
+		return nil, err
+	}
+
 	size := (capacity / blockSize) + 1
 
-	return &BitArray{
-		blocks:   make([]BitBlock, size),
-		capacity: capacity,
+	b := &BitArray{
 		size:     size,
+		policy:   c.policy,
+		mismatch: c.mismatch,
+		locking:  c.locking,
+		source:   c.source,
+		align:    c.align,
+		logger:   c.logger,
+		inst:     c.inst,
+		limiter:  c.limiter,
+		monitor:  c.monitor,
+		tally:    c.tally,
+		rotate:   c.rotate,
+		scan:     c.scan,
+
+		base:     c.base,
+		tiers:    c.tiers,
+		reserved: c.reserved,
+	}
+
+	if c.leakTracking {
+		b.held = make(map[int64]heldRecord)
+	}
+	b.capacity.Set64(capacity)
+
+	if c.view != nil {
+		b.useView(c.view)
+	} else {
+		b.blocks = b.alloc(size)
+	}
+
+	if c.countCache {
+		b.recache()
 	}
+
+	if len(c.initial) > 0 {
+		for _, index := range c.initial {
+			if ok, _ := b.checkIndex(index, true); ok {
+				i, j := bitIndexAndNum(index)
+				b.blocks[i].mark(j)
+			}
+		}
+
+		b.recount()
+	}
+
+	if len(c.reserved) > 0 {
+		b.refence()
+		b.markReserved()
+	}
+
+	if b.monitor != nil {
+		b.monitor.full = b.full()
+	}
+
+	return b, nil
 }
 
-// HasRoom reports true if this BitArray contains bits that are set to true.
+// checkCapacity reports an error wrapping ErrCapacity unless capacity is in
+// [0, MaxCapacity].
+func checkCapacity(capacity int64) error {
+	if capacity < 0 || capacity > MaxCapacity {
+		return fmt.Errorf("%w: %d", ErrCapacity, capacity)
+	}
+
+	return nil
+}
+
+// toInt converts n to int, saturating at math.MaxInt where int is narrower
+// than int64.
+func toInt(n int64) int {
+	return int(min(n, math.MaxInt))
+}
+
+// NewBitArrayFull creates a BitArray like NewBitArray with all the bits up to
+// the capacity set to true.
+func NewBitArrayFull(capacity int64, opts ...Option) *BitArray {
+	b := NewBitArray(capacity, opts...)
+	b.Fill()
+
+	return b
+}
+
+// HasRoom reports whether the array contains bits that are set to false.
 func (b *BitArray) HasRoom() bool {
-	return b.count.Get64() < b.capacity
+	return b.count.Get64() < b.capacity.Get64()
+}
+
+// IsFull reports whether all the bits of the array outside the reserved and
+// excluded ranges are set to true, so MarkFree fails. An array of zero
+// capacity is full.
+func (b *BitArray) IsFull() bool {
+	b.rlock()
+	defer b.runlock()
+
+	return b.full()
+}
+
+// full is IsFull for callers holding the read lock.
+func (b *BitArray) full() bool {
+	return b.allocatable() <= 0
 }
 
-// IsEmpty reports true if this BitArray contains no bits that are set to true.
+// IsEmpty reports whether all the bits of the array are set to true,
+// despite its name.
+//
+// Deprecated: Use IsFull, or Len64 to check for set bits.
 func (b *BitArray) IsEmpty() bool {
 	return !b.HasRoom()
 }
 
-// Len returns the number of occupied bits.
+// Len returns the number of occupied bits. See Len64 for arrays that may
+// hold more bits than int can represent; Len saturates at math.MaxInt.
 func (b *BitArray) Len() int {
-	return b.count.Get()
+	return toInt(b.count.Get64())
+}
+
+// Len64 returns the number of occupied bits as int64.
+func (b *BitArray) Len64() int64 {
+	return b.count.Get64()
 }
 
 // Cap returns the BitArray capacity, that is, the total bits allocated
-// for the data.
+// for the data. See Cap64 for arrays larger than int can represent; Cap
+// saturates at math.MaxInt.
 func (b *BitArray) Cap() int {
-	return int(b.capacity)
+	return toInt(b.capacity.Get64())
+}
+
+// Cap64 returns the BitArray capacity as int64.
+func (b *BitArray) Cap64() int64 {
+	return b.capacity.Get64()
+}
+
+// FreeCount returns the number of bits that are set to false, that is, the
+// number of indexes MarkFree can still allocate.
+func (b *BitArray) FreeCount() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.capacity.Get64() - b.count.Get64()
+}
+
+// Utilization returns the fraction of the capacity that is set, from 0 for
+// an empty array to 1 for a full one. Unlike dividing Len64 by Cap64, the
+// counters are read consistently with concurrent growth.
+func (b *BitArray) Utilization() float64 {
+	b.rlock()
+	defer b.runlock()
+
+	return ratio(b.count.Get64(), b.capacity.Get64())
+}
+
+// LoadFactor returns the fraction of the allocated storage that is set. It
+// is lower than Utilization when the array has grown and keeps storage in
+// reserve for further growth.
+func (b *BitArray) LoadFactor() float64 {
+	b.rlock()
+	defer b.runlock()
+
+	return ratio(b.count.Get64(), int64(cap(b.blocks))*blockSize)
 }
 
-// Reset resets BitArray to initial state.
+// ratio returns n/d, or 0 if d is 0.
+func ratio(n, d int64) float64 {
+	if d == 0 {
+		return 0
+	}
+
+	return float64(n) / float64(d)
+}
+
+// ZeroCount returns the number of bits that are set to false.
+//
+// Deprecated: Use FreeCount.
+func (b *BitArray) ZeroCount() int64 {
+	return b.FreeCount()
+}
+
+// Reset resets BitArray to initial state and starts a new epoch. The bits
+// of the reserved ranges stay set.
 func (b *BitArray) Reset() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	b.lock()
+	defer b.unlock()
+
+	b.epoch++
+
+	if b.tally != nil {
+		b.tally.resets.Add(1)
+	}
 
 	for i := int64(0); i < b.size; i++ {
 		b.blocks[i] = BitBlock(0)
 	}
 
 	b.count.Set(0)
+	clear(b.held)
+	clear(b.counts)
+
+	if b.journal != nil {
+		b.journal.reset()
+	}
+
+	b.markReserved()
+
+	if b.logger != nil {
+		b.trace("bitarray: reset")
+	}
+}
+
+// Fill sets all the bits up to the capacity to true, the opposite of Reset.
+func (b *BitArray) Fill() {
+	b.lock()
+	defer b.unlock()
+
+	if b.blockwise() {
+		for i := int64(0); i < b.size; i++ {
+			b.setBlock(i, bitBlockFull)
+		}
+	} else {
+		for i := int64(0); i < b.size; i++ {
+			b.blocks[i] = b.validMask(i)
+		}
+
+		b.count.Set64(b.capacity.Get64())
+
+		if b.counts != nil {
+			b.recache()
+		}
+	}
+
+	if b.logger != nil {
+		b.trace("bitarray: fill")
+	}
 }
 
 // Set sets the bit at the specified index to the specified value.
+// Indexes beyond the capacity are handled according to the RangePolicy,
+// negative indexes are ignored.
 func (b *BitArray) Set(index int64, mark bool) (changed bool) {
-	if i, j := bitIndexAndNum(index); i < b.size {
-		block := &b.blocks[i]
+	changed, _ = b.SetE(index, mark)
+	return
+}
+
+// SetE is like Set but returns ErrOutOfRange if the index is beyond
+// the capacity and the RangePolicy is RangeError, or ErrNegativeIndex if
+// the index is negative.
+func (b *BitArray) SetE(index int64, mark bool) (changed bool, err error) {
+	b.lock()
+	defer b.unlock()
+
+	return b.set(b.in(index), mark)
+}
+
+// set sets the bit at the specified index. Callers hold the write lock.
+func (b *BitArray) set(index int64, mark bool) (changed bool, err error) {
+	var ok bool
+	if ok, err = b.checkIndex(index, mark == bitBlockMark); !ok {
+		return
+	}
+
+	i, j := bitIndexAndNum(index)
+	block := &b.blocks[i]
+
+	if mark == bitBlockMark {
+		if changed = block.compareAndMark(j); changed {
+			b.count.Inc()
+
+			if b.tally != nil {
+				b.tally.marks.Add(1)
+			}
+
+			if b.counts != nil {
+				b.cache(i, 1)
+			}
+		}
+	} else {
+		if changed = block.compareAndUnmark(j); changed {
+			b.count.Dec()
 
-		b.mu.Lock()
+			if b.tally != nil {
+				b.tally.unmarks.Add(1)
+			}
+
+			if b.counts != nil {
+				b.cache(i, -1)
+			}
 
-		if mark == bitBlockMark {
-			if changed = block.compareAndMark(j); changed {
-				b.count.Inc()
+			if i < b.curIndex {
+				b.curIndex = i // move pointer closer to the beginning
 			}
-		} else {
-			if changed = block.compareAndUnmark(j); changed {
-				b.count.Dec()
 
-				if i < b.curIndex {
-					b.curIndex = i // move pointer closer to the beginning
-				}
+			if b.held != nil {
+				delete(b.held, index)
 			}
 		}
+	}
+
+	if changed && b.journal != nil {
+		b.journal.bit(index, mark)
+	}
 
-		b.mu.Unlock()
+	if b.logger != nil {
+		b.traceSet(index, mark, changed)
 	}
 
 	return
 }
 
 // Get returns the value of the bit with the specified index.
+// Indexes beyond the capacity and negative indexes are reported as false.
 func (b *BitArray) Get(index int64) (res bool) {
-	if i, j := bitIndexAndNum(index); i < b.size {
-		block := &b.blocks[i]
+	res, _ = b.GetE(index)
+	return
+}
+
+// GetE is like Get but returns ErrOutOfRange if the index is beyond
+// the capacity and the RangePolicy is RangeError, or ErrNegativeIndex if
+// the index is negative.
+func (b *BitArray) GetE(index int64) (res bool, err error) {
+	b.rlock()
+	defer b.runlock()
 
-		b.mu.RLock()
-		res = block.value(j)
-		b.mu.RUnlock()
+	return b.get(b.in(index))
+}
+
+// get returns the value of the bit at the specified index. Callers hold the
+// read lock.
+func (b *BitArray) get(index int64) (res bool, err error) {
+	var ok bool
+	if ok, err = b.checkIndex(index, false); ok {
+		i, j := bitIndexAndNum(index)
+		res = b.blocks[i].value(j)
 	}
 
 	return
@@ -128,48 +469,249 @@ func (b *BitArray) Unmark(index int64) {
 	b.Set(index, bitBlockUnmark)
 }
 
+// MarkE is like Mark but returns ErrOutOfRange if the index is beyond
+// the capacity and the RangePolicy is RangeError, or ErrNegativeIndex if
+// the index is negative.
+func (b *BitArray) MarkE(index int64) error {
+	_, err := b.SetE(index, bitBlockMark)
+	return err
+}
+
+// UnmarkE is like Unmark but returns ErrOutOfRange if the index is beyond
+// the capacity and the RangePolicy is RangeError, or ErrNegativeIndex if
+// the index is negative.
+func (b *BitArray) UnmarkE(index int64) error {
+	_, err := b.SetE(index, bitBlockUnmark)
+	return err
+}
+
 // MarkFree finds the index of the first bit that is set to false and
 // sets the bit to true. Returns index of changed bit. Returns BitBlockNotFound
 // unless array has room.
-func (b *BitArray) MarkFree() (index int64) {
+func (b *BitArray) MarkFree() int64 {
+	return b.markFree()
+}
+
+func (b *BitArray) markFree() int64 {
+	return b.limited(b.allocate)
+}
+
+// instrumented runs allocate, reporting the outcome and the latency to the
+// instrumentation, if any, and returns the allocated index mapped by the
+// base offset.
+func (b *BitArray) instrumented(allocate func() int64) int64 {
+	if b.inst == nil {
+		return b.tallied(b.out(allocate()))
+	}
+
+	start := time.Now()
+	index := b.tallied(b.out(allocate()))
+
+	if index == BitBlockNotFound {
+		b.inst.Exhausted(time.Since(start))
+	} else {
+		b.inst.Allocated(index, time.Since(start))
+	}
+
+	return index
+}
+
+func (b *BitArray) allocate() (index int64) {
 	index = BitBlockNotFound
 
 	if !b.HasRoom() { // fast check w/o lock
 		return
 	}
 
-	b.mu.Lock()
+	b.lock()
 
 	if b.HasRoom() {
-		if block := b.nextFree(); block != nil {
-			b.count.Inc()
-
-			j := block.ffz()
-			block.mark(j)
+		var cursor int64
 
-			index = (b.curIndex * blockSize) + j
+		if index, cursor = b.peek(); index != BitBlockNotFound {
+			b.advance(cursor)
+			b.claim(index)
 		}
 	}
 
-	b.mu.Unlock()
+	b.unlock()
 
 	return
 }
 
-func (b *BitArray) nextFree() *BitBlock {
-	for i := int64(0); i < b.size; i++ {
-		if block := b.current(); block.hasRoom() {
-			return block
+// PeekFree returns the index of the bit that MarkFree would choose, without
+// marking it. Returns BitBlockNotFound unless array has room.
+func (b *BitArray) PeekFree() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	if b.HasRoom() {
+		index, _ := b.peek()
+		return b.out(index)
+	}
+
+	return BitBlockNotFound
+}
+
+// peek returns the free index MarkFree allocates next, or BitBlockNotFound,
+// and the scan pointer after the allocation. Callers hold the read lock.
+func (b *BitArray) peek() (index, cursor int64) {
+	if index = b.nextPreferred(); index != BitBlockNotFound {
+		return index, b.curIndex
+	}
+
+	if i := b.nextFree(); i != BitBlockNotFound {
+		return (i * blockSize) + b.occupied(i).ffz(), i
+	}
+
+	return BitBlockNotFound, b.curIndex
+}
+
+// claim sets the free bit at index for an allocation. Callers hold the write
+// lock.
+func (b *BitArray) claim(index int64) {
+	i, j := bitIndexAndNum(index)
+	b.blocks[i].mark(j)
+	b.count.Inc()
+
+	if b.counts != nil {
+		b.cache(i, 1)
+	}
+
+	if b.tally != nil {
+		b.tally.marks.Add(1)
+	}
+
+	if b.held != nil {
+		b.track(index)
+	}
+
+	if b.journal != nil {
+		b.journal.bit(index, bitBlockMark)
+	}
+
+	if b.logger != nil {
+		b.traceSet(index, bitBlockMark, true)
+	}
+}
+
+// nextFree returns the index of the first block that has room, scanning from
+// the current block, or the rotor under WithRoundRobin, and wrapping around,
+// unless the ScanStrategy says otherwise. Returns BitBlockNotFound unless
+// there is such a block.
+func (b *BitArray) nextFree() int64 {
+	start := b.curIndex
+
+	switch {
+	case b.scan == ScanLowest && b.counts != nil:
+		return b.lowestFree()
+
+	case b.scan != ScanResume:
+		start = 0
+
+	case b.rotate > 0 && b.rotor < b.size:
+		start = b.rotor
+	}
+
+	for n, i := int64(0), start; n < b.size; n++ {
+		if b.occupied(i).hasRoom() {
+			return i
 		}
 
-		b.curIndex = (b.curIndex + 1) % b.size
+		if i = (i + 1) % b.size; i == 0 && start > 0 && b.tally != nil {
+			b.tally.wraps.Add(1)
+		}
 	}
 
-	return nil
+	return BitBlockNotFound
+}
+
+// occupied returns block i with the bits that cannot be allocated, i.e.
+// those beyond the capacity and the fenced ones, reported as set.
+func (b *BitArray) occupied(i int64) BitBlock {
+	block := b.blocks[i] | ^b.validMask(i)
+
+	if b.fence != nil {
+		block |= b.fence.mask(i)
+	}
+
+	return block
+}
+
+// blockAt returns block i, or an empty block if i is beyond the storage.
+func (b *BitArray) blockAt(i int64) BitBlock {
+	if i < b.size {
+		return b.blocks[i]
+	}
+
+	return 0
+}
+
+// tailMask returns the bits of the last block that lie within the capacity.
+// The array maintains the invariant that the bits between the capacity and
+// the end of the last block are zero, so they are never counted or found;
+// operations working on whole blocks apply the mask to the last block, and
+// Validate checks it. The blocks before the last one lie entirely within
+// the capacity. Callers hold the read lock.
+func (b *BitArray) tailMask() BitBlock {
+	return b.validMask(b.size - 1)
+}
+
+// validMask returns the bits of block i that lie within the capacity.
+func (b *BitArray) validMask(i int64) BitBlock {
+	return capacityMask(i, b.capacity.Get64())
 }
 
-func (b *BitArray) current() *BitBlock {
-	return &b.blocks[b.curIndex]
+// capacityMask returns the bits of block i that lie below capacity.
+func capacityMask(i, capacity int64) BitBlock {
+	switch start := i * blockSize; {
+	case start+blockSize <= capacity:
+		return bitBlockFull
+	case start >= capacity:
+		return 0
+	default:
+		return mask(capacity-start) - 1
+	}
+}
+
+// setBlock replaces block i with the bits of v that lie within the capacity
+// and adjusts the counter. Callers hold the write lock.
+func (b *BitArray) setBlock(i int64, v BitBlock) {
+	v &= b.validMask(i)
+
+	if old := b.blocks[i]; old != v {
+		b.blocks[i] = v
+		b.count.Add64(v.popcount() - old.popcount())
+
+		if b.tally != nil {
+			b.tally.marks.Add((v &^ old).popcount())
+			b.tally.unmarks.Add((old &^ v).popcount())
+		}
+
+		if b.counts != nil {
+			b.cache(i, v.popcount()-old.popcount())
+		}
+
+		if b.journal != nil {
+			b.journal.block(i, v)
+		}
+	}
+}
+
+// blockwise reports whether the bulk operations write block by block with
+// setBlock, which journals and counts the changed bits, instead of running
+// the kernels. Callers hold the write lock.
+func (b *BitArray) blockwise() bool {
+	return b.journal != nil || b.tally != nil
+}
+
+// recount recalculates the number of set bits from the blocks.
+func (b *BitArray) recount() {
+	b.count.Set64(countBlocks(b.blocks[:b.size]))
+
+	if b.counts != nil {
+		b.recache()
+	}
 }
 
 func bitIndexAndNum(i int64) (int64, int64) {
@@ -214,52 +756,6 @@ func (b BitBlock) hasRoom() bool {
 	return b != bitBlockFull
 }
 
-func (b BitBlock) ffz() int64 {
-	v := (b & (^b - 1))
-
-	switch blockSize {
-	case 64:
-		return popcount64(uint64(v))
-
-	case 32:
-		return popcount32(uint32(v))
-
-	default:
-		panic("wrong block size")
-	}
-}
-
-func popcount64(b uint64) int64 {
-	const (
-		m1 = 0x5555555555555555 // binary: 0101...
-		m2 = 0x3333333333333333 // binary: 00110011..
-		m4 = 0x0f0f0f0f0f0f0f0f // binary: 4 zeros, 4 ones ...
-	)
-
-	b -= (b >> 1) & m1             // put count of each 2 bits into those 2 bits
-	b = (b & m2) + ((b >> 2) & m2) // put count of each 4 bits into those 4 bits
-	b = (b + (b >> 4)) & m4        // put count of each 8 bits into those 8 bits
-	b += b >> 8                    // put count of each 16 bits into their lowest 8 bits
-	b += b >> 16                   // put count of each 32 bits into their lowest 8 bits
-	b += b >> 32                   // put count of each 64 bits into their lowest 8 bits
-	return int64(b & 0x7f)
-}
-
-func popcount32(b uint32) int64 {
-	const (
-		m1 = 0x55555555 // binary: 0101...
-		m2 = 0x33333333 // binary: 00110011..
-		m4 = 0x0f0f0f0f // binary: 4 zeros, 4 ones ...
-	)
-
-	b -= (b >> 1) & m1
-	b = (b & m2) + ((b >> 2) & m2)
-	b = (b + (b >> 4)) & m4
-	b = b + (b >> 8)
-	b = b + (b >> 16)
-	return int64(b & 0x3f)
-}
-
 func mask(bit int64) BitBlock {
 	return 1 << bit
 }
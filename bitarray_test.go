@@ -3,6 +3,7 @@ package bitarray
 import (
 	"sync"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +23,115 @@ func TestBitArray(t *testing.T) {
 	assert.False(b.Get(4001))
 }
 
+func TestNewBitArrayFull(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArrayFull(100)
+	assert.Equal(100, b.Len())
+	assert.False(b.HasRoom())
+	assert.True(b.Get(99))
+	assert.NoError(b.Validate())
+
+	b.Unmark(70)
+	assert.Equal(int64(70), b.MarkFree())
+}
+
+func TestBitArrayZeroValue(t *testing.T) {
+	assert := assert.New(t)
+
+	var b BitArray
+
+	assert.Zero(b.Len())
+	assert.Zero(b.Cap())
+	assert.False(b.HasRoom())
+	assert.False(b.Get(0))
+	assert.Equal(ErrOutOfRange, b.MarkE(0))
+	assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+	assert.NoError(b.Validate())
+	b.Reset()
+
+	b.Grow(100)
+	assert.Equal(100, b.Cap())
+	assert.Equal(int64(0), b.MarkFree())
+	b.Mark(99)
+	assert.True(b.Get(99))
+	assert.Equal(2, b.Len())
+	assert.NoError(b.Validate())
+}
+
+func TestBitArrayLenCap64(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(1_000)
+	b.Mark(1)
+	b.Mark(999)
+
+	assert.Equal(int64(2), b.Len64())
+	assert.Equal(int64(1_000), b.Cap64())
+}
+
+func TestBitArrayFreeCount(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	assert.Equal(int64(100), b.FreeCount())
+
+	b.MarkFree()
+	b.Mark(70)
+	assert.Equal(int64(98), b.FreeCount())
+	assert.Equal(int64(98), b.ZeroCount())
+
+	assert.Equal(int64(0), NewBitArrayFull(100).FreeCount())
+
+	var z BitArray
+	assert.Equal(int64(0), z.FreeCount())
+}
+
+func TestBitArrayUtilization(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(191, WithAutoGrow())
+	b.MarkAll(1, 2)
+	assert.Equal(2.0/191, b.Utilization())
+	assert.Equal(2.0/192, b.LoadFactor())
+
+	b.Mark(255)
+	assert.Equal(256, b.Cap())
+	assert.Equal(3.0/256, b.Utilization())
+	assert.Less(b.LoadFactor(), b.Utilization())
+
+	var z BitArray
+	assert.Equal(0.0, z.Utilization())
+	assert.Equal(0.0, z.LoadFactor())
+}
+
+func TestBitArrayOutOfRange(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+
+	assert.False(b.Set(100, true))
+	assert.False(b.Get(100))
+	assert.Zero(b.Len())
+
+	_, err := b.SetE(100, true)
+	assert.Equal(ErrOutOfRange, err)
+
+	_, err = b.GetE(127)
+	assert.Equal(ErrOutOfRange, err)
+
+	assert.Equal(ErrOutOfRange, b.MarkE(1000))
+	assert.Equal(ErrOutOfRange, b.UnmarkE(1000))
+
+	assert.NoError(b.MarkE(99))
+	res, err := b.GetE(99)
+	assert.NoError(err)
+	assert.True(res)
+
+	assert.NoError(b.UnmarkE(99))
+	assert.False(b.Get(99))
+}
+
 func TestBitArrayHasRoom(t *testing.T) {
 	assert := assert.New(t)
 
@@ -35,6 +145,7 @@ func TestBitArrayHasRoom(t *testing.T) {
 
 	b.MarkFree()
 	assert.False(b.HasRoom())
+	assert.True(b.IsFull())
 	assert.True(b.IsEmpty())
 
 	b.Set(0, false)
@@ -60,6 +171,23 @@ func TestBitArrayReset(t *testing.T) {
 	assert.Zero(b.count.Get())
 }
 
+func TestBitArrayFill(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	b.Mark(3)
+	b.Fill()
+
+	assert.Equal(100, b.Len())
+	assert.False(b.HasRoom())
+	assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+	assert.NoError(b.Validate())
+
+	var z BitArray
+	z.Fill()
+	assert.Equal(0, z.Len())
+}
+
 func TestBitArrayConcurent(t *testing.T) {
 	assert := assert.New(t)
 
@@ -122,6 +250,28 @@ func TestBitArrayMarkFree(t *testing.T) {
 	}
 }
 
+func TestBitArrayPeekFree(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(70)
+	assert.Equal(int64(0), b.PeekFree())
+	assert.Equal(int64(0), b.PeekFree())
+	assert.Equal(int64(0), b.MarkFree())
+	assert.Equal(int64(1), b.PeekFree())
+
+	for i := int64(0); i < 64; i++ {
+		b.Mark(i)
+	}
+	assert.Equal(int64(64), b.PeekFree())
+	assert.Equal(int64(64), b.MarkFree())
+
+	for b.HasRoom() {
+		b.MarkFree()
+	}
+	assert.Equal(int64(BitBlockNotFound), b.PeekFree())
+	assert.NoError(b.Validate())
+}
+
 func BenchmarkBitIndexAndNum(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		_, _ = bitIndexAndNum(int64(n))
@@ -169,3 +319,11 @@ func BenchmarkCompareAndMark(b *testing.B) {
 		_ = bc.compareAndMark(bi)
 	}
 }
+
+func TestBitArrayCounterAlignment(t *testing.T) {
+	var b BitArray
+
+	// 64-bit atomic operations require 8-byte alignment on 32-bit platforms
+	assert.Zero(t, unsafe.Offsetof(b.capacity)%8)
+	assert.Zero(t, unsafe.Offsetof(b.count)%8)
+}
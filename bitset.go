@@ -0,0 +1,35 @@
+package bitarray
+
+import "iter"
+
+// BitSet is the common interface of the bit array implementations:
+// BitArray, Durable, Shared, Sharded and the Redis-backed
+// redisbitarray.BitArray. Libraries accepting a BitSet work with any of
+// them.
+type BitSet interface {
+	// Get returns the value of the bit with the specified index.
+	Get(index int64) bool
+
+	// Set sets the bit at the specified index to the specified value and
+	// reports whether it changed.
+	Set(index int64, mark bool) bool
+
+	// Len64 returns the number of set bits.
+	Len64() int64
+
+	// Cap64 returns the capacity.
+	Cap64() int64
+
+	// MarkFree sets a bit that is set to false to true and returns its
+	// index, or BitBlockNotFound if there is none.
+	MarkFree() int64
+
+	// SetBits returns an iterator over the indexes of the set bits in
+	// ascending order.
+	SetBits() iter.Seq[int64]
+}
+
+var (
+	_ BitSet = (*BitArray)(nil)
+	_ BitSet = (*Durable)(nil)
+)
@@ -0,0 +1,32 @@
+package bitarray
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBitSet runs the same operations against any BitSet of capacity 100.
+func testBitSet(t *testing.T, s BitSet) {
+	assert := assert.New(t)
+
+	assert.Equal(int64(100), s.Cap64())
+	assert.True(s.Set(70, true))
+	assert.False(s.Set(70, true))
+	assert.True(s.Get(70))
+	assert.Equal(int64(0), s.MarkFree())
+	assert.Equal(int64(2), s.Len64())
+	assert.Equal([]int64{0, 70}, slices.Collect(s.SetBits()))
+}
+
+func TestBitSet(t *testing.T) {
+	testBitSet(t, NewBitArray(100))
+
+	d, err := Recover(filepath.Join(t.TempDir(), "state"), 100)
+	assert.NoError(t, err)
+	defer d.Close()
+
+	testBitSet(t, d)
+}
@@ -0,0 +1,32 @@
+//go:build !bitarray32 && (bitarray64 || !(386 || arm || mips || mipsle))
+
+package bitarray
+
+import "math/bits"
+
+// BitBlock is a block of bits of the array. Its width is the native word
+// size: 64 bits, or 32 bits on 32-bit platforms. The bitarray32 build tag
+// selects 32-bit blocks everywhere, e.g. for memory-constrained targets, and
+// bitarray64 selects 64-bit blocks on 32-bit platforms.
+type BitBlock uint64
+
+const blockSize int64 = 64
+
+// ffz returns the index of the lowest clear bit.
+func (b BitBlock) ffz() int64 {
+	return int64(bits.TrailingZeros64(uint64(^b)))
+}
+
+// ffs returns the index of the lowest set bit.
+func (b BitBlock) ffs() int64 {
+	return int64(bits.TrailingZeros64(uint64(b)))
+}
+
+// fls returns the index of the highest set bit.
+func (b BitBlock) fls() int64 {
+	return int64(bits.Len64(uint64(b))) - 1
+}
+
+func (b BitBlock) popcount() int64 {
+	return int64(bits.OnesCount64(uint64(b)))
+}
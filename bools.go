@@ -0,0 +1,33 @@
+package bitarray
+
+// NewBitArrayFromBools creates a BitArray packing the values of bits, so that
+// bit i is set if bits[i] is true. The capacity of the array is len(bits).
+func NewBitArrayFromBools(bits []bool, opts ...Option) *BitArray {
+	b := NewBitArray(int64(len(bits)), opts...)
+
+	for index, bit := range bits {
+		if bit {
+			i, j := bitIndexAndNum(int64(index))
+			b.blocks[i].mark(j)
+		}
+	}
+
+	b.recount()
+
+	return b
+}
+
+// ToBools returns the bits of the array as a slice of length Cap.
+func (b *BitArray) ToBools() []bool {
+	b.rlock()
+	defer b.runlock()
+
+	bits := make([]bool, b.capacity.Get64())
+
+	for index := range bits {
+		i, j := bitIndexAndNum(int64(index))
+		bits[index] = b.blocks[i].value(j)
+	}
+
+	return bits
+}
@@ -0,0 +1,26 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBitArrayFromBools(t *testing.T) {
+	assert := assert.New(t)
+
+	bits := make([]bool, 130)
+	bits[0] = true
+	bits[64] = true
+	bits[129] = true
+
+	b := NewBitArrayFromBools(bits)
+	assert.Equal(130, b.Cap())
+	assert.Equal(3, b.Len())
+	assert.True(b.Get(64))
+	assert.False(b.Get(63))
+	assert.NoError(b.Validate())
+
+	assert.Equal(bits, b.ToBools())
+	assert.Empty(NewBitArrayFromBools(nil).ToBools())
+}
@@ -0,0 +1,36 @@
+package bitarray
+
+// MapBlocks replaces every 64-bit word of the array, in the layout of
+// ForEachWord, with the result of fn applied to its raw bits, under a single
+// write lock, and updates the number of set bits accordingly. Bits that fn
+// sets beyond the capacity are discarded.
+func (b *BitArray) MapBlocks(fn func(word uint64) uint64) {
+	b.lock()
+	defer b.unlock()
+
+	for k, n := int64(0), b.words(); k < n; k++ {
+		b.setWord(k, fn(b.word(k)))
+	}
+
+	b.curIndex = 0
+}
+
+// setRange sets the bits in the half-open range [from, to) to the specified
+// value, a block at a time. Callers hold the write lock.
+func (b *BitArray) setRange(from, to int64, mark bool) {
+	if from, to = b.clamp(from, to); from >= to {
+		return
+	}
+
+	for i, last := from/blockSize, (to-1)/blockSize; i <= last; i++ {
+		if mark == bitBlockMark {
+			b.setBlock(i, b.blocks[i]|rangeMask(i, from, to))
+		} else {
+			b.setBlock(i, b.blocks[i]&^rangeMask(i, from, to))
+		}
+	}
+
+	if mark == bitBlockUnmark && from/blockSize < b.curIndex {
+		b.curIndex = from / blockSize
+	}
+}
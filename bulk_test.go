@@ -0,0 +1,28 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayMapBlocks(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 0, 1, 70)
+
+	b.MapBlocks(func(word uint64) uint64 {
+		return ^word
+	})
+
+	assert.Equal(97, b.Len())
+	assert.False(b.Get(0))
+	assert.True(b.Get(2))
+	assert.False(b.Get(70))
+	assert.True(b.Get(99))
+	assert.NoError(b.Validate())
+
+	b.MapBlocks(func(uint64) uint64 { return 0 })
+	assert.Zero(b.Len())
+	assert.Equal(int64(0), b.MarkFree())
+}
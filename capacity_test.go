@@ -0,0 +1,73 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBitArrayE(t *testing.T) {
+	assert := assert.New(t)
+
+	b, err := NewBitArrayE(100)
+	assert.NoError(err)
+	assert.Equal(100, b.Cap())
+
+	for _, capacity := range []int64{-1, math.MinInt64, MaxCapacity + 1, math.MaxInt64} {
+		_, err = NewBitArrayE(capacity)
+		assert.True(errors.Is(err, ErrCapacity), capacity)
+		assert.Panics(func() { NewBitArray(capacity) })
+	}
+
+	_, err = ParseRanges("1", -1)
+	assert.True(errors.Is(err, ErrCapacity))
+
+	_, err = ParseMask("1", -1)
+	assert.True(errors.Is(err, ErrCapacity))
+}
+
+func TestBitArrayMaxCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(MaxCapacity > 1<<33)
+	assert.Equal(int64(0), MaxCapacity%blockSize)
+	assert.True(MaxCapacity/blockSize+1 <= math.MaxInt/(blockSize/8))
+
+	b := NewBitArray(10, WithRangePolicy(RangeGrow))
+	_, err := b.SetE(MaxCapacity, true)
+	assert.True(errors.Is(err, ErrCapacity))
+	_, err = b.SetE(math.MaxInt64, true)
+	assert.True(errors.Is(err, ErrCapacity))
+	assert.False(b.Get(math.MaxInt64))
+	assert.Equal(10, b.Cap())
+
+	assert.Panics(func() { b.Grow(math.MaxInt64) })
+	assert.Equal(10, b.Cap())
+}
+
+func TestBitArrayLenSaturates(t *testing.T) {
+	assert := assert.New(t)
+
+	var b BitArray
+	b.count.Set64(math.MaxInt64)
+	b.capacity.Set64(math.MaxInt64)
+
+	assert.Equal(math.MaxInt, b.Len())
+	assert.Equal(math.MaxInt, b.Cap())
+	assert.Equal(int64(math.MaxInt64), b.Len64())
+}
+
+func TestBitArrayUnmarshalCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(0)
+
+	data := append([]byte(binaryMagic), sparseVersion, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 0)
+	data = binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+	assert.True(errors.Is(b.UnmarshalBinary(data), ErrFormat))
+	assert.Equal(0, b.Cap())
+}
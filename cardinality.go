@@ -0,0 +1,52 @@
+package bitarray
+
+// AndCardinalityMany returns the number of bits set in b and in all the
+// others, the cardinality of their intersection, without materializing it.
+// With no others it is the number of set bits of b. Bits beyond the capacity
+// of an array are considered clear.
+func (b *BitArray) AndCardinalityMany(others ...*BitArray) (n int64) {
+	unlock := lockSet(nil, append([]*BitArray{b}, others...)...)
+	defer unlock()
+
+	for i := int64(0); i < b.size; i++ {
+		block := b.blocks[i]
+
+		for _, o := range others {
+			if block == 0 {
+				break
+			}
+
+			block &= o.blockAt(i)
+		}
+
+		n += block.popcount()
+	}
+
+	return
+}
+
+// AndCardinalities returns the number of bits set in both b and each of the
+// others, like a query joining b with every array of a bitmap index. The
+// blocks of b are streamed once for all the others. Bits beyond the capacity
+// of an array are considered clear.
+func (b *BitArray) AndCardinalities(others ...*BitArray) []int64 {
+	unlock := lockSet(nil, append([]*BitArray{b}, others...)...)
+	defer unlock()
+
+	res := make([]int64, len(others))
+
+	for i := int64(0); i < b.size; i++ {
+		block := b.blocks[i]
+		if block == 0 {
+			continue
+		}
+
+		for k, o := range others {
+			if i < o.size {
+				res[k] += (block & o.blocks[i]).popcount()
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,32 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayAndCardinalityMany(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 1, 2, 70, 200, 299)
+	x := newMarked(300, 1, 70, 200)
+	y := newMarked(100, 1, 2, 70)
+
+	assert.Equal(int64(5), b.AndCardinalityMany())
+	assert.Equal(int64(3), b.AndCardinalityMany(x))
+	assert.Equal(int64(2), b.AndCardinalityMany(x, y))
+	assert.Equal(int64(2), b.AndCardinalityMany(y, x, x, b))
+	assert.Equal(int64(0), b.AndCardinalityMany(x, NewBitArray(0)))
+}
+
+func TestBitArrayAndCardinalities(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 1, 2, 70, 200, 299)
+	x := newMarked(300, 1, 70, 200)
+	y := newMarked(100, 1, 2, 70)
+
+	assert.Equal([]int64{3, 3, 5, 0}, b.AndCardinalities(x, y, b, NewBitArray(1000)))
+	assert.Equal([]int64{}, b.AndCardinalities())
+}
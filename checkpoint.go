@@ -0,0 +1,59 @@
+package bitarray
+
+import "slices"
+
+// Checkpoint is a saved state of a BitArray: its bits, number of set bits
+// and allocation cursor. It is restored by Rollback and may be restored any
+// number of times.
+type Checkpoint struct {
+	blocks   []BitBlock
+	capacity int64
+	count    int64
+	curIndex int64
+}
+
+// Checkpoint saves the state of the array, e.g. before a speculative phase
+// of allocations that may need to be undone.
+func (b *BitArray) Checkpoint() Checkpoint {
+	b.rlock()
+	defer b.runlock()
+
+	return Checkpoint{
+		blocks:   slices.Clone(b.blocks[:b.size]),
+		capacity: b.capacity.Get64(),
+		count:    b.count.Get64(),
+		curIndex: b.curIndex,
+	}
+}
+
+// Rollback restores the state saved by Checkpoint, including the capacity
+// if the array has grown since.
+func (b *BitArray) Rollback(cp Checkpoint) {
+	b.lock()
+	defer b.unlock()
+
+	size := int64(len(cp.blocks))
+
+	if size > int64(cap(b.blocks)) {
+		b.blocks = b.alloc(size)
+	}
+
+	// grow expects the storage beyond the size to be clear
+	clear(b.blocks[min(size, b.size):b.size])
+
+	b.blocks = b.blocks[:size]
+	copy(b.blocks, cp.blocks)
+
+	b.size = size
+	b.curIndex = cp.curIndex
+	b.capacity.Set64(cp.capacity)
+	b.count.Set64(cp.count)
+
+	if b.counts != nil {
+		b.recache()
+	}
+
+	if b.journal != nil {
+		b.journal.replace()
+	}
+}
@@ -0,0 +1,60 @@
+package bitarray
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithAutoGrow())
+	b.MarkFree()
+	b.MarkFree()
+
+	cp := b.Checkpoint()
+
+	b.MarkFree()
+	b.Unmark(0)
+	b.Mark(500)
+	assert.Equal("1-2,500", b.FormatRanges())
+
+	b.Rollback(cp)
+	assert.Equal("0-1", b.FormatRanges())
+	assert.Equal(100, b.Cap())
+	assert.Equal(2, b.Len())
+	assert.Equal(int64(2), b.MarkFree())
+	assert.NoError(b.Validate())
+
+	b.Rollback(cp)
+	assert.Equal("0-1", b.FormatRanges())
+
+	b.Mark(500)
+	b.Rollback(cp)
+	b.Mark(200)
+	assert.Equal("0-1,200", b.FormatRanges())
+	assert.NoError(b.Validate())
+}
+
+func TestRecoverRollback(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100)
+	assert.NoError(err)
+
+	d.Mark(1)
+	cp := d.Checkpoint()
+	d.Mark(2)
+	d.Rollback(cp)
+	d.Mark(3)
+	assert.NoError(d.Close())
+
+	r, err := Recover(path, 0)
+	assert.NoError(err)
+	assert.Equal("1,3", r.FormatRanges())
+	assert.NoError(r.Close())
+}
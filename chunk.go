@@ -0,0 +1,185 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"iter"
+)
+
+// A chunk of the chunked encoding consists of
+//
+//	magic    [4]byte  "BACH"
+//	version  uint8    1
+//	capacity uint64   little-endian capacity of the whole array
+//	offset   uint64   little-endian index of the first word of the chunk
+//	words    []uint64 little-endian, in the layout of the binary encoding
+//	checksum uint32   little-endian CRC-32 (IEEE) of all the preceding bytes
+//
+// Every chunk is self-contained, so the chunks can be stored, transferred
+// and imported independently and in any order.
+const (
+	chunkMagic   = "BACH"
+	chunkVersion = 1
+
+	chunkHeaderLen = len(chunkMagic) + 1 + 8 + 8
+)
+
+// ExportChunks returns an iterator over the chunked encoding of the array,
+// yielding the chunk number and the chunk. Each chunk holds up to chunkSize
+// bytes of words, at least one word; an empty array yields one empty
+// chunk. Every chunk is read under the read lock on its own, so the chunks
+// reflect a consistent state only if the array is not modified during the
+// iteration; a chunk taken after the capacity changed is rejected by the
+// import.
+func (b *BitArray) ExportChunks(chunkSize int) iter.Seq2[int, []byte] {
+	per := max(int64(chunkSize/8), 1)
+
+	return func(yield func(int, []byte) bool) {
+		b.rlock()
+		n := wordCount(b.capacity.Get64())
+		b.runlock()
+
+		for c, k := 0, int64(0); k < n || c == 0; c, k = c+1, k+per {
+			if !yield(c, b.chunk(k, min(per, n-k))) {
+				return
+			}
+		}
+	}
+}
+
+// chunk returns the chunk of n words starting at word k.
+func (b *BitArray) chunk(k, n int64) []byte {
+	b.rlock()
+	defer b.runlock()
+
+	n = max(min(n, wordCount(b.capacity.Get64())-k), 0)
+	data := make([]byte, 0, chunkHeaderLen+int(n)*8+checksumLen)
+	data = append(data, chunkMagic...)
+	data = append(data, chunkVersion)
+	data = binary.LittleEndian.AppendUint64(data, uint64(b.capacity.Get64()))
+	data = binary.LittleEndian.AppendUint64(data, uint64(k))
+
+	for end := k + n; k < end; k++ {
+		data = binary.LittleEndian.AppendUint64(data, b.word(k))
+	}
+
+	return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+}
+
+// ImportChunks creates a bit array from all the chunks of a chunked
+// encoding, produced by ExportChunks, in any order. The capacity is taken
+// from the chunks, which must cover all its words; where chunks overlap, the
+// words of the later ones win.
+func ImportChunks(chunks iter.Seq2[int, []byte], opts ...Option) (*BitArray, error) {
+	var (
+		b       *BitArray
+		covered rangeSet // of words
+	)
+
+	for c, data := range chunks {
+		capacity, offset, words, err := decodeChunk(data)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", c, err)
+		}
+
+		if b == nil {
+			if b, err = NewBitArrayE(capacity, opts...); err != nil {
+				return nil, fmt.Errorf("chunk %d: %w", c, err)
+			}
+		}
+
+		if err = b.importChunk(capacity, offset, words); err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", c, err)
+		}
+
+		covered = covered.add(Range{offset, offset + int64(len(words))})
+	}
+
+	if b == nil {
+		return nil, fmt.Errorf("%w: no chunks", ErrFormat)
+	}
+
+	if n := wordCount(b.Cap64()); n > 0 && (len(covered) != 1 || covered[0] != Range{0, n}) {
+		var words int64
+		for _, r := range covered {
+			words += r.To - r.From
+		}
+
+		return nil, fmt.Errorf("%w: chunks cover %d of %d words", ErrFormat, words, n)
+	}
+
+	return b, nil
+}
+
+// ImportChunk replaces the words held by a chunk of the chunked encoding,
+// so an interrupted import can be resumed chunk by chunk. The capacity of
+// the chunk must match the capacity of the array.
+func (b *BitArray) ImportChunk(data []byte) error {
+	capacity, offset, words, err := decodeChunk(data)
+	if err != nil {
+		return err
+	}
+
+	return b.importChunk(capacity, offset, words)
+}
+
+func (b *BitArray) importChunk(capacity, offset int64, words []uint64) error {
+	b.lock()
+	defer b.unlock()
+
+	if c := b.capacity.Get64(); c != capacity {
+		return fmt.Errorf("%w: chunk of capacity %d for capacity %d", ErrFormat, capacity, c)
+	}
+
+	for k, w := range words {
+		b.setWord(offset+int64(k), w)
+	}
+
+	if len(words) > 0 {
+		b.curIndex = 0
+	}
+
+	return nil
+}
+
+// decodeChunk verifies a chunk and returns its contents.
+func decodeChunk(data []byte) (capacity, offset int64, words []uint64, err error) {
+	if len(data) < chunkHeaderLen+checksumLen {
+		return 0, 0, nil, fmt.Errorf("%w: %d bytes is too short", ErrFormat, len(data))
+	}
+
+	if string(data[:len(chunkMagic)]) != chunkMagic {
+		return 0, 0, nil, fmt.Errorf("%w: bad magic", ErrFormat)
+	}
+
+	if v := data[len(chunkMagic)]; v != chunkVersion {
+		return 0, 0, nil, fmt.Errorf("%w: unsupported version %d", ErrFormat, v)
+	}
+
+	body, sum := data[:len(data)-checksumLen], data[len(data)-checksumLen:]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(sum) {
+		return 0, 0, nil, fmt.Errorf("%w: checksum mismatch", ErrFormat)
+	}
+
+	capacity = int64(binary.LittleEndian.Uint64(data[len(chunkMagic)+1:]))
+	offset = int64(binary.LittleEndian.Uint64(data[len(chunkMagic)+9:]))
+	payload := body[chunkHeaderLen:]
+	n := wordCount(capacity)
+
+	if capacity < 0 || len(payload)%8 != 0 || offset < 0 || offset > n || int64(len(payload)/8) > n-offset {
+		return 0, 0, nil, fmt.Errorf("%w: %d bytes of words at word %d for capacity %d", ErrFormat, len(payload), offset, capacity)
+	}
+
+	words = make([]uint64, len(payload)/8)
+	for k := range words {
+		words[k] = binary.LittleEndian.Uint64(payload[k*8:])
+	}
+
+	if last := offset + int64(len(words)); len(words) > 0 && last == n && capacity%wordSize != 0 &&
+		words[len(words)-1]>>(capacity%wordSize) != 0 {
+		return 0, 0, nil, fmt.Errorf("%w: bits set beyond capacity %d", ErrFormat, capacity)
+	}
+
+	return capacity, offset, words, nil
+}
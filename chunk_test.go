@@ -0,0 +1,67 @@
+package bitarray
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportChunks(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(1000, 0, 63, 64, 500, 999)
+	chunks := maps.Collect(b.ExportChunks(24))
+	assert.Len(chunks, 6)
+
+	r, err := ImportChunks(maps.All(chunks))
+	assert.NoError(err)
+	assert.Equal(1000, r.Cap())
+	assert.Equal(b.String(), r.String())
+	assert.NoError(r.Validate())
+
+	// a resumed import, chunk by chunk
+	r = NewBitArray(1000)
+	r.Mark(1)
+	for _, c := range slices.Backward(slices.Sorted(maps.Keys(chunks))) {
+		assert.NoError(r.ImportChunk(chunks[c]))
+	}
+	assert.Equal(b.String(), r.String())
+	assert.Equal(5, r.Len())
+
+	e, err := ImportChunks(NewBitArray(0).ExportChunks(8))
+	assert.NoError(err)
+	assert.Equal(0, e.Cap())
+}
+
+func TestImportChunksErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(200, 5, 150)
+	chunks := maps.Collect(b.ExportChunks(8))
+	assert.Len(chunks, 4)
+
+	delete(chunks, 2)
+	_, err := ImportChunks(maps.All(chunks))
+	assert.True(errors.Is(err, ErrFormat))
+
+	bad := slices.Clone(chunks[0])
+	bad[len(bad)-5] ^= 1
+	assert.True(errors.Is(NewBitArray(200).ImportChunk(bad), ErrFormat))
+	assert.True(errors.Is(NewBitArray(100).ImportChunk(chunks[0]), ErrFormat))
+
+	_, err = ImportChunks(maps.All(map[int][]byte{}))
+	assert.True(errors.Is(err, ErrFormat))
+
+	// the overlap of words 0-1 and word 1 does not make up for word 3
+	overlapping := map[int][]byte{0: b.chunk(0, 2), 1: b.chunk(1, 1), 2: b.chunk(2, 1)}
+	_, err = ImportChunks(maps.All(overlapping))
+	assert.True(errors.Is(err, ErrFormat))
+
+	overlapping[3] = b.chunk(3, 1)
+	o, err := ImportChunks(maps.All(overlapping))
+	assert.NoError(err)
+	assert.True(o.Equal(b))
+}
@@ -0,0 +1,212 @@
+// Command bitarray inspects serialized BitArray files.
+//
+// Usage:
+//
+//	bitarray count FILE
+//	bitarray dump FILE
+//	bitarray diff OLD NEW
+//	bitarray convert -to binary|json|hex FILE
+//
+// The format of the input files (binary, JSON or hex-encoded binary) is
+// detected automatically. A FILE of "-" reads the standard input.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aermolaev/bitarray"
+)
+
+const usage = `usage:
+	bitarray count FILE
+	bitarray dump FILE
+	bitarray diff OLD NEW
+	bitarray convert -to binary|json|hex FILE
+`
+
+var errUsage = errors.New(usage)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+
+	cmd, args := args[0], args[1:]
+
+	switch cmd {
+	case "count":
+		return count(args, stdin, w)
+
+	case "dump":
+		return dump(args, stdin, w)
+
+	case "diff":
+		return diff(args, stdin, w)
+
+	case "convert":
+		return convert(args, stdin, w)
+
+	default:
+		return errUsage
+	}
+}
+
+func count(args []string, stdin io.Reader, w io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	b, err := load(args[0], stdin)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "count: %d\ncapacity: %d\n", b.Len64(), b.Cap64())
+	return err
+}
+
+func dump(args []string, stdin io.Reader, w io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	b, err := load(args[0], stdin)
+	if err != nil {
+		return err
+	}
+
+	b.ForEach(func(index int64) bool {
+		_, err = fmt.Fprintln(w, index)
+		return err == nil
+	})
+
+	return err
+}
+
+func diff(args []string, stdin io.Reader, w io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	old, err := load(args[0], stdin)
+	if err != nil {
+		return err
+	}
+
+	cur, err := load(args[1], stdin)
+	if err != nil {
+		return err
+	}
+
+	bitarray.DiffFunc(old, cur, func(index int64, set bool) bool {
+		sign := "-"
+		if set {
+			sign = "+"
+		}
+
+		_, err = fmt.Fprintf(w, "%s%d\n", sign, index)
+
+		return err == nil
+	})
+
+	return err
+}
+
+func convert(args []string, stdin io.Reader, w io.Writer) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	to := flags.String("to", "binary", "output format: binary, json or hex")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		return errUsage
+	}
+
+	b, err := load(flags.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+
+	switch *to {
+	case "binary":
+		data, err = b.MarshalBinary()
+
+	case "json":
+		if data, err = json.Marshal(b); err == nil {
+			data = append(data, '\n')
+		}
+
+	case "hex":
+		if data, err = b.MarshalBinary(); err == nil {
+			data = append([]byte(hex.EncodeToString(data)), '\n')
+		}
+
+	default:
+		return fmt.Errorf("unknown format %q", *to)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// load reads a BitArray from the named file, detecting its format.
+func load(name string, stdin io.Reader) (*bitarray.BitArray, error) {
+	var (
+		data []byte
+		err  error
+	)
+
+	if name == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	b := bitarray.NewBitArray(0)
+
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(data, []byte("BARR")):
+		err = b.UnmarshalBinary(data)
+
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		err = json.Unmarshal(trimmed, b)
+
+	default:
+		if data, err = hex.DecodeString(string(trimmed)); err == nil {
+			err = b.UnmarshalBinary(data)
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return b, nil
+}
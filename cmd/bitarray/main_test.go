@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aermolaev/bitarray"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func marshal(t *testing.T, capacity int64, indices ...int64) []byte {
+	data, err := bitarray.NewBitArray(capacity, bitarray.WithInitialSet(indices...)).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func exec(args ...string) (string, error) {
+	var out bytes.Buffer
+	err := run(args, strings.NewReader(""), &out)
+
+	return out.String(), err
+}
+
+func TestCount(t *testing.T) {
+	path := writeFile(t, "a.bin", marshal(t, 100, 1, 2, 3))
+
+	out, err := exec("count", path)
+	assert.NoError(t, err)
+	assert.Equal(t, "count: 3\ncapacity: 100\n", out)
+}
+
+func TestDump(t *testing.T) {
+	path := writeFile(t, "a.hex", []byte(hex.EncodeToString(marshal(t, 100, 5, 70))))
+
+	out, err := exec("dump", path)
+	assert.NoError(t, err)
+	assert.Equal(t, "5\n70\n", out)
+}
+
+func TestDiff(t *testing.T) {
+	old := writeFile(t, "old.bin", marshal(t, 100, 1, 2))
+	cur := writeFile(t, "new.bin", marshal(t, 100, 2, 3))
+
+	out, err := exec("diff", old, cur)
+	assert.NoError(t, err)
+	assert.Equal(t, "-1\n+3\n", out)
+}
+
+func TestConvert(t *testing.T) {
+	assert := assert.New(t)
+
+	path := writeFile(t, "a.bin", marshal(t, 10, 0, 1))
+
+	out, err := exec("convert", "-to", "json", path)
+	assert.NoError(err)
+	assert.JSONEq(`{"capacity":10,"words":["0000000000000003"]}`, out)
+
+	out, err = exec("convert", "-to", "hex", writeFile(t, "a.json", []byte(out)))
+	assert.NoError(err)
+	assert.Equal(hex.EncodeToString(marshal(t, 10, 0, 1))+"\n", out)
+
+	_, err = exec("convert", "-to", "xml", path)
+	assert.Error(err)
+}
+
+func TestUsage(t *testing.T) {
+	_, err := exec()
+	assert.Equal(t, errUsage, err)
+
+	_, err = exec("count")
+	assert.Equal(t, errUsage, err)
+}
@@ -0,0 +1,147 @@
+package bitarray
+
+import "fmt"
+
+// CopyRange copies the n bits of src starting at srcOff to dst starting at
+// dstOff, shifting them across the block boundaries as needed. The bits of
+// src beyond its capacity read as clear and the bits beyond the capacity of
+// dst are dropped. dst and src may be the same array, and the ranges may
+// overlap. It panics if an offset or n is negative.
+func CopyRange(dst *BitArray, dstOff int64, src *BitArray, srcOff, n int64) {
+	if dstOff < 0 || srcOff < 0 || n < 0 {
+		panic(fmt.Errorf("bitarray: negative copy range %d, %d, %d", dstOff, srcOff, n))
+	}
+
+	if dst == src {
+		dst.lock()
+		defer dst.unlock()
+	} else {
+		unlock := lockPair(dst, src)
+		defer unlock()
+	}
+
+	if n = min(n, dst.capacity.Get64()-dstOff); n <= 0 {
+		return
+	}
+
+	from, to := dstOff, dstOff+n
+	first, last := from/blockSize, (to-1)/blockSize
+
+	// copy every block of the destination range; an overlapping range
+	// of the same array is walked away from the source bits still to copy
+	step := int64(1)
+	if dst == src && dstOff > srcOff {
+		first, last, step = last, first, -1
+	}
+
+	for i := first; ; i += step {
+		lo, hi := max(from, i*blockSize), min(to, (i+1)*blockSize)
+		v := src.bitsAt(srcOff+lo-from, hi-lo) << (lo - i*blockSize)
+		m := rangeMask(i, from, to)
+
+		dst.setBlock(i, dst.blocks[i]&^m|v&m)
+
+		if i == last {
+			break
+		}
+	}
+
+	if first := from / blockSize; first < dst.curIndex {
+		dst.curIndex = first // move pointer closer to the beginning
+	}
+}
+
+// bitsAt returns the n bits starting at index p, at most blockSize of them,
+// in the lowest bits of a block. Bits beyond the blocks read as clear.
+// Callers hold the read lock.
+func (b *BitArray) bitsAt(p, n int64) (v BitBlock) {
+	i, j := bitIndexAndNum(p)
+
+	if i < b.size {
+		v = b.blocks[i] >> j
+	}
+
+	if j > 0 && i+1 < b.size {
+		v |= b.blocks[i+1] << (blockSize - j)
+	}
+
+	return v & (mask(n) - 1)
+}
+
+// CopyFrom replaces the bits of b with the bits of other in one block copy
+// under the locks of both arrays. If other is larger, b grows to its
+// capacity under the RangeGrow policy and the bits of other beyond the
+// capacity of b are dropped otherwise. The bits of b beyond the capacity of
+// other are cleared. Arrays of different capacities are handled according
+// to the CapacityPolicy of b.
+func (b *BitArray) CopyFrom(other *BitArray) {
+	b.CopyFromE(other)
+}
+
+// CopyFromE is like CopyFrom but returns ErrCapacityMismatch if the
+// capacities differ under CapacityError.
+func (b *BitArray) CopyFromE(other *BitArray) error {
+	if b == other {
+		return nil
+	}
+
+	unlock := lockPair(b, other)
+	defer unlock()
+
+	if err := b.matchCapacity(other); err != nil {
+		return err
+	}
+
+	if b.policy == RangeGrow {
+		b.grow(other.capacity.Get64())
+	}
+
+	n := min(b.size, other.size)
+
+	if b.blockwise() {
+		for i := int64(0); i < b.size; i++ {
+			var block BitBlock
+			if i < n {
+				block = other.blocks[i]
+			}
+
+			b.setBlock(i, block)
+		}
+	} else {
+		copy(b.blocks[:n], other.blocks[:n])
+		clear(b.blocks[n:b.size])
+
+		if b.size > 0 {
+			b.blocks[b.size-1] &= b.tailMask()
+		}
+
+		b.recount()
+	}
+
+	b.curIndex = 0
+
+	return nil
+}
+
+// CloneWithCapacity returns a copy of the array with the specified capacity,
+// created with the specified options: the bits beyond the capacity are
+// dropped and the new bits are clear. E.g. a resharding step moves the
+// contents into a larger or a smaller array with it.
+func (b *BitArray) CloneWithCapacity(capacity int64, opts ...Option) *BitArray {
+	c := NewBitArray(capacity, opts...)
+
+	b.rlock()
+	defer b.runlock()
+
+	n := min(c.size, b.size)
+	copy(c.blocks[:n], b.blocks[:n])
+	clear(c.blocks[n:c.size])
+
+	if c.size > 0 {
+		c.blocks[c.size-1] &= c.tailMask()
+	}
+
+	c.recount()
+
+	return c
+}
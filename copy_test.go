@@ -0,0 +1,92 @@
+package bitarray
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyRange(t *testing.T) {
+	assert := assert.New(t)
+
+	src := newMarked(200, 0, 3, 63, 64, 130, 199)
+	dst := newMarked(300, 10, 299)
+
+	CopyRange(dst, 5, src, 0, 200)
+	assert.Equal("5,8,68-69,135,204,299", dst.FormatRanges())
+
+	CopyRange(dst, 290, src, 195, 100)
+	assert.Equal("5,8,68-69,135,204,294", dst.FormatRanges())
+	assert.Equal(7, dst.Len())
+	assert.NoError(dst.Validate())
+}
+
+func TestCopyRangeOverlap(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for n := 0; n < 100; n++ {
+		b := NewBitArray(500)
+		for i := 0; i < 150; i++ {
+			b.Mark(rng.Int64N(500))
+		}
+
+		dstOff, srcOff, size := rng.Int64N(300), rng.Int64N(300), rng.Int64N(200)
+		want := []byte(b.String())
+		copy(want[dstOff:], want[srcOff:srcOff+size])
+
+		CopyRange(b, dstOff, b, srcOff, size)
+		assert.Equal(string(want), b.String())
+		assert.NoError(b.Validate())
+	}
+}
+
+func TestBitArrayCopyFrom(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 50, 99)
+	b.CopyFrom(newMarked(80, 2, 79))
+	assert.Equal("2,79", b.FormatRanges())
+	assert.Equal(100, b.Cap())
+
+	b.CopyFrom(newMarked(200, 3, 99, 150))
+	assert.Equal("3,99", b.FormatRanges())
+	assert.Equal(2, b.Len())
+
+	g := NewBitArray(10, WithAutoGrow())
+	g.Mark(5)
+	g.CopyFrom(newMarked(200, 3, 150))
+	assert.Equal(200, g.Cap())
+	assert.Equal("3,150", g.FormatRanges())
+	assert.NoError(g.Validate())
+}
+
+func TestBitArrayCloneWithCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(200, 1, 63, 64, 150, 199)
+
+	c := b.CloneWithCapacity(100)
+	assert.Equal(100, c.Cap())
+	assert.Equal("1,63-64", c.FormatRanges())
+	assert.Equal(3, c.Len())
+	assert.NoError(c.Validate())
+
+	c = b.CloneWithCapacity(64)
+	assert.Equal("1,63", c.FormatRanges())
+	assert.NoError(c.Validate())
+
+	c = b.CloneWithCapacity(1000, WithCountCache())
+	assert.Equal(1000, c.Cap())
+	assert.Equal(b.FormatRanges(), c.FormatRanges())
+	assert.Equal(int64(5), c.CountRange(0, 1000))
+	assert.NoError(c.Validate())
+
+	c.Mark(500)
+	assert.False(b.Get(500))
+	assert.Equal(5, b.Len())
+
+	assert.Equal(0, b.CloneWithCapacity(0).Cap())
+}
@@ -0,0 +1,130 @@
+package bitarray
+
+// superBlockSize is the number of bits counted by an entry of the count
+// cache.
+const superBlockSize = 512
+
+// WithCountCache keeps the number of set bits of every 512 bits up to date
+// on every change, so CountRange and Select skip the counted regions instead
+// of counting their blocks. It costs 2 bytes per 512 bits.
+func WithCountCache() Option {
+	return func(c *config) {
+		c.countCache = true
+	}
+}
+
+// CountRange returns the number of set bits in the half-open range
+// [from, to), clamped to the capacity.
+func (b *BitArray) CountRange(from, to int64) int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.countRange(from, to)
+}
+
+// CountByRegion returns the number of set bits of every consecutive region
+// of regionSize bits, the last region ending at the capacity, in one pass
+// over the array. It returns nil unless regionSize is positive.
+func (b *BitArray) CountByRegion(regionSize int64) []int64 {
+	if regionSize <= 0 {
+		return nil
+	}
+
+	b.rlock()
+	defer b.runlock()
+
+	capacity := b.capacity.Get64()
+	res := make([]int64, 0, (capacity+regionSize-1)/regionSize)
+
+	for from := int64(0); from < capacity; from += regionSize {
+		res = append(res, b.countRange(from, from+regionSize))
+	}
+
+	return res
+}
+
+// Select returns the index of the set bit of the specified rank, the k-th
+// set bit counting from 0, or BitBlockNotFound unless there are more than k
+// set bits.
+func (b *BitArray) Select(k int64) int64 {
+	b.rlock()
+	defer b.runlock()
+
+	if k < 0 || k >= b.count.Get64() {
+		return BitBlockNotFound
+	}
+
+	i := int64(0)
+
+	if b.counts != nil {
+		for _, n := range b.counts {
+			if k < int64(n) {
+				break
+			}
+
+			k -= int64(n)
+			i += superBlockSize / blockSize
+		}
+	}
+
+	for ; i < b.size; i++ {
+		block := b.blocks[i]
+
+		if n := block.popcount(); k >= n {
+			k -= n
+			continue
+		}
+
+		for ; k > 0; k-- {
+			block &= block - 1
+		}
+
+		return b.base + (i * blockSize) + block.ffs()
+	}
+
+	return BitBlockNotFound
+}
+
+// cachedRange counts the set bits of [from, to) using the count cache for
+// the superblocks the range covers. Callers hold the read lock.
+func (b *BitArray) cachedRange(from, to int64) (n int64) {
+	first := (from + superBlockSize - 1) / superBlockSize
+	last := to / superBlockSize
+
+	for _, c := range b.counts[first:last] {
+		n += int64(c)
+	}
+
+	return n + b.scanRange(from, first*superBlockSize) + b.scanRange(last*superBlockSize, to)
+}
+
+// cache adds delta to the count of the superblock holding block i.
+// Callers hold the write lock.
+func (b *BitArray) cache(i, delta int64) {
+	b.counts[i*blockSize/superBlockSize] += uint16(delta)
+}
+
+// recache rebuilds the count cache from the blocks. Callers hold the write
+// lock.
+func (b *BitArray) recache() {
+	n := (b.size*blockSize + superBlockSize - 1) / superBlockSize
+
+	if int64(cap(b.counts)) < n {
+		b.counts = make([]uint16, n)
+	} else {
+		b.counts = b.counts[:n]
+		clear(b.counts)
+	}
+
+	for i := int64(0); i < b.size; i++ {
+		b.counts[i*blockSize/superBlockSize] += uint16(b.blocks[i].popcount())
+	}
+}
+
+// growCache extends the count cache to the grown blocks, which are clear.
+// Callers hold the write lock.
+func (b *BitArray) growCache() {
+	if n := (b.size*blockSize + superBlockSize - 1) / superBlockSize; n > int64(len(b.counts)) {
+		b.counts = append(b.counts, make([]uint16, n-int64(len(b.counts)))...)
+	}
+}
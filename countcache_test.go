@@ -0,0 +1,82 @@
+package bitarray
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayCountRange(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, opts := range [][]Option{nil, {WithCountCache()}} {
+		b := NewBitArray(3000, opts...)
+		b.setRange(100, 2900, bitBlockMark)
+
+		assert.Equal(int64(2800), b.CountRange(0, 3000))
+		assert.Equal(int64(2800), b.CountRange(-5, 5000))
+		assert.Equal(int64(1900), b.CountRange(1000, 4000))
+		assert.Equal(int64(1), b.CountRange(100, 101))
+		assert.Equal(int64(0), b.CountRange(50, 10))
+
+		assert.Equal(int64(100), b.Select(0))
+		assert.Equal(int64(1612), b.Select(1512))
+		assert.Equal(int64(2899), b.Select(2799))
+		assert.Equal(int64(BitBlockNotFound), b.Select(2800))
+		assert.Equal(int64(BitBlockNotFound), b.Select(-1))
+	}
+}
+
+func TestWithCountCache(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	b := NewBitArray(1000, WithCountCache(), WithAutoGrow(), WithInitialSet(1, 700))
+	assert.NoError(b.Validate())
+
+	check := func() {
+		assert.NoError(b.Validate())
+		assert.Equal(b.Len64(), b.CountRange(0, b.Cap64()))
+	}
+
+	for n := 0; n < 500; n++ {
+		switch rng.IntN(8) {
+		case 0:
+			b.Set(rng.Int64N(b.Cap64()+100), rng.IntN(2) == 0)
+		case 1:
+			b.MarkFree()
+		case 2:
+			b.Or(newMarked(700, rng.Int64N(700)))
+		case 3:
+			b.ApplyWord(rng.Int64N(16), rng.Uint64(), rng.Uint64())
+		case 4:
+			b.Not()
+		case 5:
+			b.Fill()
+		case 6:
+			b.Reset()
+		case 7:
+			b.Rollback(newMarked(600, 5).Checkpoint())
+		}
+
+		check()
+	}
+}
+
+func TestBitArrayCountByRegion(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, opts := range [][]Option{nil, {WithCountCache()}} {
+		b := NewBitArray(3000, opts...)
+		b.setRange(100, 2900, bitBlockMark)
+
+		assert.Equal([]int64{900, 1000, 900}, b.CountByRegion(1000))
+		assert.Equal([]int64{2800}, b.CountByRegion(5000))
+	}
+
+	assert.Nil(NewBitArray(10).CountByRegion(0))
+	assert.Empty(NewBitArray(0).CountByRegion(10))
+	assert.Equal([]int64{1, 0, 1}, newMarked(5, 0, 4).CountByRegion(2))
+}
@@ -0,0 +1,169 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The Linux kernel stores a bitmap as an array of unsigned longs, bit i
+// being bit i%BITS_PER_LONG of long i/BITS_PER_LONG, in the byte order of
+// the host. This is the layout of the masks exchanged by system calls like
+// sched_getaffinity. Its textual form, used by sysfs and procfs for the
+// cpumaps and the IRQ affinities, lists the bitmap in comma-separated
+// 32-bit groups of hexadecimal digits, the most significant group first,
+// e.g. "ff,00000001" for the bits 0 and 32-39 of a 40-bit bitmap.
+const (
+	kernelLongSize = strconv.IntSize // unsigned long is as wide as a pointer
+	maskChunkSize  = 32
+)
+
+// NewBitArrayFromKernelBitmap creates a BitArray of the specified capacity
+// from a bitmap in the unsigned long layout of the Linux kernel of the host.
+// The data may be longer than the capacity needs, but the bits beyond the
+// capacity must be clear.
+func NewBitArrayFromKernelBitmap(data []byte, capacity int64, opts ...Option) (*BitArray, error) {
+	longBytes := kernelLongSize / 8
+
+	if len(data)%longBytes != 0 {
+		return nil, fmt.Errorf("%w: %d bytes is not a multiple of %d-byte longs", ErrFormat, len(data), longBytes)
+	}
+
+	words := make([]uint64, wordCount(int64(len(data))*8))
+
+	for k := 0; k < len(data)/longBytes; k++ {
+		var l uint64
+		if longBytes == 8 {
+			l = binary.NativeEndian.Uint64(data[k*8:])
+		} else {
+			l = uint64(binary.NativeEndian.Uint32(data[k*4:]))
+		}
+
+		i := int64(k) * kernelLongSize
+		words[i/wordSize] |= l << (i % wordSize)
+	}
+
+	return fromMaskWords(words, capacity, opts)
+}
+
+// ToKernelBitmap returns the bits of the array in the unsigned long layout
+// of the Linux kernel of the host, in as many longs as the capacity needs.
+func (b *BitArray) ToKernelBitmap() []byte {
+	b.rlock()
+	defer b.runlock()
+
+	longs := (b.capacity.Get64() + kernelLongSize - 1) / kernelLongSize
+	data := make([]byte, longs*kernelLongSize/8)
+
+	for k := int64(0); k < longs; k++ {
+		i := k * kernelLongSize
+		l := b.word(i/wordSize) >> (i % wordSize)
+
+		if kernelLongSize == 64 {
+			binary.NativeEndian.PutUint64(data[k*8:], l)
+		} else {
+			binary.NativeEndian.PutUint32(data[k*4:], uint32(l))
+		}
+	}
+
+	return data
+}
+
+// ParseMask creates a BitArray of the specified capacity from the textual
+// form of a Linux kernel bitmap, as read from e.g.
+// /sys/devices/system/cpu/online or /proc/irq/N/smp_affinity. Groups may
+// have fewer than 8 digits and surrounding whitespace, like the trailing
+// newline of sysfs, is ignored. The bits beyond the capacity must be clear.
+func ParseMask(s string, capacity int64, opts ...Option) (*BitArray, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return fromMaskWords(nil, capacity, opts)
+	}
+
+	chunks := strings.Split(s, ",")
+	words := make([]uint64, wordCount(int64(len(chunks))*maskChunkSize))
+
+	for n, chunk := range chunks {
+		v, err := strconv.ParseUint(chunk, 16, maskChunkSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad mask group %q", ErrSyntax, chunk)
+		}
+
+		i := int64(len(chunks)-1-n) * maskChunkSize
+		words[i/wordSize] |= v << (i % wordSize)
+	}
+
+	return fromMaskWords(words, capacity, opts)
+}
+
+// FormatMask returns the bits of the array in the textual form of a Linux
+// kernel bitmap sized to the capacity, like the kernel prints them: the
+// full groups have 8 digits and the most significant one as many as its
+// bits need, e.g. "f" for a 4-bit array with all the bits set, and "0" for
+// an array of zero capacity.
+func (b *BitArray) FormatMask() string {
+	b.rlock()
+	defer b.runlock()
+
+	capacity := b.capacity.Get64()
+	if capacity == 0 {
+		return "0"
+	}
+
+	width := capacity % maskChunkSize
+	if width == 0 {
+		width = maskChunkSize
+	}
+
+	var buf []byte
+
+	for i := (capacity+maskChunkSize-1)/maskChunkSize*maskChunkSize - maskChunkSize; i >= 0; i -= maskChunkSize {
+		v := b.word(i/wordSize) >> (i % wordSize) & (1<<width - 1)
+
+		if len(buf) > 0 {
+			buf = append(buf, ',')
+		}
+
+		digits := strconv.FormatUint(v, 16)
+		for n := len(digits); n < int((width+3)/4); n++ {
+			buf = append(buf, '0')
+		}
+
+		buf = append(buf, digits...)
+		width = maskChunkSize
+	}
+
+	return string(buf)
+}
+
+// fromMaskWords creates a BitArray of capacity bits from words, in the
+// layout of the binary encoding, checking that no bit beyond the capacity
+// is set.
+func fromMaskWords(words []uint64, capacity int64, opts []Option) (*BitArray, error) {
+	if err := checkCapacity(capacity); err != nil {
+		return nil, err
+	}
+
+	n := min(int64(len(words)), wordCount(capacity))
+
+	for k := n; k < int64(len(words)); k++ {
+		if words[k] != 0 {
+			return nil, fmt.Errorf("%w: bits set beyond capacity %d", ErrOutOfRange, capacity)
+		}
+	}
+
+	if n > 0 && capacity%wordSize != 0 && words[n-1]>>(capacity%wordSize) != 0 {
+		return nil, fmt.Errorf("%w: bits set beyond capacity %d", ErrOutOfRange, capacity)
+	}
+
+	b := NewBitArray(capacity, opts...)
+
+	for k, w := range words[:n] {
+		if w != 0 {
+			b.setWord(int64(k), w)
+		}
+	}
+
+	return b, nil
+}
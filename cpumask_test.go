@@ -0,0 +1,90 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMask(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("0", NewBitArray(0).FormatMask())
+	assert.Equal("f", newMarked(4, 0, 1, 2, 3).FormatMask())
+	assert.Equal("00", NewBitArray(8).FormatMask())
+	assert.Equal("00000001", newMarked(32, 0).FormatMask())
+	assert.Equal("ff,00000001", newMarked(40, 0, 32, 33, 34, 35, 36, 37, 38, 39).FormatMask())
+	assert.Equal("1,00000000,80000000", newMarked(65, 31, 64).FormatMask())
+}
+
+func TestParseMask(t *testing.T) {
+	assert := assert.New(t)
+
+	b, err := ParseMask("ff,00000001\n", 40)
+	assert.NoError(err)
+	assert.Equal("0,32-39", b.FormatRanges())
+	assert.Equal("ff,00000001", b.FormatMask())
+	assert.NoError(b.Validate())
+
+	b, err = ParseMask("0,f0", 8)
+	assert.NoError(err)
+	assert.Equal("4-7", b.FormatRanges())
+
+	b, err = ParseMask("80000000,00000000,00000001", 96)
+	assert.NoError(err)
+	assert.Equal("0,95", b.FormatRanges())
+	assert.Equal(2, b.Len())
+
+	b, err = ParseMask(NewBitArray(0).FormatMask(), 0)
+	assert.NoError(err)
+	assert.Zero(b.Cap())
+
+	b, err = ParseMask("", 10)
+	assert.NoError(err)
+	assert.Equal(10, b.Cap())
+
+	_, err = ParseMask("1ff", 8)
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	_, err = ParseMask("1,00000000,00000000", 64)
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	for _, s := range []string{"g", "1,,2", "123456789", "+1", "0x1"} {
+		_, err = ParseMask(s, 64)
+		assert.True(errors.Is(err, ErrSyntax), s)
+	}
+}
+
+func TestKernelBitmap(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(70, 0, 33, 69)
+	data := b.ToKernelBitmap()
+
+	if strconv.IntSize == 64 {
+		assert.Len(data, 16)
+		assert.Equal(uint64(1|1<<33), binary.NativeEndian.Uint64(data))
+		assert.Equal(uint64(1<<5), binary.NativeEndian.Uint64(data[8:]))
+	} else {
+		assert.Len(data, 12)
+		assert.Equal(uint32(1), binary.NativeEndian.Uint32(data))
+		assert.Equal(uint32(1<<1), binary.NativeEndian.Uint32(data[4:]))
+	}
+
+	c, err := NewBitArrayFromKernelBitmap(data, 70)
+	assert.NoError(err)
+	assert.Equal("0,33,69", c.FormatRanges())
+
+	_, err = NewBitArrayFromKernelBitmap(data, 69)
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	_, err = NewBitArrayFromKernelBitmap(data[:3], 8)
+	assert.True(errors.Is(err, ErrFormat))
+
+	c, err = NewBitArrayFromKernelBitmap(append(data, make([]byte, 8)...), 70)
+	assert.NoError(err)
+	assert.Equal("0,33,69", c.FormatRanges())
+}
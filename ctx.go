@@ -0,0 +1,172 @@
+package bitarray
+
+import "context"
+
+// ctxCheckBlocks is the number of blocks the context-aware operations
+// process between two checks of the context.
+const ctxCheckBlocks = 1 << 14
+
+// CountRangeCtx is like CountRange but stops with the error of ctx once it
+// is cancelled, checking it every few thousand blocks.
+func (b *BitArray) CountRangeCtx(ctx context.Context, from, to int64) (n int64, err error) {
+	b.rlock()
+	defer b.runlock()
+
+	if from, to = b.clamp(from, to); from >= to {
+		return 0, ctx.Err()
+	}
+
+	for lo := from; lo < to; lo += ctxCheckBlocks * blockSize {
+		if err = ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		n += b.countRange(lo, min(to, lo+ctxCheckBlocks*blockSize))
+	}
+
+	return
+}
+
+// ForEachCtx is like ForEach but stops with the error of ctx once it is
+// cancelled, checking it every few thousand blocks. It returns nil if the
+// iteration completed or fn returned false.
+func (b *BitArray) ForEachCtx(ctx context.Context, fn func(index int64) bool) error {
+	b.rlock()
+	defer b.runlock()
+
+	for i := int64(0); i < b.size; i++ {
+		if i%ctxCheckBlocks == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		for block := b.blocks[i]; block != 0; block &= block - 1 {
+			if !fn(b.base + (i * blockSize) + block.ffs()) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// AndCtx is like AndE but stops with the error of ctx once it is cancelled,
+// checking it every few thousand blocks. The blocks processed before the
+// cancellation keep the result, so the array is left partially combined,
+// with a consistent count.
+func (b *BitArray) AndCtx(ctx context.Context, other *BitArray) error {
+	return b.applyCtx(ctx, other, opAnd)
+}
+
+// OrCtx is like OrE but stops with the error of ctx once it is cancelled,
+// like AndCtx.
+func (b *BitArray) OrCtx(ctx context.Context, other *BitArray) error {
+	return b.applyCtx(ctx, other, opOr)
+}
+
+// XorCtx is like XorE but stops with the error of ctx once it is cancelled,
+// like AndCtx.
+func (b *BitArray) XorCtx(ctx context.Context, other *BitArray) error {
+	if b == other {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		b.lock()
+		defer b.unlock()
+
+		b.clearAll()
+
+		return nil
+	}
+
+	return b.applyCtx(ctx, other, opXor)
+}
+
+func (b *BitArray) applyCtx(ctx context.Context, other *BitArray, op bulkOp) error {
+	if b == other {
+		return ctx.Err()
+	}
+
+	unlock := lockPair(b, other)
+	defer unlock()
+
+	if err := b.matchCapacity(other); err != nil {
+		return err
+	}
+
+	defer func() {
+		if b.counts != nil {
+			b.recache()
+		}
+
+		b.curIndex = 0
+	}()
+
+	n := min(b.size, other.size)
+
+	end := n
+	if op == opAnd {
+		end = b.size // the blocks beyond other are cleared
+	}
+
+	for lo := int64(0); lo < end; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		hi := min(end, lo+ctxCheckBlocks)
+		if lo < n {
+			hi = min(hi, n) // chunks do not straddle the end of other
+		}
+
+		b.combineChunk(other, op, lo, hi, n)
+		lo = hi
+	}
+
+	return nil
+}
+
+// combineChunk combines the blocks [lo, hi) of other into b and updates the
+// count. The blocks of other from n on count as clear, so a chunk lies either
+// below n or above it. The count cache is left to the caller. Callers hold
+// the locks of both arrays.
+func (b *BitArray) combineChunk(other *BitArray, op bulkOp, lo, hi, n int64) {
+	if b.blockwise() {
+		for i := lo; i < hi; i++ {
+			var src BitBlock
+			if i < n {
+				src = other.blocks[i]
+			}
+
+			b.setBlock(i, op.block(b.blocks[i], src))
+		}
+
+		return
+	}
+
+	dst := b.blocks[lo:hi]
+	before := countBlocks(dst)
+
+	switch {
+	case lo >= n:
+		clear(dst)
+
+	case op == opAnd:
+		andBlocks(dst, other.blocks[lo:hi])
+
+	case op == opOr:
+		orBlocks(dst, other.blocks[lo:hi])
+
+	case op == opXor:
+		xorBlocks(dst, other.blocks[lo:hi])
+	}
+
+	// only the last block can hold bits of other beyond the capacity
+	if hi == b.size {
+		b.blocks[hi-1] &= b.tailMask()
+	}
+
+	b.count.Add64(countBlocks(dst) - before)
+}
@@ -0,0 +1,88 @@
+package bitarray
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayCountRangeCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(3*ctxCheckBlocks*blockSize, 0, 5, ctxCheckBlocks*blockSize, 3*ctxCheckBlocks*blockSize-1)
+
+	n, err := b.CountRangeCtx(context.Background(), 0, b.Cap64())
+	assert.NoError(err)
+	assert.EqualValues(4, n)
+
+	n, err = b.CountRangeCtx(context.Background(), 1, 6)
+	assert.NoError(err)
+	assert.EqualValues(1, n)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = b.CountRangeCtx(ctx, 0, b.Cap64())
+	assert.True(errors.Is(err, context.Canceled))
+}
+
+func TestBitArrayForEachCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(3*ctxCheckBlocks*blockSize, 1, 64, 3*ctxCheckBlocks*blockSize-1)
+
+	var res []int64
+	assert.NoError(b.ForEachCtx(context.Background(), func(index int64) bool {
+		res = append(res, index)
+		return true
+	}))
+	assert.Equal([]int64{1, 64, 3*ctxCheckBlocks*blockSize - 1}, res)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res = nil
+
+	err := b.ForEachCtx(ctx, func(index int64) bool {
+		res = append(res, index)
+		cancel()
+		return true
+	})
+	assert.True(errors.Is(err, context.Canceled))
+	assert.Equal([]int64{1, 64}, res)
+}
+
+func TestBitArrayAndCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	const capacity = 3*ctxCheckBlocks*blockSize + 10
+
+	for _, opts := range [][]Option{nil, {WithCountCache()}} {
+		b := NewBitArray(capacity, opts...)
+		b.MarkAll(1, 2, ctxCheckBlocks*blockSize, capacity-1)
+
+		assert.NoError(b.AndCtx(context.Background(), newMarked(capacity, 2, 3, capacity-1)))
+		assert.Equal(fmt.Sprintf("2,%d", capacity-1), b.FormatRanges())
+		assert.NoError(b.Validate())
+
+		assert.NoError(b.OrCtx(context.Background(), newMarked(capacity, 7)))
+		assert.NoError(b.XorCtx(context.Background(), newMarked(capacity, 7, 8)))
+		assert.Equal(fmt.Sprintf("2,8,%d", capacity-1), b.FormatRanges())
+		assert.Equal(3, b.Len())
+
+		assert.NoError(b.AndCtx(context.Background(), newMarked(100, 2)))
+		assert.Equal("2", b.FormatRanges())
+		assert.NoError(b.Validate())
+	}
+
+	b := NewBitArray(10, WithCapacityPolicy(CapacityError))
+	assert.True(errors.Is(b.AndCtx(context.Background(), NewBitArray(20)), ErrCapacityMismatch))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b = newMarked(capacity, 1)
+	assert.True(errors.Is(b.OrCtx(ctx, newMarked(capacity, 2)), context.Canceled))
+	assert.Equal(1, b.Len())
+}
@@ -0,0 +1,104 @@
+package bitarray
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// DebugOptions controls the handler returned by DebugHandler.
+type DebugOptions struct {
+	// Queries enables the bit and range queries.
+	Queries bool
+
+	// MaxRange limits the length of a queried range, 1<<20 by default.
+	MaxRange int64
+}
+
+const defaultMaxRange = 1 << 20
+
+// DebugHandler returns an HTTP handler serving the statistics of the array
+// as JSON, like PublishExpvar. If opts.Queries is set, it also answers
+//
+//	?bit=12345   the value of a bit
+//	?range=0-999 the number and the runs of set bits in an inclusive range
+//
+// Both take and report the indexes of WithBaseOffset, like Get. A bit out of
+// range is rejected with 400 Bad Request whatever the range policy. It is
+// meant for an internal admin port; it does not modify the array.
+func (b *BitArray) DebugHandler(opts DebugOptions) http.Handler {
+	if opts.MaxRange <= 0 {
+		opts.MaxRange = defaultMaxRange
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		v := b.stats()
+
+		if opts.Queries && q.Has("bit") {
+			index, err := strconv.ParseInt(q.Get("bit"), 10, 64)
+			if err != nil {
+				http.Error(w, "bad bit "+strconv.Quote(q.Get("bit")), http.StatusBadRequest)
+				return
+			}
+
+			if i := b.in(index); i < 0 || i >= b.Cap64() {
+				http.Error(w, "bit "+strconv.Quote(q.Get("bit"))+" out of range", http.StatusBadRequest)
+				return
+			}
+
+			set, err := b.GetE(index)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			v = map[string]any{"bit": index, "value": set}
+		} else if opts.Queries && q.Has("range") {
+			first, last, err := parseRange(q.Get("range"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if last-first >= opts.MaxRange {
+				http.Error(w, "range longer than "+strconv.FormatInt(opts.MaxRange, 10), http.StatusBadRequest)
+				return
+			}
+
+			v = b.queryRange(first, last+1)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	})
+}
+
+// queryRange returns the number and the runs of set bits in the half-open
+// range [from, to) of external indexes.
+func (b *BitArray) queryRange(from, to int64) map[string]any {
+	b.rlock()
+	defer b.runlock()
+
+	v := map[string]any{"range": string(appendRange(nil, from, to))}
+	from, to = b.in(from), b.in(to)
+	v["count"] = b.countRange(from, to)
+	buf := []byte{}
+
+	if from, to = b.clamp(from, to); from < to {
+		for start := b.nextSet(from); start < to; start = b.nextSet(start) {
+			end := min(b.nextClear(start), to)
+
+			if len(buf) > 0 {
+				buf = append(buf, ',')
+			}
+
+			buf = appendRange(buf, b.out(start), b.out(end))
+			start = end
+		}
+	}
+
+	v["set"] = string(buf)
+
+	return v
+}
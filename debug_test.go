@@ -0,0 +1,64 @@
+package bitarray
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayDebugHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(200, 1, 2, 3, 10, 150)
+	h := b.DebugHandler(DebugOptions{Queries: true, MaxRange: 100})
+
+	get := func(query string) (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+query, nil))
+
+		return w.Code, w.Body.String()
+	}
+
+	code, body := get("")
+	assert.Equal(http.StatusOK, code)
+	assert.JSONEq(`{"len":5,"cap":200,"utilization":0.025}`, body)
+
+	_, body = get("?bit=10")
+	assert.JSONEq(`{"bit":10,"value":true}`, body)
+
+	_, body = get("?range=2-99")
+	assert.JSONEq(`{"range":"2-99","count":3,"set":"2-3,10"}`, body)
+
+	_, body = get("?range=190-199")
+	assert.JSONEq(`{"range":"190-199","count":0,"set":""}`, body)
+
+	code, _ = get("?bit=x")
+	assert.Equal(http.StatusBadRequest, code)
+
+	code, _ = get("?range=0-100")
+	assert.Equal(http.StatusBadRequest, code)
+
+	code, _ = get("?bit=1000")
+	assert.Equal(http.StatusBadRequest, code)
+
+	// queries are disabled by default
+	w := httptest.NewRecorder()
+	b.DebugHandler(DebugOptions{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?bit=10", nil))
+	assert.JSONEq(`{"len":5,"cap":200,"utilization":0.025}`, w.Body.String())
+
+	// the queries take the indexes of the base offset
+	b = NewBitArray(200, WithBaseOffset(1000), WithRangePolicy(RangePanic))
+	b.MarkAll(1010, 1011)
+	h = b.DebugHandler(DebugOptions{Queries: true})
+
+	_, body = get("?bit=1010")
+	assert.JSONEq(`{"bit":1010,"value":true}`, body)
+
+	_, body = get("?range=1000-1099")
+	assert.JSONEq(`{"range":"1000-1099","count":2,"set":"1010-1011"}`, body)
+
+	code, _ = get("?bit=5")
+	assert.Equal(http.StatusBadRequest, code)
+}
@@ -0,0 +1,50 @@
+package bitarray
+
+// DeltaSince returns the bits of b that changed since the state prev, the
+// Xor of both, with the capacity of b. Bits beyond the capacity of prev are
+// considered clear. A replica holding prev reaches the state of b with
+// ApplyDelta; the delta of a short interval is mostly clear, so it ships
+// compactly with MarshalSparse.
+func (b *BitArray) DeltaSince(prev *BitArray) *BitArray {
+	unlock := rlockPair(b, prev)
+	defer unlock()
+
+	delta := NewBitArray(b.capacity.Get64())
+	copy(delta.blocks, b.blocks[:b.size])
+
+	if prev != b {
+		n := min(delta.size, prev.size)
+		xorBlocks(delta.blocks[:n], prev.blocks[:n])
+	} else {
+		clear(delta.blocks)
+	}
+
+	if delta.size > 0 {
+		delta.blocks[delta.size-1] &= delta.tailMask()
+	}
+
+	delta.recount()
+
+	return delta
+}
+
+// ApplyDelta flips the bits of b that are set in delta, as returned by
+// DeltaSince, and maintains the number of set bits. The array grows to the
+// capacity of the delta regardless of its range policy, following the growth
+// of the array the delta was taken from.
+func (b *BitArray) ApplyDelta(delta *BitArray) {
+	if b == delta {
+		b.lock()
+		defer b.unlock()
+
+		b.clearAll()
+
+		return
+	}
+
+	unlock := lockPair(b, delta)
+	defer unlock()
+
+	b.grow(delta.capacity.Get64())
+	b.combine(delta, opXor, 1)
+}
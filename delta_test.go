@@ -0,0 +1,56 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayDelta(t *testing.T) {
+	assert := assert.New(t)
+
+	primary := newMarked(100, 1, 2, 70)
+	replica := primary.CloneWithCapacity(100)
+	prev := primary.CloneWithCapacity(100)
+
+	primary.Unmark(2)
+	primary.Mark(3)
+	primary.Grow(100)
+	primary.Mark(150)
+
+	delta := primary.DeltaSince(prev)
+	assert.Equal(200, delta.Cap())
+	assert.Equal("2-3,150", delta.FormatRanges())
+	assert.Equal(3, delta.Len())
+	assert.NoError(delta.Validate())
+
+	replica.ApplyDelta(delta)
+	assert.Equal(200, replica.Cap())
+	assert.Equal(primary.FormatRanges(), replica.FormatRanges())
+	assert.Equal(primary.Len(), replica.Len())
+	assert.NoError(replica.Validate())
+
+	assert.Equal(0, primary.DeltaSince(primary).Len())
+	assert.Equal(0, primary.DeltaSince(replica).Len())
+
+	data, err := delta.MarshalSparse()
+	assert.NoError(err)
+
+	shipped := NewBitArray(0)
+	assert.NoError(shipped.UnmarshalBinary(data))
+	prev.ApplyDelta(shipped)
+	assert.Equal(primary.FormatRanges(), prev.FormatRanges())
+}
+
+func TestBitArrayDeltaJournaled(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 2)
+	ch := b.Watch(0, 200)
+
+	b.ApplyDelta(newMarked(130, 2, 120))
+	assert.Equal("1,120", b.FormatRanges())
+	assert.Equal(Change{Index: 2, Mark: false}, <-ch)
+	assert.Equal(Change{Index: 120, Mark: true}, <-ch)
+	b.Unwatch(ch)
+}
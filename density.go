@@ -0,0 +1,62 @@
+package bitarray
+
+import (
+	"math"
+	"math/bits"
+)
+
+// DensityHistogram returns the number of 64-bit words of the array, the
+// words of the binary encoding, in each of the specified number of buckets
+// of equal width over the popcounts 0 to 64, so bucket k holds the words
+// with a popcount p where k == p*buckets/65. With 65 buckets every popcount
+// has its own. The last word counts only the bits below the capacity. It
+// returns nil unless buckets is positive.
+func (b *BitArray) DensityHistogram(buckets int) []int64 {
+	if buckets <= 0 {
+		return nil
+	}
+
+	b.rlock()
+	defer b.runlock()
+
+	res := make([]int64, buckets)
+
+	for k, n := int64(0), wordCount(b.capacity.Get64()); k < n; k++ {
+		p := bits.OnesCount64(b.word(k))
+		res[p*buckets/(wordSize+1)]++
+	}
+
+	return res
+}
+
+// Skew returns how unevenly the set bits are distributed over the regions
+// of regionSize bits, the last region ending at the capacity: the standard
+// deviation of the densities of the regions divided by their mean, each
+// region weighted by its size. It is 0 for evenly spread bits and grows as
+// they cluster, up to sqrt(n-1) for n regions with all the set bits in one.
+// It returns 0 if no bit is set or regionSize is not positive.
+func (b *BitArray) Skew(regionSize int64) float64 {
+	if regionSize <= 0 {
+		return 0
+	}
+
+	b.rlock()
+	defer b.runlock()
+
+	capacity, count := b.capacity.Get64(), b.count.Get64()
+	if count == 0 {
+		return 0
+	}
+
+	mean := ratio(count, capacity)
+
+	var variance float64
+
+	for from := int64(0); from < capacity; from += regionSize {
+		size := min(regionSize, capacity-from)
+		d := ratio(b.countRange(from, from+size), size) - mean
+		variance += d * d * float64(size)
+	}
+
+	return math.Sqrt(variance/float64(capacity)) / mean
+}
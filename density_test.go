@@ -0,0 +1,51 @@
+package bitarray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayDensityHistogram(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(300)
+	b.setRange(64, 128, bitBlockMark)
+	b.setRange(128, 160, bitBlockMark)
+	b.Mark(200)
+
+	assert.Equal([]int64{5}, b.DensityHistogram(1))
+	assert.Equal([]int64{3, 1, 1}, b.DensityHistogram(3))
+
+	h := b.DensityHistogram(65)
+	assert.Len(h, 65)
+	assert.Equal(int64(2), h[0])
+	assert.Equal(int64(1), h[1])
+	assert.Equal(int64(1), h[32])
+	assert.Equal(int64(1), h[64])
+
+	assert.Nil(b.DensityHistogram(0))
+	assert.Equal([]int64{0, 0}, NewBitArray(0).DensityHistogram(2))
+}
+
+func TestBitArraySkew(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(400)
+	assert.Equal(0.0, b.Skew(100))
+
+	b.MarkAll(0, 100, 200, 300)
+	assert.Equal(0.0, b.Skew(100))
+
+	b.Reset()
+	b.setRange(0, 100, bitBlockMark)
+	assert.InDelta(math.Sqrt(3), b.Skew(100), 1e-9)
+	assert.InDelta(1.0, b.Skew(200), 1e-9)
+
+	b.Mark(350)
+	assert.Less(b.Skew(100), math.Sqrt(3))
+
+	assert.Equal(0.0, b.Skew(0))
+	assert.Equal(0.0, NewBitArrayFull(250).Skew(100))
+}
@@ -0,0 +1,61 @@
+package bitarray
+
+// Diff returns the indexes of the bits set in next but not in prev (added)
+// and of the bits set in prev but not in next (removed), both in ascending
+// order. Bits beyond the capacity of an array are considered clear.
+func Diff(prev, next *BitArray) (added, removed []int64) {
+	DiffFunc(prev, next, func(index int64, set bool) bool {
+		if set {
+			added = append(added, index)
+		} else {
+			removed = append(removed, index)
+		}
+
+		return true
+	})
+
+	return
+}
+
+// DiffFunc is the streaming variant of Diff. It calls fn for every bit that
+// differs between prev and next in ascending order, with set reporting
+// whether the bit is set in next, until fn returns false. Both arrays are
+// read-locked during the call, so fn must not modify them.
+func DiffFunc(prev, next *BitArray, fn func(index int64, set bool) bool) {
+	unlock := rlockPair(prev, next)
+	defer unlock()
+
+	for i, n := int64(0), max(prev.size, next.size); i < n; i++ {
+		block := next.blockAt(i)
+
+		for d := prev.blockAt(i) ^ block; d != 0; d &= d - 1 {
+			j := d.ffs()
+
+			if !fn((i*blockSize)+j, block.value(j)) {
+				return
+			}
+		}
+	}
+}
+
+// EqualUpTo reports whether the first n bits of b and other, the bits
+// [0, n), are equal. Bits beyond the capacity of an array are considered
+// clear, so arrays of different capacities may share a prefix.
+func (b *BitArray) EqualUpTo(other *BitArray, n int64) bool {
+	if b == other || n <= 0 {
+		return true
+	}
+
+	unlock := rlockPair(b, other)
+	defer unlock()
+
+	last := min((n-1)/blockSize, max(b.size, other.size)-1)
+
+	for i := int64(0); i <= last; i++ {
+		if (b.blockAt(i)^other.blockAt(i))&rangeMask(i, 0, n) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,61 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	prev := newMarked(200, 1, 2, 70, 150)
+	next := newMarked(300, 2, 3, 70, 250)
+
+	added, removed := Diff(prev, next)
+	assert.Equal([]int64{3, 250}, added)
+	assert.Equal([]int64{1, 150}, removed)
+
+	added, removed = Diff(prev, prev)
+	assert.Empty(added)
+	assert.Empty(removed)
+}
+
+func TestDiffFunc(t *testing.T) {
+	assert := assert.New(t)
+
+	prev := newMarked(200, 1, 2)
+	next := newMarked(200, 3, 4)
+
+	var changes []int64
+	DiffFunc(prev, next, func(index int64, set bool) bool {
+		if !set {
+			index = -index
+		}
+		changes = append(changes, index)
+
+		return len(changes) < 3
+	})
+
+	assert.Equal([]int64{-1, -2, 3}, changes)
+}
+
+func TestBitArrayEqualUpTo(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 1, 64, 200)
+	c := newMarked(100, 1, 64, 99)
+
+	assert.True(b.EqualUpTo(c, 0))
+	assert.True(b.EqualUpTo(c, 65))
+	assert.True(b.EqualUpTo(c, 99))
+	assert.False(b.EqualUpTo(c, 100))
+	assert.False(c.EqualUpTo(b, 1000))
+
+	c.Unmark(99)
+	assert.True(b.EqualUpTo(c, 200))
+	assert.False(b.EqualUpTo(c, 201))
+	assert.True(b.EqualUpTo(b, 1000))
+	assert.True(NewBitArray(0).EqualUpTo(NewBitArray(10), 10))
+	assert.True(b.EqualUpTo(c, -5))
+}
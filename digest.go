@@ -0,0 +1,40 @@
+package bitarray
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Digest returns the SHA-256 digest of the binary encoding of the array
+// without its checksum. Arrays with the same capacity and bits have the same
+// digest in any process and on any platform, so it may serve as a map key
+// for deduplication or to compare arrays held by different processes.
+func (b *BitArray) Digest() [32]byte {
+	b.rlock()
+	defer b.runlock()
+
+	capacity := b.capacity.Get64()
+
+	buf := make([]byte, 0, 4096)
+	buf = append(buf, binaryMagic...)
+	buf = append(buf, binaryVersion)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(capacity))
+
+	h := sha256.New()
+
+	for k, n := int64(0), wordCount(capacity); k < n; k++ {
+		if len(buf) == cap(buf) {
+			h.Write(buf)
+			buf = buf[:0]
+		}
+
+		buf = binary.LittleEndian.AppendUint64(buf, b.word(k))
+	}
+
+	h.Write(buf)
+
+	var sum [32]byte
+	h.Sum(sum[:0])
+
+	return sum
+}
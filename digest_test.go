@@ -0,0 +1,27 @@
+package bitarray
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayDigest(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100000, 1, 500, 99999)
+
+	data := mustMarshal(t, b)
+	assert.Equal(sha256.Sum256(data[:len(data)-checksumLen]), b.Digest())
+
+	c := NewBitArray(100000)
+	c.MarkAll(99999, 500, 1)
+	assert.Equal(b.Digest(), c.Digest())
+	assert.NotEqual(b.Digest(), newMarked(100001, 1, 500, 99999).Digest())
+
+	c.Unmark(500)
+	assert.NotEqual(b.Digest(), c.Digest())
+
+	assert.NotEqual(NewBitArray(0).Digest(), NewBitArray(1).Digest())
+}
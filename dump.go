@@ -0,0 +1,132 @@
+package bitarray
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// DumpOptions controls the output of Dump.
+type DumpOptions struct {
+	// Ranges enables the list of the runs of set bits, e.g. "0-3,7,16-31".
+	Ranges bool
+
+	// MaxRanges limits the number of listed runs, zero means no limit.
+	MaxRanges int
+
+	// Heat enables a map showing the utilization of consecutive regions,
+	// from ' ' for an empty region to '@' for a full one.
+	Heat bool
+
+	// RegionSize is the number of bits summarized by a cell of the heat map,
+	// rounded up to a multiple of the block size. Defaults to 64.
+	RegionSize int64
+
+	// Width is the number of heat map cells per line, 64 by default.
+	Width int
+}
+
+// heatLevels are the characters of the heat map, from empty to full.
+const heatLevels = " .:-=+*#%@"
+
+// Dump writes a human-readable summary of the array to w: the capacity, the
+// number of set bits and, as requested by opts, the runs of set bits and a
+// utilization heat map. Unlike String, the size of the output does not grow
+// with the capacity unless the listed runs do. The array is read-locked while
+// the output is written.
+func (b *BitArray) Dump(w io.Writer, opts DumpOptions) error {
+	b.rlock()
+	defer b.runlock()
+
+	bw := bufio.NewWriter(w)
+	capacity, count := b.capacity.Get64(), b.count.Get64()
+
+	fmt.Fprintf(bw, "capacity: %d\ncount: %d", capacity, count)
+	if capacity > 0 {
+		fmt.Fprintf(bw, " (%.2f%%)", float64(count)*100/float64(capacity))
+	}
+	bw.WriteByte('\n')
+
+	if opts.Ranges {
+		b.dumpRanges(bw, opts)
+	}
+
+	if opts.Heat {
+		b.dumpHeat(bw, opts)
+	}
+
+	return bw.Flush()
+}
+
+func (b *BitArray) dumpRanges(bw *bufio.Writer, opts DumpOptions) {
+	buf := make([]byte, 0, 64)
+	n := 0
+
+	bw.WriteString("ranges: ")
+
+	b.forEachRun(func(start, end int64) bool {
+		if opts.MaxRanges > 0 && n == opts.MaxRanges {
+			bw.WriteString(",...")
+			return false
+		}
+
+		if n > 0 {
+			bw.WriteByte(',')
+		}
+
+		bw.Write(appendRange(buf[:0], start, end))
+		n++
+
+		return true
+	})
+
+	bw.WriteByte('\n')
+}
+
+func (b *BitArray) dumpHeat(bw *bufio.Writer, opts DumpOptions) {
+	region := (max(opts.RegionSize, wordSize) + blockSize - 1) / blockSize * blockSize
+
+	width := int64(opts.Width)
+	if width <= 0 {
+		width = 64
+	}
+
+	fmt.Fprintf(bw, "heat: %d bits per cell\n", region)
+
+	capacity := b.capacity.Get64()
+
+	for line := int64(0); line < capacity; line += region * width {
+		fmt.Fprintf(bw, "%12d ", line)
+
+		for from := line; from < line+region*width && from < capacity; from += region {
+			to := min(from+region, capacity)
+
+			switch n := b.countRange(from, to); n {
+			case 0:
+				bw.WriteByte(heatLevels[0])
+
+			case to - from:
+				bw.WriteByte(heatLevels[len(heatLevels)-1])
+
+			default:
+				bw.WriteByte(heatLevels[1+n*int64(len(heatLevels)-2)/(to-from)])
+			}
+		}
+
+		bw.WriteByte('\n')
+	}
+}
+
+// appendRange appends the half-open range [start, end) in the range syntax,
+// "start-last" or just "start" for a single bit.
+func appendRange(buf []byte, start, end int64) []byte {
+	buf = strconv.AppendInt(buf, start, 10)
+
+	if end-start > 1 {
+		buf = append(buf, '-')
+		buf = strconv.AppendInt(buf, end-1, 10)
+	}
+
+	return buf
+}
@@ -0,0 +1,53 @@
+package bitarray
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayDump(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(256, 0, 1, 2, 3, 7, 100)
+	for i := int64(128); i < 192; i++ {
+		b.Mark(i)
+	}
+
+	var sb strings.Builder
+	assert.NoError(b.Dump(&sb, DumpOptions{}))
+	assert.Equal("capacity: 256\ncount: 70 (27.34%)\n", sb.String())
+
+	sb.Reset()
+	assert.NoError(b.Dump(&sb, DumpOptions{Ranges: true, MaxRanges: 2}))
+	assert.Contains(sb.String(), "ranges: 0-3,7,...\n")
+
+	sb.Reset()
+	assert.NoError(b.Dump(&sb, DumpOptions{Ranges: true, Heat: true, Width: 2}))
+	assert.Equal("capacity: 256\ncount: 70 (27.34%)\n"+
+		"ranges: 0-3,7,100,128-191\n"+
+		"heat: 64 bits per cell\n"+
+		"           0 ..\n"+
+		"         128 @ \n", sb.String())
+}
+
+func TestBitArrayDumpEmpty(t *testing.T) {
+	var sb strings.Builder
+	assert.NoError(t, NewBitArray(0).Dump(&sb, DumpOptions{Ranges: true, Heat: true}))
+	assert.Equal(t, "capacity: 0\ncount: 0\nranges: \nheat: 64 bits per cell\n", sb.String())
+}
+
+func TestBitArrayNextSetClear(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(200, 5, 130)
+
+	assert.Equal(int64(5), b.nextSet(0))
+	assert.Equal(int64(130), b.nextSet(6))
+	assert.Equal(int64(200), b.nextSet(131))
+	assert.Equal(int64(0), b.nextClear(0))
+	assert.Equal(int64(6), b.nextClear(5))
+	assert.Equal(int64(2), b.countRange(0, 200))
+	assert.Equal(int64(1), b.countRange(6, 131))
+}
@@ -0,0 +1,271 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"runtime"
+	"slices"
+	"strconv"
+)
+
+// ErrFormat is returned when serialized data cannot be decoded.
+var ErrFormat = errors.New("bitarray: invalid encoding")
+
+// The binary encoding consists of
+//
+//	magic    [4]byte  "BARR"
+//	version  uint8    1
+//	capacity uint64   little-endian
+//	words    []uint64 little-endian, ceil(capacity/64) words, bit i of the
+//	                  array is bit i%64 of word i/64
+//	checksum uint32   little-endian CRC-32 (IEEE) of all the preceding bytes
+const (
+	binaryMagic   = "BARR"
+	binaryVersion = 1
+
+	binaryHeaderLen = len(binaryMagic) + 1 + 8
+	checksumLen     = 4
+
+	wordSize = 64
+)
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (b *BitArray) MarshalBinary() ([]byte, error) {
+	b.rlock()
+	defer b.runlock()
+
+	return b.appendBinary(nil), nil
+}
+
+// AppendBinary implements the encoding.BinaryAppender interface. It appends
+// the encoding of MarshalBinary to dst, so a buffer can be reused across
+// snapshots.
+func (b *BitArray) AppendBinary(dst []byte) ([]byte, error) {
+	b.rlock()
+	defer b.runlock()
+
+	return b.appendBinary(dst), nil
+}
+
+// appendBinary appends the binary encoding of the array to data.
+// Callers hold the read lock.
+func (b *BitArray) appendBinary(data []byte) []byte {
+	capacity := b.capacity.Get64()
+	n := wordCount(capacity)
+	start := len(data)
+
+	data = slices.Grow(data, binaryHeaderLen+int(n)*8+checksumLen)
+	data = append(data, binaryMagic...)
+	data = append(data, binaryVersion)
+	data = binary.LittleEndian.AppendUint64(data, uint64(capacity))
+
+	for k := int64(0); k < n; k++ {
+		data = binary.LittleEndian.AppendUint64(data, b.word(k))
+	}
+
+	return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data[start:]))
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// It replaces the contents and the capacity of the array. It decodes the
+// encodings of both MarshalBinary and MarshalSparse.
+func (b *BitArray) UnmarshalBinary(data []byte) error {
+	version, capacity, payload, err := decodeBinary(data)
+	if err != nil {
+		return err
+	}
+
+	if version == sparseVersion {
+		return b.unmarshalSparse(capacity, payload)
+	}
+
+	if capacity < 0 || int64(len(payload)) != wordCount(capacity)*8 {
+		return fmt.Errorf("%w: %d bytes of words for capacity %d", ErrFormat, len(payload), capacity)
+	}
+
+	words := make([]uint64, len(payload)/8)
+	for k := range words {
+		words[k] = binary.LittleEndian.Uint64(payload[k*8:])
+	}
+
+	return b.load(capacity, words)
+}
+
+// decodeBinary checks the header and the checksum of a binary encoding and
+// returns its version, capacity and the payload following the header.
+func decodeBinary(data []byte) (version byte, capacity int64, payload []byte, err error) {
+	if len(data) < binaryHeaderLen+checksumLen {
+		return 0, 0, nil, fmt.Errorf("%w: %d bytes is too short", ErrFormat, len(data))
+	}
+
+	if string(data[:len(binaryMagic)]) != binaryMagic {
+		return 0, 0, nil, fmt.Errorf("%w: bad magic", ErrFormat)
+	}
+
+	version = data[len(binaryMagic)]
+	if version != binaryVersion && version != sparseVersion {
+		return 0, 0, nil, fmt.Errorf("%w: unsupported version %d", ErrFormat, version)
+	}
+
+	body, sum := data[:len(data)-checksumLen], data[len(data)-checksumLen:]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(sum) {
+		return 0, 0, nil, fmt.Errorf("%w: checksum mismatch", ErrFormat)
+	}
+
+	capacity = int64(binary.LittleEndian.Uint64(data[len(binaryMagic)+1:]))
+
+	return version, capacity, body[binaryHeaderLen:], nil
+}
+
+// jsonBitArray is the JSON representation of a BitArray. The words are
+// encoded as hexadecimal strings, so they survive decoders that use floating
+// point numbers.
+type jsonBitArray struct {
+	Capacity int64    `json:"capacity"`
+	Words    []string `json:"words"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b *BitArray) MarshalJSON() ([]byte, error) {
+	b.rlock()
+
+	v := jsonBitArray{
+		Capacity: b.capacity.Get64(),
+		Words:    make([]string, wordCount(b.capacity.Get64())),
+	}
+
+	for k := range v.Words {
+		v.Words[k] = fmt.Sprintf("%016x", b.word(int64(k)))
+	}
+
+	b.runlock()
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It replaces the contents and the capacity of the array.
+func (b *BitArray) UnmarshalJSON(data []byte) error {
+	var v jsonBitArray
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.Capacity < 0 || int64(len(v.Words)) != wordCount(v.Capacity) {
+		return fmt.Errorf("%w: %d words for capacity %d", ErrFormat, len(v.Words), v.Capacity)
+	}
+
+	words := make([]uint64, len(v.Words))
+
+	for k, s := range v.Words {
+		w, err := strconv.ParseUint(s, 16, 64)
+		if err != nil {
+			return fmt.Errorf("%w: bad word %q", ErrFormat, s)
+		}
+
+		words[k] = w
+	}
+
+	return b.load(v.Capacity, words)
+}
+
+// load replaces the contents of the array with capacity bits taken from
+// words, in the layout of the binary encoding.
+func (b *BitArray) load(capacity int64, words []uint64) error {
+	if err := checkCapacity(capacity); err != nil {
+		return fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	if n := len(words); n > 0 && capacity%wordSize != 0 && words[n-1]>>(capacity%wordSize) != 0 {
+		return fmt.Errorf("%w: bits set beyond capacity %d", ErrFormat, capacity)
+	}
+
+	blocks, err := b.tryAlloc(capacity/blockSize + 1)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	for k, w := range words {
+		for n := int64(0); n < wordSize/blockSize; n++ {
+			if i := int64(k)*(wordSize/blockSize) + n; i < int64(len(blocks)) {
+				blocks[i] = BitBlock(w >> (n * blockSize))
+			}
+		}
+	}
+
+	b.install(capacity, blocks)
+
+	return nil
+}
+
+// install replaces the storage of the array with the decoded blocks of
+// capacity bits.
+func (b *BitArray) install(capacity int64, blocks []BitBlock) {
+	b.lock()
+	defer b.unlock()
+
+	b.blocks = blocks
+	b.size = int64(len(blocks))
+	b.curIndex = 0
+	b.capacity.Set64(capacity)
+	b.recount()
+
+	if b.journal != nil {
+		b.journal.replace()
+	}
+}
+
+// tryAlloc is like alloc, but reports storage the platform cannot allocate,
+// e.g. for a capacity read from untrusted data, as an error wrapping
+// ErrCapacity instead of panicking.
+func (b *BitArray) tryAlloc(n int64) (blocks []BitBlock, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			re, ok := r.(runtime.Error)
+			if !ok {
+				panic(r)
+			}
+
+			err = fmt.Errorf("%w: %d blocks: %v", ErrCapacity, n, re)
+		}
+	}()
+
+	return b.alloc(n), nil
+}
+
+// word returns the k-th 64-bit word of the binary encoding.
+// Callers hold the read lock.
+func (b *BitArray) word(k int64) (w uint64) {
+	for n := int64(0); n < wordSize/blockSize; n++ {
+		if i := k*(wordSize/blockSize) + n; i < b.size {
+			w |= uint64(b.blocks[i]) << (n * blockSize)
+		}
+	}
+
+	return
+}
+
+// setWord replaces the k-th 64-bit word of the binary encoding through
+// setBlock. Callers hold the write lock.
+func (b *BitArray) setWord(k int64, w uint64) {
+	for n := int64(0); n < wordSize/blockSize; n++ {
+		if i := k*(wordSize/blockSize) + n; i < b.size {
+			b.setBlock(i, BitBlock(w>>(n*blockSize)))
+		}
+	}
+}
+
+// words returns the number of 64-bit words overlaying the blocks.
+// Callers hold the read lock.
+func (b *BitArray) words() int64 {
+	return (b.size*blockSize + wordSize - 1) / wordSize
+}
+
+// wordCount returns the number of 64-bit words holding capacity bits.
+func wordCount(capacity int64) int64 {
+	return (capacity + wordSize - 1) / wordSize
+}
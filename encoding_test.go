@@ -0,0 +1,79 @@
+package bitarray
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayMarshalBinary(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, capacity := range []int64{0, 1, 64, 65, 1000} {
+		b := NewBitArray(capacity)
+		for i := int64(0); i < capacity; i += 7 {
+			b.Mark(i)
+		}
+
+		data, err := b.MarshalBinary()
+		assert.NoError(err)
+
+		var c BitArray
+		assert.NoError(c.UnmarshalBinary(data))
+		assert.Equal(b.String(), c.String())
+		assert.Equal(b.Len(), c.Len())
+		assert.NoError(c.Validate())
+	}
+}
+
+func TestBitArrayAppendBinary(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 99)
+	want, _ := b.MarshalBinary()
+
+	buf := make([]byte, 0, 256)
+	for range 2 {
+		data, err := b.AppendBinary(append(buf[:0], "prefix"...))
+		assert.NoError(err)
+		assert.Equal("prefix", string(data[:6]))
+		assert.Equal(want, data[6:])
+		assert.Equal(&buf[:1][0], &data[0])
+	}
+}
+
+func TestBitArrayUnmarshalBinaryCorrupt(t *testing.T) {
+	assert := assert.New(t)
+
+	data, _ := newMarked(100, 1, 99).MarshalBinary()
+
+	var c BitArray
+
+	broken := append([]byte(nil), data...)
+	broken[len(broken)-6] ^= 1
+	assert.True(errors.Is(c.UnmarshalBinary(broken), ErrFormat))
+
+	assert.True(errors.Is(c.UnmarshalBinary(data[:10]), ErrFormat))
+	assert.True(errors.Is(c.UnmarshalBinary(append([]byte("XXXX"), data[4:]...)), ErrFormat))
+}
+
+func TestBitArrayMarshalJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 0, 1, 64, 99)
+
+	data, err := json.Marshal(b)
+	assert.NoError(err)
+	assert.JSONEq(`{"capacity":100,"words":["0000000000000003","0000000800000001"]}`, string(data))
+
+	c := NewBitArray(0)
+	assert.NoError(json.Unmarshal(data, c))
+	assert.Equal(b.String(), c.String())
+	assert.Equal(4, c.Len())
+
+	assert.True(errors.Is(c.UnmarshalJSON([]byte(`{"capacity":100,"words":["3"]}`)), ErrFormat))
+	assert.True(errors.Is(c.UnmarshalJSON([]byte(`{"capacity":1,"words":["3"]}`)), ErrFormat))
+	assert.True(errors.Is(c.UnmarshalJSON([]byte(`{"capacity":1,"words":["x"]}`)), ErrFormat))
+}
@@ -0,0 +1,36 @@
+package bitarray
+
+// Epoch returns the current epoch of the array, the number of times it has
+// been Reset. The epoch is not persisted, so it starts at 0 for arrays
+// restored from storage.
+func (b *BitArray) Epoch() uint64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.epoch
+}
+
+// EpochOf returns the epoch of the allocation at the specified index, which
+// a caller keeps with the index as a handle, and reports whether the bit is
+// set. After a Reset the handles of the previous epochs are stale even if
+// their bits are allocated again, which ValidateHandle detects.
+func (b *BitArray) EpochOf(index int64) (epoch uint64, ok bool) {
+	b.rlock()
+	defer b.runlock()
+
+	ok, _ = b.get(b.in(index))
+
+	return b.epoch, ok
+}
+
+// ValidateHandle reports whether the handle of the specified index and
+// epoch, as returned by EpochOf, is still valid: the bit is set and the
+// array has not been Reset since.
+func (b *BitArray) ValidateHandle(index int64, epoch uint64) bool {
+	b.rlock()
+	defer b.runlock()
+
+	ok, _ := b.get(b.in(index))
+
+	return ok && epoch == b.epoch
+}
@@ -0,0 +1,38 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpoch(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	assert.Equal(uint64(0), b.Epoch())
+
+	index := b.MarkFree()
+	epoch, ok := b.EpochOf(index)
+	assert.True(ok)
+	assert.Equal(uint64(0), epoch)
+	assert.True(b.ValidateHandle(index, epoch))
+
+	_, ok = b.EpochOf(index + 1)
+	assert.False(ok)
+	assert.False(b.ValidateHandle(index+1, epoch))
+
+	b.Reset()
+	assert.Equal(uint64(1), b.Epoch())
+	assert.False(b.ValidateHandle(index, epoch))
+
+	assert.Equal(index, b.MarkFree())
+	assert.False(b.ValidateHandle(index, epoch)) // allocated again
+
+	epoch, _ = b.EpochOf(index)
+	assert.True(b.ValidateHandle(index, epoch))
+
+	b.Unmark(index)
+	assert.False(b.ValidateHandle(index, epoch))
+	assert.False(b.ValidateHandle(-1, epoch))
+}
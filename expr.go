@@ -0,0 +1,195 @@
+package bitarray
+
+import "iter"
+
+// exprChunk is the number of blocks an Expression evaluates at a time.
+const exprChunk = 512
+
+// Expression is a lazily evaluated combination of arrays, built by Expr and
+// the chained operations. It is evaluated chunk by chunk of blocks when it
+// is counted, iterated or materialized, so no temporary array is allocated
+// however many arrays it combines. The result has the capacity of the first
+// array, and the operations with the other arrays behave like the methods
+// of the same name of BitArray applied in order.
+type Expression struct {
+	first *BitArray
+	steps []exprStep
+}
+
+type exprStep struct {
+	op      exprOp
+	operand *BitArray
+}
+
+type exprOp int
+
+const (
+	exprAnd exprOp = iota
+	exprOr
+	exprXor
+	exprAndNot
+	exprNot
+)
+
+// Expr starts an expression with the bits of b.
+func Expr(b *BitArray) *Expression {
+	return &Expression{first: b}
+}
+
+// And keeps the bits that are also set in b.
+func (e *Expression) And(b *BitArray) *Expression {
+	return e.with(exprAnd, b)
+}
+
+// Or adds the bits that are set in b.
+func (e *Expression) Or(b *BitArray) *Expression {
+	return e.with(exprOr, b)
+}
+
+// Xor flips the bits that are set in b.
+func (e *Expression) Xor(b *BitArray) *Expression {
+	return e.with(exprXor, b)
+}
+
+// AndNot clears the bits that are set in b.
+func (e *Expression) AndNot(b *BitArray) *Expression {
+	return e.with(exprAndNot, b)
+}
+
+// Not flips all the bits up to the capacity.
+func (e *Expression) Not() *Expression {
+	return e.with(exprNot, nil)
+}
+
+func (e *Expression) with(op exprOp, b *BitArray) *Expression {
+	return &Expression{
+		first: e.first,
+		steps: append(e.steps[:len(e.steps):len(e.steps)], exprStep{op, b}),
+	}
+}
+
+// Count returns the number of set bits of the result.
+func (e *Expression) Count() (n int64) {
+	e.eval(func(_ int64, blocks []BitBlock) bool {
+		n += countBlocks(blocks)
+		return true
+	})
+
+	return
+}
+
+// SetBits returns an iterator over the indexes of the set bits of the result
+// in ascending order. The arrays are read-locked during the iteration, so
+// the loop body must not modify them.
+func (e *Expression) SetBits() iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		e.eval(func(i int64, blocks []BitBlock) bool {
+			for k, block := range blocks {
+				for ; block != 0; block &= block - 1 {
+					if !yield((i+int64(k))*blockSize + block.ffs()) {
+						return false
+					}
+				}
+			}
+
+			return true
+		})
+	}
+}
+
+// Materialize returns the result as a new array created with the specified
+// options.
+func (e *Expression) Materialize(opts ...Option) *BitArray {
+	// the array is created before the arrays of e are locked, since the
+	// options may run arbitrary code
+	res := NewBitArray(e.first.Cap64(), opts...)
+
+	res.lock()
+	defer res.unlock()
+
+	e.eval(func(i int64, blocks []BitBlock) bool {
+		if capacity := e.first.capacity.Get64(); i == 0 && capacity != res.capacity.Get64() {
+			// the capacity of the first array has changed since
+			res.size = e.first.size
+			res.blocks = res.alloc(res.size)
+			res.capacity.Set64(capacity)
+		}
+
+		copy(res.blocks[i:], blocks)
+
+		return true
+	})
+
+	res.recount()
+
+	return res
+}
+
+// eval evaluates the expression and calls fn with the blocks of the result
+// starting at block i, chunk by chunk, until fn returns false. The buffer
+// passed to fn is reused.
+func (e *Expression) eval(fn func(i int64, blocks []BitBlock) bool) {
+	arrays := []*BitArray{e.first}
+
+	for _, s := range e.steps {
+		if s.operand != nil {
+			arrays = append(arrays, s.operand)
+		}
+	}
+
+	unlock := lockSet(nil, arrays...)
+	defer unlock()
+
+	size := e.first.size
+	buf := make([]BitBlock, min(size, exprChunk))
+
+	for i := int64(0); i < size; i += exprChunk {
+		chunk := buf[:min(size-i, exprChunk)]
+		copy(chunk, e.first.blocks[i:])
+
+		for _, s := range e.steps {
+			e.step(chunk, i, s)
+		}
+
+		if i+int64(len(chunk)) == size {
+			chunk[len(chunk)-1] &= e.first.tailMask()
+		}
+
+		if !fn(i, chunk) {
+			return
+		}
+	}
+}
+
+// step applies the step s to the chunk of blocks starting at block i.
+func (e *Expression) step(chunk []BitBlock, i int64, s exprStep) {
+	if s.op == exprNot {
+		for k := range chunk {
+			chunk[k] = ^chunk[k]
+		}
+
+		return
+	}
+
+	var src []BitBlock
+	if i < s.operand.size {
+		src = s.operand.blocks[i:min(s.operand.size, i+int64(len(chunk)))]
+	}
+
+	switch s.op {
+	case exprAnd:
+		andBlocks(chunk, src)
+		clear(chunk[len(src):])
+
+	case exprOr:
+		orBlocks(chunk, src)
+
+	case exprXor:
+		xorBlocks(chunk, src)
+
+	case exprAndNot:
+		for k, block := range src {
+			chunk[k] &^= block
+		}
+	}
+}
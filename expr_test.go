@@ -0,0 +1,51 @@
+package bitarray
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpr(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b, c := newMarked(100, 1, 2, 64, 99), newMarked(70, 2, 64, 65), newMarked(200, 3, 150)
+
+	e := Expr(a).And(b).Or(c)
+	assert.Equal(int64(3), e.Count())
+	assert.Equal([]int64{2, 3, 64}, slices.Collect(e.SetBits())[:3])
+	assert.Equal("2-3", Expr(a).And(b).Or(c).AndNot(newMarked(100, 64)).Materialize().FormatRanges())
+	assert.Equal("0,4-63,65-98", Expr(a).Xor(c).Not().Materialize().FormatRanges())
+	assert.Equal(100, e.Materialize().Cap())
+
+	base := Expr(a)
+	x, y := base.Or(c), base.And(b)
+	assert.Equal("1-3,64,99", x.Materialize().FormatRanges())
+	assert.Equal("2,64", y.Materialize().FormatRanges())
+
+	for range e.SetBits() {
+		break
+	}
+}
+
+func TestExprLarge(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(7, 8))
+	capacity := int64(3*exprChunk*blockSize + 5)
+
+	a, b, c := randomArray(rng, capacity, 5000), randomArray(rng, capacity/2, 5000), randomArray(rng, 2*capacity, 5000)
+
+	want := a.Freeze().Thaw()
+	want.And(b)
+	want.Or(c)
+	want.Not()
+
+	got := Expr(a).And(b).Or(c).Not().Materialize()
+	assert.Equal(want.String(), got.String())
+	assert.Equal(want.Len64(), Expr(a).And(b).Or(c).Not().Count())
+	assert.Equal(slices.Collect(want.SetBits()), slices.Collect(Expr(a).And(b).Or(c).Not().SetBits()))
+	assert.NoError(got.Validate())
+}
@@ -0,0 +1,27 @@
+package bitarray
+
+import "expvar"
+
+// PublishExpvar registers the live statistics of the array under name in
+// the expvar package: the number of set bits ("len"), the capacity ("cap")
+// and their ratio ("utilization"). Like expvar.Publish, it panics if the
+// name is already registered.
+func (b *BitArray) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return b.stats()
+	}))
+}
+
+// stats returns the statistics published by PublishExpvar.
+func (b *BitArray) stats() map[string]any {
+	b.rlock()
+	defer b.runlock()
+
+	count, capacity := b.count.Get64(), b.capacity.Get64()
+
+	return map[string]any{
+		"len":         count,
+		"cap":         capacity,
+		"utilization": ratio(count, capacity),
+	}
+}
@@ -0,0 +1,20 @@
+package bitarray
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayPublishExpvar(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(200)
+	b.PublishExpvar("bitarray_test")
+
+	b.MarkAll(1, 2)
+	assert.JSONEq(`{"len":2,"cap":200,"utilization":0.01}`, expvar.Get("bitarray_test").String())
+
+	assert.Panics(func() { b.PublishExpvar("bitarray_test") })
+}
@@ -0,0 +1,96 @@
+package bitarray
+
+import "slices"
+
+// ExtractBits gathers the bits of b at the indexes set in mask into a dense
+// array, like the PEXT instruction: bit k of the result is the bit of b at
+// the index of the k-th set bit of mask. The capacity of the result is the
+// number of set bits in mask; indexes of mask beyond the capacity of b read
+// as false.
+func (b *BitArray) ExtractBits(mask *BitArray) *BitArray {
+	unlock := rlockPair(b, mask)
+	defer unlock()
+
+	dst := NewBitArray(mask.count.Get64())
+	capacity := b.capacity.Get64()
+	k := int64(0)
+
+	for i := int64(0); i < mask.size; i++ {
+		for m := mask.blocks[i]; m != 0; m &= m - 1 {
+			if index := (i * blockSize) + m.ffs(); index < capacity && b.bit(index) {
+				dst.blocks[k/blockSize].mark(k % blockSize)
+			}
+
+			k++
+		}
+	}
+
+	dst.recount()
+
+	return dst
+}
+
+// DepositBits scatters the bits of src to the indexes of b set in mask, like
+// the PDEP instruction: the bit of b at the index of the k-th set bit of
+// mask becomes bit k of src. The other bits of b are left unchanged, so
+// depositing into an empty array matches PDEP. Indexes of mask beyond the
+// capacity of b are ignored and bits beyond the capacity of src read as
+// false.
+func (b *BitArray) DepositBits(src, mask *BitArray) {
+	if src == b {
+		src = b.detached()
+	}
+
+	unlock := lockSet(b, src, mask)
+	defer unlock()
+
+	capacity := src.capacity.Get64()
+	k := int64(0)
+
+	for i, n := int64(0), min(b.size, mask.size); i < n; i++ {
+		m := mask.blocks[i] & b.validMask(i)
+		if m == 0 {
+			continue
+		}
+
+		block := b.blocks[i]
+
+		for ; m != 0; m &= m - 1 {
+			if j := m.ffs(); k < capacity && src.bit(k) {
+				block.mark(j)
+			} else {
+				block.unmark(j)
+			}
+
+			k++
+		}
+
+		b.setBlock(i, block)
+	}
+
+	b.curIndex = 0
+}
+
+// bit returns the bit at index, which lies within the capacity. Callers
+// hold the read lock.
+func (b *BitArray) bit(index int64) bool {
+	i, j := bitIndexAndNum(index)
+	return b.blocks[i].value(j)
+}
+
+// detached returns a copy of the bits of b that is not locked and not
+// observed.
+func (b *BitArray) detached() *BitArray {
+	b.rlock()
+	defer b.runlock()
+
+	c := &BitArray{
+		blocks:  slices.Clone(b.blocks[:b.size]),
+		size:    b.size,
+		locking: LockNone,
+	}
+	c.capacity.Set64(b.capacity.Get64())
+	c.count.Set64(b.count.Get64())
+
+	return c
+}
@@ -0,0 +1,45 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayExtractBits(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(200, 3, 10, 70, 150)
+	mask := newMarked(300, 2, 3, 70, 71, 150, 250)
+
+	e := b.ExtractBits(mask)
+	assert.Equal(6, e.Cap())
+	assert.Equal("1-2,4", e.FormatRanges())
+	assert.NoError(e.Validate())
+
+	assert.Equal(0, b.ExtractBits(NewBitArray(10)).Cap())
+	assert.Equal(b.Len(), b.ExtractBits(b).Len())
+}
+
+func TestBitArrayDepositBits(t *testing.T) {
+	assert := assert.New(t)
+
+	mask := newMarked(300, 2, 3, 70, 71, 150, 250)
+	src := newMarked(6, 1, 2, 4, 5)
+
+	b := newMarked(200, 2, 100)
+	b.DepositBits(src, mask)
+	assert.Equal("3,70,100,150", b.FormatRanges())
+	assert.Equal(4, b.Len())
+	assert.NoError(b.Validate())
+
+	// a round trip through ExtractBits
+	d := NewBitArray(200)
+	d.DepositBits(b.ExtractBits(mask), mask)
+	assert.Equal("3,70,150", d.FormatRanges())
+
+	// the source is the receiver
+	b = newMarked(8, 0, 1)
+	b.DepositBits(b, newMarked(8, 1, 3, 5))
+	assert.Equal("0-1,3", b.FormatRanges())
+}
@@ -0,0 +1,98 @@
+package bitarray
+
+// First returns the lowest index of a set bit, or BitBlockNotFound if no bit
+// is set.
+func (b *BitArray) First() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.out(b.found(b.nextSet(0)))
+}
+
+// Last returns the highest index of a set bit, or BitBlockNotFound if no bit
+// is set.
+func (b *BitArray) Last() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.out(b.prev(b.capacity.Get64()-1, false))
+}
+
+// FirstClear returns the lowest index of a clear bit below the capacity, or
+// BitBlockNotFound if the array is full.
+func (b *BitArray) FirstClear() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.out(b.found(b.nextClear(0)))
+}
+
+// LastClear returns the highest index of a clear bit below the capacity, or
+// BitBlockNotFound if the array is full.
+func (b *BitArray) LastClear() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.out(b.prev(b.capacity.Get64()-1, true))
+}
+
+// TrailingZeros returns the number of clear bits below the lowest set bit,
+// the free space at the start of the array, like bits.TrailingZeros counts
+// from the least significant bit. It is the capacity if no bit is set.
+func (b *BitArray) TrailingZeros() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	if index := b.found(b.nextSet(0)); index != BitBlockNotFound {
+		return index
+	}
+
+	return b.capacity.Get64()
+}
+
+// LeadingZeros returns the number of clear bits above the highest set bit up
+// to the capacity, the free space at the end of the array, like
+// bits.LeadingZeros counts from the most significant bit. It is the capacity
+// if no bit is set.
+func (b *BitArray) LeadingZeros() int64 {
+	b.rlock()
+	defer b.runlock()
+
+	capacity := b.capacity.Get64()
+
+	return capacity - 1 - b.prev(capacity-1, false)
+}
+
+// found returns index, or BitBlockNotFound if a forward scan reached the
+// capacity. Callers hold the read lock.
+func (b *BitArray) found(index int64) int64 {
+	if index >= b.capacity.Get64() {
+		return BitBlockNotFound
+	}
+
+	return index
+}
+
+// prev returns the index of the last set bit, or the last clear bit, at or
+// before from, or BitBlockNotFound if there is none. Callers hold the read
+// lock.
+func (b *BitArray) prev(from int64, clear bool) int64 {
+	if capacity := b.capacity.Get64(); from >= capacity {
+		from = capacity - 1
+	}
+
+	for i := from / blockSize; from >= 0; i-- {
+		block := b.blocks[i]
+		if clear {
+			block = ^block
+		}
+
+		if block &= rangeMask(i, 0, from+1); block != 0 {
+			return (i * blockSize) + block.fls()
+		}
+
+		from = (i * blockSize) - 1
+	}
+
+	return BitBlockNotFound
+}
@@ -0,0 +1,67 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayFirstLast(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(300)
+	assert.Equal(int64(BitBlockNotFound), b.First())
+	assert.Equal(int64(BitBlockNotFound), b.Last())
+
+	b.MarkAll(70, 130, 200)
+	assert.Equal(int64(70), b.First())
+	assert.Equal(int64(200), b.Last())
+
+	b.MarkAll(0, 299)
+	assert.Equal(int64(0), b.First())
+	assert.Equal(int64(299), b.Last())
+
+	var z BitArray
+	assert.Equal(int64(BitBlockNotFound), z.First())
+	assert.Equal(int64(BitBlockNotFound), z.Last())
+}
+
+func TestBitArrayFirstLastClear(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArrayFull(300)
+	assert.Equal(int64(BitBlockNotFound), b.FirstClear())
+	assert.Equal(int64(BitBlockNotFound), b.LastClear())
+
+	b.Unmark(70)
+	b.Unmark(200)
+	assert.Equal(int64(70), b.FirstClear())
+	assert.Equal(int64(200), b.LastClear())
+
+	b = NewBitArray(300)
+	assert.Equal(int64(0), b.FirstClear())
+	assert.Equal(int64(299), b.LastClear())
+
+	var z BitArray
+	assert.Equal(int64(BitBlockNotFound), z.FirstClear())
+	assert.Equal(int64(BitBlockNotFound), z.LastClear())
+}
+
+func TestBitArrayLeadingTrailingZeros(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(200)
+	assert.Equal(int64(200), b.TrailingZeros())
+	assert.Equal(int64(200), b.LeadingZeros())
+
+	b.MarkAll(70, 130)
+	assert.Equal(int64(70), b.TrailingZeros())
+	assert.Equal(int64(69), b.LeadingZeros())
+
+	b.MarkAll(0, 199)
+	assert.Equal(int64(0), b.TrailingZeros())
+	assert.Equal(int64(0), b.LeadingZeros())
+
+	assert.Equal(int64(0), NewBitArray(0).TrailingZeros())
+	assert.Equal(int64(0), NewBitArray(0).LeadingZeros())
+}
@@ -0,0 +1,153 @@
+package bitarray
+
+import "fmt"
+
+// truncated marks the output of Format cut short by the precision.
+const truncated = "..."
+
+// Format implements the fmt.Formatter interface with the verbs
+//
+//	%b, %s  the bits as '0' and '1' characters, like String
+//	%x, %X  the 64-bit words as space-separated hexadecimal numbers of 16
+//	        digits, the lowest word first, bit i of a word holding index
+//	        wordIndex*64+i like in ForEachWord
+//	%v      the set bits in the range syntax of FormatRanges
+//
+// The precision limits the number of bits, words or ranges printed, and
+// "..." is appended if there are more, e.g. fmt.Sprintf("%.8b", b) prints the
+// first 8 bits. The output is padded with spaces to the width, on the left
+// unless the '-' flag is given.
+func (b *BitArray) Format(f fmt.State, verb rune) {
+	limit, ok := f.Precision()
+	if !ok {
+		limit = -1
+	}
+
+	var buf []byte
+
+	switch verb {
+	case 'b', 's':
+		buf = b.appendBits(buf, int64(limit))
+
+	case 'x', 'X':
+		buf = b.appendWords(buf, int64(limit), verb == 'X')
+
+	case 'v':
+		buf = b.appendRanges(buf, limit)
+
+	default:
+		fmt.Fprintf(f, "%%!%c(*bitarray.BitArray)", verb)
+		return
+	}
+
+	if width, ok := f.Width(); ok && len(buf) < width {
+		pad := make([]byte, width-len(buf))
+		for i := range pad {
+			pad[i] = ' '
+		}
+
+		if f.Flag('-') {
+			buf = append(buf, pad...)
+		} else {
+			buf = append(pad, buf...)
+		}
+	}
+
+	f.Write(buf)
+}
+
+// appendBits appends the first limit bits as '0' and '1' characters to buf,
+// all of them if limit is negative.
+func (b *BitArray) appendBits(buf []byte, limit int64) []byte {
+	b.rlock()
+	defer b.runlock()
+
+	n := b.capacity.Get64()
+	if limit >= 0 && limit < n {
+		n = limit
+	}
+
+	for index := int64(0); index < n; index++ {
+		i, j := bitIndexAndNum(index)
+
+		if b.blocks[i].value(j) {
+			buf = append(buf, '1')
+		} else {
+			buf = append(buf, '0')
+		}
+	}
+
+	if n < b.capacity.Get64() {
+		buf = append(buf, truncated...)
+	}
+
+	return buf
+}
+
+// appendWords appends the first limit 64-bit words in hexadecimal to buf,
+// all of them if limit is negative.
+func (b *BitArray) appendWords(buf []byte, limit int64, upper bool) []byte {
+	b.rlock()
+	defer b.runlock()
+
+	words := b.words()
+
+	n := words
+	if limit >= 0 && limit < n {
+		n = limit
+	}
+
+	for k := int64(0); k < n; k++ {
+		if k > 0 {
+			buf = append(buf, ' ')
+		}
+
+		buf = appendHex(buf, b.word(k), upper)
+	}
+
+	if n < words {
+		if n > 0 {
+			buf = append(buf, ' ')
+		}
+
+		buf = append(buf, truncated...)
+	}
+
+	return buf
+}
+
+// appendRanges appends the first limit runs of set bits in the range syntax
+// to buf, all of them if limit is negative.
+func (b *BitArray) appendRanges(buf []byte, limit int) []byte {
+	b.rlock()
+	defer b.runlock()
+
+	n := 0
+
+	b.forEachRun(func(start, end int64) bool {
+		if n > 0 {
+			buf = append(buf, ',')
+		}
+
+		if n == limit {
+			buf = append(buf, truncated...)
+			return false
+		}
+
+		buf = appendRange(buf, start, end)
+		n++
+
+		return true
+	})
+
+	return buf
+}
+
+// appendHex appends w as 16 hexadecimal digits to buf.
+func appendHex(buf []byte, w uint64, upper bool) []byte {
+	if upper {
+		return fmt.Appendf(buf, "%016X", w)
+	}
+
+	return fmt.Appendf(buf, "%016x", w)
+}
@@ -0,0 +1,34 @@
+package bitarray
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(70, 0, 1, 2, 3, 7, 64, 69)
+
+	assert.Equal(b.String(), fmt.Sprintf("%b", b))
+	assert.Equal(b.String(), fmt.Sprintf("%s", b))
+	assert.Equal("10010000...", fmt.Sprintf("%.8b", newMarked(70, 0, 3)))
+	assert.Equal("0000000000000000000000000000000000000000000000000000000000000000000000", fmt.Sprintf("%.100b", NewBitArray(70)))
+
+	assert.Equal("000000000000008f 0000000000000021", fmt.Sprintf("%x", b))
+	assert.Equal("000000000000008F ...", fmt.Sprintf("%.1X", b))
+	assert.Equal("...", fmt.Sprintf("%.0x", b))
+
+	assert.Equal("0-3,7,64,69", fmt.Sprintf("%v", b))
+	assert.Equal("0-3,7,...", fmt.Sprintf("%.2v", b))
+	assert.Equal("0-3,7,64,69", fmt.Sprintf("%.4v", b))
+	assert.Equal("0-3,7,64,69", fmt.Sprint(b))
+	assert.Equal("", fmt.Sprint(NewBitArray(10)))
+
+	assert.Equal("     0-3,7", fmt.Sprintf("%10.2v", newMarked(10, 0, 1, 2, 3, 7)))
+	assert.Equal("0-3,7     |", fmt.Sprintf("%-10v|", newMarked(10, 0, 1, 2, 3, 7)))
+
+	assert.Equal("%!d(*bitarray.BitArray)", fmt.Sprintf("%d", b))
+}
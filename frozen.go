@@ -0,0 +1,90 @@
+package bitarray
+
+import "iter"
+
+// FrozenBitArray is an immutable copy of a BitArray. It has no methods that
+// change it, so it is read without locks and may be shared by any number of
+// goroutines.
+type FrozenBitArray struct {
+	b *BitArray
+}
+
+// Freeze returns an immutable copy of the array, e.g. to hand the result of
+// a build phase to many readers. Later changes of b do not affect it.
+func (b *BitArray) Freeze() *FrozenBitArray {
+	return &FrozenBitArray{b: b.detached()}
+}
+
+// Thaw returns a mutable copy of the frozen array, created with the
+// specified options.
+func (f *FrozenBitArray) Thaw(opts ...Option) *BitArray {
+	b := NewBitArray(f.Cap64(), opts...)
+	b.CopyFrom(f.b)
+
+	return b
+}
+
+// Get returns the value of the bit with the specified index. Indexes out of
+// range are reported as false.
+func (f *FrozenBitArray) Get(index int64) bool {
+	return index >= 0 && index < f.b.capacity.Get64() && f.b.bit(index)
+}
+
+// Len returns the number of set bits.
+func (f *FrozenBitArray) Len() int {
+	return f.b.Len()
+}
+
+// Len64 returns the number of set bits.
+func (f *FrozenBitArray) Len64() int64 {
+	return f.b.Len64()
+}
+
+// Cap returns the capacity.
+func (f *FrozenBitArray) Cap() int {
+	return f.b.Cap()
+}
+
+// Cap64 returns the capacity.
+func (f *FrozenBitArray) Cap64() int64 {
+	return f.b.Cap64()
+}
+
+// First returns the index of the first set bit, or BitBlockNotFound.
+func (f *FrozenBitArray) First() int64 {
+	return f.b.First()
+}
+
+// Last returns the index of the last set bit, or BitBlockNotFound.
+func (f *FrozenBitArray) Last() int64 {
+	return f.b.Last()
+}
+
+// SetBits returns an iterator over the indexes of the set bits in ascending
+// order.
+func (f *FrozenBitArray) SetBits() iter.Seq[int64] {
+	return f.b.SetBits()
+}
+
+// ClearBits returns an iterator over the indexes of the clear bits in
+// ascending order.
+func (f *FrozenBitArray) ClearBits() iter.Seq[int64] {
+	return f.b.ClearBits()
+}
+
+// String returns the bits as a string of '0' and '1' characters, the lowest
+// index first.
+func (f *FrozenBitArray) String() string {
+	return f.b.String()
+}
+
+// FormatRanges returns the set bits in the range syntax of
+// BitArray.FormatRanges.
+func (f *FrozenBitArray) FormatRanges() string {
+	return f.b.FormatRanges()
+}
+
+// MarshalBinary encodes the bits like BitArray.MarshalBinary.
+func (f *FrozenBitArray) MarshalBinary() ([]byte, error) {
+	return f.b.MarshalBinary()
+}
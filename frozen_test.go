@@ -0,0 +1,44 @@
+package bitarray
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayFreeze(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 64, 99)
+	f := b.Freeze()
+	b.Mark(2)
+
+	assert.True(f.Get(64))
+	assert.False(f.Get(2))
+	assert.False(f.Get(100))
+	assert.False(f.Get(-1))
+	assert.Equal(3, f.Len())
+	assert.Equal(100, f.Cap())
+	assert.Equal(int64(1), f.First())
+	assert.Equal(int64(99), f.Last())
+	assert.Equal([]int64{1, 64, 99}, slices.Collect(f.SetBits()))
+	assert.Equal("1,64,99", f.FormatRanges())
+
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal("1,64,99", f.FormatRanges())
+		}()
+	}
+	wg.Wait()
+
+	c := f.Thaw()
+	c.Mark(3)
+	assert.Equal("1,3,64,99", c.FormatRanges())
+	assert.Equal("1,64,99", f.FormatRanges())
+	assert.NoError(c.Validate())
+}
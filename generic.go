@@ -0,0 +1,80 @@
+package bitarray
+
+import "math"
+
+// Integer is a constraint that permits any integer type used as an index.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Set sets the bit at the specified index of b to the specified value.
+// It is like BitArray.Set for an index of any integer type.
+func Set[T Integer](b *BitArray, index T, mark bool) (changed bool) {
+	return b.Set(toIndex(index), mark)
+}
+
+// Get returns the value of the bit of b with the specified index.
+// It is like BitArray.Get for an index of any integer type.
+func Get[T Integer](b *BitArray, index T) bool {
+	return b.Get(toIndex(index))
+}
+
+// Mark sets the bit at the specified index of b to true.
+// It is like BitArray.Mark for an index of any integer type.
+func Mark[T Integer](b *BitArray, index T) {
+	b.Mark(toIndex(index))
+}
+
+// Unmark sets the bit at the specified index of b to false.
+// It is like BitArray.Unmark for an index of any integer type.
+func Unmark[T Integer](b *BitArray, index T) {
+	b.Unmark(toIndex(index))
+}
+
+// MarkE is like Mark but returns the error of BitArray.MarkE.
+func MarkE[T Integer](b *BitArray, index T) error {
+	return b.MarkE(toIndex(index))
+}
+
+// UnmarkE is like Unmark but returns the error of BitArray.UnmarkE.
+func UnmarkE[T Integer](b *BitArray, index T) error {
+	return b.UnmarkE(toIndex(index))
+}
+
+// toIndex converts i to int64. Unsigned values that do not fit into int64
+// are mapped to math.MaxInt64, so they are treated as out of range instead of
+// wrapping around to a negative index.
+func toIndex[T Integer](i T) int64 {
+	if n := int64(i); n >= 0 || i < 0 {
+		return n
+	}
+
+	return math.MaxInt64
+}
+
+// SetI is like Set for an index of type int.
+func (b *BitArray) SetI(index int, mark bool) (changed bool) {
+	return b.Set(int64(index), mark)
+}
+
+// GetI is like Get for an index of type int.
+func (b *BitArray) GetI(index int) bool {
+	return b.Get(int64(index))
+}
+
+// MarkI is like Mark for an index of type int.
+func (b *BitArray) MarkI(index int) {
+	b.Mark(int64(index))
+}
+
+// UnmarkI is like Unmark for an index of type int.
+func (b *BitArray) UnmarkI(index int) {
+	b.Unmark(int64(index))
+}
+
+// MarkFreeI is like MarkFree but returns the index as int. Arrays that may
+// hold more bits than int can represent should use MarkFree.
+func (b *BitArray) MarkFreeI() int {
+	return int(b.markFree())
+}
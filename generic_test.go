@@ -0,0 +1,54 @@
+package bitarray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type portID uint16
+
+func TestGenericIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(1_000)
+
+	Mark(b, uint32(10))
+	Mark(b, portID(20))
+	assert.True(Get(b, 10))
+	assert.True(Get(b, int8(20)))
+	assert.True(Set(b, uint(30), true))
+	assert.Equal(3, b.Len())
+
+	Unmark(b, uint64(10))
+	assert.False(Get(b, int16(10)))
+
+	assert.Equal(ErrOutOfRange, MarkE(b, uint64(math.MaxUint64)))
+	assert.Equal(ErrNegativeIndex, UnmarkE(b, int32(-1)))
+}
+
+func TestToIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(int64(5), toIndex(uint8(5)))
+	assert.Equal(int64(-5), toIndex(int8(-5)))
+	assert.Equal(int64(math.MaxInt64), toIndex(uint64(math.MaxUint64)))
+	assert.Equal(int64(math.MaxInt64), toIndex(uint64(math.MaxInt64)+1))
+}
+
+func TestBitArrayIntIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+
+	assert.Equal(0, b.MarkFreeI())
+	b.MarkI(10)
+	assert.True(b.GetI(10))
+	assert.True(b.SetI(20, true))
+	assert.Equal(3, b.Len())
+
+	b.UnmarkI(10)
+	assert.False(b.GetI(10))
+	assert.False(b.SetI(-1, true))
+}
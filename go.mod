@@ -1,8 +1,14 @@
 module github.com/aermolaev/bitarray
 
-go 1.13
+go 1.23
 
 require (
 	github.com/aermolaev/atomicvalue v0.0.0-20200523092320-94e1c15243b9
 	github.com/stretchr/testify v1.5.1
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
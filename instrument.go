@@ -0,0 +1,24 @@
+package bitarray
+
+import "time"
+
+// Instrumentation receives measurements of the allocations made by
+// MarkFree, e.g. to feed counters and latency histograms of a metrics system.
+// The number of set bits and the capacity are better observed as gauges
+// reading Len64 and Cap64. The methods are called synchronously after the
+// lock is released, so they should be cheap.
+type Instrumentation interface {
+	// Allocated is called after MarkFree allocated the bit at index, with
+	// the latency of the call, including the wait for the lock.
+	Allocated(index int64, latency time.Duration)
+
+	// Exhausted is called after MarkFree failed to find a free bit.
+	Exhausted(latency time.Duration)
+}
+
+// WithInstrumentation sets the receiver of the allocation measurements.
+func WithInstrumentation(inst Instrumentation) Option {
+	return func(c *config) {
+		c.inst = inst
+	}
+}
@@ -0,0 +1,35 @@
+package bitarray
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testInstrumentation struct {
+	allocated []int64
+	exhausted int
+}
+
+func (i *testInstrumentation) Allocated(index int64, latency time.Duration) {
+	i.allocated = append(i.allocated, index)
+}
+
+func (i *testInstrumentation) Exhausted(latency time.Duration) {
+	i.exhausted++
+}
+
+func TestBitArrayWithInstrumentation(t *testing.T) {
+	assert := assert.New(t)
+
+	inst := &testInstrumentation{}
+	b := NewBitArray(2, WithInstrumentation(inst))
+
+	b.MarkFree()
+	b.MarkFreeI()
+	b.MarkFree()
+
+	assert.Equal([]int64{0, 1}, inst.allocated)
+	assert.Equal(1, inst.exhausted)
+}
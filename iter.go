@@ -0,0 +1,217 @@
+package bitarray
+
+import (
+	"iter"
+	"math"
+)
+
+// ForEach calls fn for the index of every set bit in ascending order until
+// fn returns false. The array is read-locked during the iteration, so fn
+// must not modify it.
+func (b *BitArray) ForEach(fn func(index int64) bool) {
+	b.forEachInRange(0, math.MaxInt64, fn)
+}
+
+// ForEachInRange is like ForEach but visits only the set bits with indexes
+// in the half-open range [from, to).
+func (b *BitArray) ForEachInRange(from, to int64, fn func(index int64) bool) {
+	b.forEachInRange(b.in(from), b.in(to), fn)
+}
+
+func (b *BitArray) forEachInRange(from, to int64, fn func(index int64) bool) {
+	b.rlock()
+	defer b.runlock()
+
+	if from, to = b.clamp(from, to); from >= to {
+		return
+	}
+
+	for i, last := from/blockSize, (to-1)/blockSize; i <= last; i++ {
+		for block := b.blocks[i] & rangeMask(i, from, to); block != 0; block &= block - 1 {
+			if !fn(b.base + (i * blockSize) + block.ffs()) {
+				return
+			}
+		}
+	}
+}
+
+// ForEachDescending is like ForEach but visits the set bits in descending
+// order, the highest index first.
+func (b *BitArray) ForEachDescending(fn func(index int64) bool) {
+	b.rlock()
+	defer b.runlock()
+
+	for i := b.size - 1; i >= 0; i-- {
+		for block := b.blocks[i]; block != 0; {
+			j := block.fls()
+
+			if !fn(b.base + (i * blockSize) + j) {
+				return
+			}
+
+			block.unmark(j)
+		}
+	}
+}
+
+// ForEachClear calls fn for the index of every clear bit below the capacity
+// in ascending order until fn returns false. The array is read-locked during
+// the iteration, so fn must not modify it.
+func (b *BitArray) ForEachClear(fn func(index int64) bool) {
+	b.rlock()
+	defer b.runlock()
+
+	for i := int64(0); i < b.size; i++ {
+		for block := ^b.blocks[i] & b.validMask(i); block != 0; block &= block - 1 {
+			if !fn(b.base + (i * blockSize) + block.ffs()) {
+				return
+			}
+		}
+	}
+}
+
+// ForEachWord calls fn for every 64-bit word of the array with its raw bits,
+// bit i of the word holding index wordIndex*64+i, until fn returns false.
+// Bits beyond the capacity are always zero. The array is read-locked during
+// the iteration, so fn must not modify it.
+func (b *BitArray) ForEachWord(fn func(wordIndex int64, word uint64) bool) {
+	b.rlock()
+	defer b.runlock()
+
+	for k, n := int64(0), b.words(); k < n; k++ {
+		if !fn(k, b.word(k)) {
+			return
+		}
+	}
+}
+
+// SetBits returns an iterator over the indexes of the set bits in ascending
+// order. The array is read-locked while the iteration is in progress, so the
+// loop body must not modify it.
+func (b *BitArray) SetBits() iter.Seq[int64] {
+	return b.ForEach
+}
+
+// SetBitsDesc is like SetBits but iterates in descending order.
+func (b *BitArray) SetBitsDesc() iter.Seq[int64] {
+	return b.ForEachDescending
+}
+
+// ClearBits returns an iterator over the indexes of the clear bits below the
+// capacity in ascending order. The loop body must not modify the array.
+func (b *BitArray) ClearBits() iter.Seq[int64] {
+	return b.ForEachClear
+}
+
+// nextSet returns the index of the first set bit at or after from, or the
+// capacity if there is none. Callers hold the read lock.
+func (b *BitArray) nextSet(from int64) int64 {
+	return b.next(from, false)
+}
+
+// nextClear returns the index of the first clear bit at or after from, or
+// the capacity if there is none. Callers hold the read lock.
+func (b *BitArray) nextClear(from int64) int64 {
+	return b.next(from, true)
+}
+
+func (b *BitArray) next(from int64, clear bool) int64 {
+	capacity := b.capacity.Get64()
+
+	if from < 0 {
+		from = 0
+	}
+
+	for i := from / blockSize; from < capacity; i++ {
+		block := b.blocks[i]
+		if clear {
+			block = ^block
+		}
+
+		if block &= rangeMask(i, from, capacity); block != 0 {
+			return (i * blockSize) + block.ffs()
+		}
+
+		from = (i + 1) * blockSize
+	}
+
+	return capacity
+}
+
+// forEachRun calls fn for every maximal run [start, end) of set bits in
+// ascending order until fn returns false. Callers hold the read lock.
+func (b *BitArray) forEachRun(fn func(start, end int64) bool) {
+	capacity := b.capacity.Get64()
+
+	for start := b.nextSet(0); start < capacity; {
+		end := b.nextClear(start)
+
+		if !fn(start, end) {
+			return
+		}
+
+		start = b.nextSet(end)
+	}
+}
+
+// countRange returns the number of set bits in the half-open range
+// [from, to). Callers hold the read lock.
+func (b *BitArray) countRange(from, to int64) int64 {
+	if from, to = b.clamp(from, to); from >= to {
+		return 0
+	}
+
+	if b.counts != nil && to-from >= 2*superBlockSize {
+		return b.cachedRange(from, to)
+	}
+
+	return b.scanRange(from, to)
+}
+
+// scanRange counts the set bits of [from, to) from the blocks. Callers hold
+// the read lock.
+func (b *BitArray) scanRange(from, to int64) (n int64) {
+	if from >= to {
+		return
+	}
+
+	first, last := from/blockSize, (to-1)/blockSize
+	n = (b.blocks[first] & rangeMask(first, from, to)).popcount()
+
+	if last > first {
+		n += countBlocks(b.blocks[first+1 : last])
+		n += (b.blocks[last] & rangeMask(last, from, to)).popcount()
+	}
+
+	return
+}
+
+// clamp limits the half-open range [from, to) to the capacity.
+func (b *BitArray) clamp(from, to int64) (int64, int64) {
+	if from < 0 {
+		from = 0
+	}
+
+	if capacity := b.capacity.Get64(); to > capacity {
+		to = capacity
+	}
+
+	return from, to
+}
+
+// rangeMask returns the bits of block i that lie in the half-open range
+// [from, to).
+func rangeMask(i, from, to int64) BitBlock {
+	m := BitBlock(bitBlockFull)
+	start := i * blockSize
+
+	if from > start {
+		m &^= mask(from-start) - 1
+	}
+
+	if to < start+blockSize {
+		m &= mask(to-start) - 1
+	}
+
+	return m
+}
@@ -0,0 +1,118 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collect(each func(fn func(int64) bool)) (res []int64) {
+	each(func(i int64) bool {
+		res = append(res, i)
+		return true
+	})
+
+	return
+}
+
+func TestBitArrayForEach(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 0, 5, 63, 64, 200, 299)
+
+	assert.Equal([]int64{0, 5, 63, 64, 200, 299}, collect(b.ForEach))
+
+	var n int
+	b.ForEach(func(int64) bool {
+		n++
+		return n < 2
+	})
+	assert.Equal(2, n)
+
+	assert.Empty(collect(NewBitArray(100).ForEach))
+}
+
+func TestBitArrayForEachInRange(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 0, 5, 63, 64, 200, 299)
+
+	inRange := func(from, to int64) []int64 {
+		return collect(func(fn func(int64) bool) {
+			b.ForEachInRange(from, to, fn)
+		})
+	}
+
+	assert.Equal([]int64{5, 63}, inRange(1, 64))
+	assert.Equal([]int64{63, 64}, inRange(63, 65))
+	assert.Equal([]int64{0, 5, 63, 64, 200, 299}, inRange(-10, 1000))
+	assert.Empty(inRange(65, 200))
+	assert.Empty(inRange(100, 50))
+}
+
+func TestBitArrayForEachDescending(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 0, 5, 63, 64, 200, 299)
+
+	assert.Equal([]int64{299, 200, 64, 63, 5, 0}, collect(b.ForEachDescending))
+
+	var n int
+	b.ForEachDescending(func(int64) bool {
+		n++
+		return false
+	})
+	assert.Equal(1, n)
+}
+
+func TestBitArraySetBits(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 1, 70, 250)
+
+	var asc, desc []int64
+	for i := range b.SetBits() {
+		asc = append(asc, i)
+	}
+	for i := range b.SetBitsDesc() {
+		desc = append(desc, i)
+		if len(desc) == 2 {
+			break
+		}
+	}
+
+	assert.Equal([]int64{1, 70, 250}, asc)
+	assert.Equal([]int64{250, 70}, desc)
+}
+
+func TestBitArrayForEachClear(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArrayFull(70)
+	b.UnmarkAll(0, 63, 69)
+
+	assert.Equal([]int64{0, 63, 69}, collect(b.ForEachClear))
+	assert.Len(collect(NewBitArray(130).ForEachClear), 130)
+
+	var clear []int64
+	for i := range b.ClearBits() {
+		clear = append(clear, i)
+		break
+	}
+	assert.Equal([]int64{0}, clear)
+}
+
+func TestBitArrayForEachWord(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(130, 0, 1, 65, 129)
+
+	var words []uint64
+	b.ForEachWord(func(i int64, word uint64) bool {
+		assert.Equal(int64(len(words)), i)
+		words = append(words, word)
+		return true
+	})
+
+	assert.Equal([]uint64{3, 2, 2}, words)
+}
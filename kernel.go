@@ -0,0 +1,92 @@
+package bitarray
+
+import "math/bits"
+
+// The kernels below apply a boolean operation or count the set bits of
+// whole block slices. They work on the 64-bit words overlaying 64-bit
+// blocks, in the architecture-specific implementations (kernel_amd64.s,
+// kernel_arm64.s) where available; 32-bit blocks, which need not be aligned
+// to words, are processed block by block, and so are all the blocks in the
+// purego build, which cannot overlay words (see purego.go).
+
+// andBlocks sets dst to dst & src, up to the shorter of the slices.
+func andBlocks(dst, src []BitBlock) {
+	n, w := overlay(dst, src)
+	if w > 0 {
+		andWords(asWords(dst, w), asWords(src, w))
+	}
+
+	for i := w; i < n; i++ {
+		dst[i] &= src[i]
+	}
+}
+
+// orBlocks sets dst to dst | src, up to the shorter of the slices.
+func orBlocks(dst, src []BitBlock) {
+	n, w := overlay(dst, src)
+	if w > 0 {
+		orWords(asWords(dst, w), asWords(src, w))
+	}
+
+	for i := w; i < n; i++ {
+		dst[i] |= src[i]
+	}
+}
+
+// xorBlocks sets dst to dst ^ src, up to the shorter of the slices.
+func xorBlocks(dst, src []BitBlock) {
+	n, w := overlay(dst, src)
+	if w > 0 {
+		xorWords(asWords(dst, w), asWords(src, w))
+	}
+
+	for i := w; i < n; i++ {
+		dst[i] ^= src[i]
+	}
+}
+
+// countBlocks returns the number of set bits in blocks.
+func countBlocks(blocks []BitBlock) (n int64) {
+	_, w := overlay(blocks, blocks)
+	if w > 0 {
+		n = int64(countWords(asWords(blocks, w)))
+	}
+
+	for i := w; i < len(blocks); i++ {
+		n += blocks[i].popcount()
+	}
+
+	return
+}
+
+func andWordsGeneric(dst, src []uint64) {
+	src = src[:len(dst)]
+
+	for i := range dst {
+		dst[i] &= src[i]
+	}
+}
+
+func orWordsGeneric(dst, src []uint64) {
+	src = src[:len(dst)]
+
+	for i := range dst {
+		dst[i] |= src[i]
+	}
+}
+
+func xorWordsGeneric(dst, src []uint64) {
+	src = src[:len(dst)]
+
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+func countWordsGeneric(words []uint64) (n int) {
+	for _, w := range words {
+		n += bits.OnesCount64(w)
+	}
+
+	return
+}
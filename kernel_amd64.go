@@ -0,0 +1,85 @@
+//go:build !purego && !tinygo
+
+package bitarray
+
+// The AVX2 kernels process 16 words per iteration; POPCNT is used for
+// counting. Both are detected at startup, falling back to the generic
+// kernels on older CPUs.
+var (
+	hasAVX2   bool
+	hasPOPCNT bool
+)
+
+func init() {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 1 {
+		return
+	}
+
+	_, _, ecx1, _ := cpuid(1, 0)
+	hasPOPCNT = ecx1&(1<<23) != 0
+
+	const osxsave, avx = 1 << 27, 1 << 28
+
+	if maxID < 7 || ecx1&osxsave == 0 || ecx1&avx == 0 {
+		return
+	}
+
+	// the OS must save the XMM and YMM registers
+	if eax, _ := xgetbv(); eax&6 != 6 {
+		return
+	}
+
+	_, ebx7, _, _ := cpuid(7, 0)
+	hasAVX2 = ebx7&(1<<5) != 0
+}
+
+func andWords(dst, src []uint64) {
+	if hasAVX2 {
+		andAVX2(dst, src)
+	} else {
+		andWordsGeneric(dst, src)
+	}
+}
+
+func orWords(dst, src []uint64) {
+	if hasAVX2 {
+		orAVX2(dst, src)
+	} else {
+		orWordsGeneric(dst, src)
+	}
+}
+
+func xorWords(dst, src []uint64) {
+	if hasAVX2 {
+		xorAVX2(dst, src)
+	} else {
+		xorWordsGeneric(dst, src)
+	}
+}
+
+func countWords(words []uint64) int {
+	if hasPOPCNT {
+		return countPOPCNT(words)
+	}
+
+	return countWordsGeneric(words)
+}
+
+// The assembly kernels require len(src) >= len(dst).
+
+//go:noescape
+func andAVX2(dst, src []uint64)
+
+//go:noescape
+func orAVX2(dst, src []uint64)
+
+//go:noescape
+func xorAVX2(dst, src []uint64)
+
+//go:noescape
+func countPOPCNT(words []uint64) int
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func xgetbv() (eax, edx uint32)
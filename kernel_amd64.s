@@ -0,0 +1,182 @@
+//go:build !purego && !tinygo
+
+#include "textflag.h"
+
+// func andAVX2(dst, src []uint64)
+TEXT ·andAVX2(SB), NOSPLIT, $0-48
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ src_base+24(FP), SI
+
+andLoop:
+	CMPQ CX, $16
+	JB   andTail
+	VMOVDQU (DI), Y0
+	VMOVDQU 32(DI), Y1
+	VMOVDQU 64(DI), Y2
+	VMOVDQU 96(DI), Y3
+	VPAND (SI), Y0, Y0
+	VPAND 32(SI), Y1, Y1
+	VPAND 64(SI), Y2, Y2
+	VPAND 96(SI), Y3, Y3
+	VMOVDQU Y0, (DI)
+	VMOVDQU Y1, 32(DI)
+	VMOVDQU Y2, 64(DI)
+	VMOVDQU Y3, 96(DI)
+	ADDQ $128, DI
+	ADDQ $128, SI
+	SUBQ $16, CX
+	JMP  andLoop
+
+andTail:
+	TESTQ CX, CX
+	JZ    andDone
+	MOVQ  (SI), AX
+	ANDQ  AX, (DI)
+	ADDQ  $8, DI
+	ADDQ  $8, SI
+	DECQ  CX
+	JMP   andTail
+
+andDone:
+	VZEROUPPER
+	RET
+
+// func orAVX2(dst, src []uint64)
+TEXT ·orAVX2(SB), NOSPLIT, $0-48
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ src_base+24(FP), SI
+
+orLoop:
+	CMPQ CX, $16
+	JB   orTail
+	VMOVDQU (DI), Y0
+	VMOVDQU 32(DI), Y1
+	VMOVDQU 64(DI), Y2
+	VMOVDQU 96(DI), Y3
+	VPOR (SI), Y0, Y0
+	VPOR 32(SI), Y1, Y1
+	VPOR 64(SI), Y2, Y2
+	VPOR 96(SI), Y3, Y3
+	VMOVDQU Y0, (DI)
+	VMOVDQU Y1, 32(DI)
+	VMOVDQU Y2, 64(DI)
+	VMOVDQU Y3, 96(DI)
+	ADDQ $128, DI
+	ADDQ $128, SI
+	SUBQ $16, CX
+	JMP  orLoop
+
+orTail:
+	TESTQ CX, CX
+	JZ    orDone
+	MOVQ  (SI), AX
+	ORQ  AX, (DI)
+	ADDQ  $8, DI
+	ADDQ  $8, SI
+	DECQ  CX
+	JMP   orTail
+
+orDone:
+	VZEROUPPER
+	RET
+
+// func xorAVX2(dst, src []uint64)
+TEXT ·xorAVX2(SB), NOSPLIT, $0-48
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), CX
+	MOVQ src_base+24(FP), SI
+
+xorLoop:
+	CMPQ CX, $16
+	JB   xorTail
+	VMOVDQU (DI), Y0
+	VMOVDQU 32(DI), Y1
+	VMOVDQU 64(DI), Y2
+	VMOVDQU 96(DI), Y3
+	VPXOR (SI), Y0, Y0
+	VPXOR 32(SI), Y1, Y1
+	VPXOR 64(SI), Y2, Y2
+	VPXOR 96(SI), Y3, Y3
+	VMOVDQU Y0, (DI)
+	VMOVDQU Y1, 32(DI)
+	VMOVDQU Y2, 64(DI)
+	VMOVDQU Y3, 96(DI)
+	ADDQ $128, DI
+	ADDQ $128, SI
+	SUBQ $16, CX
+	JMP  xorLoop
+
+xorTail:
+	TESTQ CX, CX
+	JZ    xorDone
+	MOVQ  (SI), AX
+	XORQ  AX, (DI)
+	ADDQ  $8, DI
+	ADDQ  $8, SI
+	DECQ  CX
+	JMP   xorTail
+
+xorDone:
+	VZEROUPPER
+	RET
+
+// func countPOPCNT(words []uint64) int
+TEXT ·countPOPCNT(SB), NOSPLIT, $0-32
+	MOVQ words_base+0(FP), SI
+	MOVQ words_len+8(FP), CX
+	XORQ AX, AX
+	XORQ BX, BX
+	XORQ DX, DX
+	XORQ R8, R8
+
+countLoop:
+	CMPQ    CX, $4
+	JB      countTail
+	POPCNTQ (SI), R9
+	POPCNTQ 8(SI), R10
+	POPCNTQ 16(SI), R11
+	POPCNTQ 24(SI), R12
+	ADDQ    R9, AX
+	ADDQ    R10, BX
+	ADDQ    R11, DX
+	ADDQ    R12, R8
+	ADDQ    $32, SI
+	SUBQ    $4, CX
+	JMP     countLoop
+
+countTail:
+	TESTQ   CX, CX
+	JZ      countDone
+	POPCNTQ (SI), R9
+	ADDQ    R9, AX
+	ADDQ    $8, SI
+	DECQ    CX
+	JMP     countTail
+
+countDone:
+	ADDQ BX, AX
+	ADDQ DX, AX
+	ADDQ R8, AX
+	MOVQ AX, ret+24(FP)
+	RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
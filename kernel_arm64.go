@@ -0,0 +1,36 @@
+//go:build !purego && !tinygo
+
+package bitarray
+
+// NEON is part of the base ARMv8 architecture, so the kernels need no
+// feature detection. They process 8 words per iteration.
+
+func andWords(dst, src []uint64) {
+	andNEON(dst, src)
+}
+
+func orWords(dst, src []uint64) {
+	orNEON(dst, src)
+}
+
+func xorWords(dst, src []uint64) {
+	xorNEON(dst, src)
+}
+
+func countWords(words []uint64) int {
+	return countNEON(words)
+}
+
+// The assembly kernels require len(src) >= len(dst).
+
+//go:noescape
+func andNEON(dst, src []uint64)
+
+//go:noescape
+func orNEON(dst, src []uint64)
+
+//go:noescape
+func xorNEON(dst, src []uint64)
+
+//go:noescape
+func countNEON(words []uint64) int
@@ -0,0 +1,134 @@
+//go:build !purego && !tinygo
+
+#include "textflag.h"
+
+// func andNEON(dst, src []uint64)
+TEXT ·andNEON(SB), NOSPLIT, $0-48
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R2
+	MOVD src_base+24(FP), R1
+
+andLoop:
+	CMP  $8, R2
+	BLT  andTail
+	VLD1   (R0), [V0.B16, V1.B16, V2.B16, V3.B16]
+	VLD1.P 64(R1), [V4.B16, V5.B16, V6.B16, V7.B16]
+	VAND   V4.B16, V0.B16, V0.B16
+	VAND   V5.B16, V1.B16, V1.B16
+	VAND   V6.B16, V2.B16, V2.B16
+	VAND   V7.B16, V3.B16, V3.B16
+	VST1.P [V0.B16, V1.B16, V2.B16, V3.B16], 64(R0)
+	SUB  $8, R2
+	B    andLoop
+
+andTail:
+	CBZ    R2, andDone
+	MOVD   (R0), R3
+	MOVD.P 8(R1), R4
+	AND    R4, R3
+	MOVD.P R3, 8(R0)
+	SUB    $1, R2
+	B      andTail
+
+andDone:
+	RET
+
+// func orNEON(dst, src []uint64)
+TEXT ·orNEON(SB), NOSPLIT, $0-48
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R2
+	MOVD src_base+24(FP), R1
+
+orLoop:
+	CMP  $8, R2
+	BLT  orTail
+	VLD1   (R0), [V0.B16, V1.B16, V2.B16, V3.B16]
+	VLD1.P 64(R1), [V4.B16, V5.B16, V6.B16, V7.B16]
+	VORR   V4.B16, V0.B16, V0.B16
+	VORR   V5.B16, V1.B16, V1.B16
+	VORR   V6.B16, V2.B16, V2.B16
+	VORR   V7.B16, V3.B16, V3.B16
+	VST1.P [V0.B16, V1.B16, V2.B16, V3.B16], 64(R0)
+	SUB  $8, R2
+	B    orLoop
+
+orTail:
+	CBZ    R2, orDone
+	MOVD   (R0), R3
+	MOVD.P 8(R1), R4
+	ORR    R4, R3
+	MOVD.P R3, 8(R0)
+	SUB    $1, R2
+	B      orTail
+
+orDone:
+	RET
+
+// func xorNEON(dst, src []uint64)
+TEXT ·xorNEON(SB), NOSPLIT, $0-48
+	MOVD dst_base+0(FP), R0
+	MOVD dst_len+8(FP), R2
+	MOVD src_base+24(FP), R1
+
+xorLoop:
+	CMP  $8, R2
+	BLT  xorTail
+	VLD1   (R0), [V0.B16, V1.B16, V2.B16, V3.B16]
+	VLD1.P 64(R1), [V4.B16, V5.B16, V6.B16, V7.B16]
+	VEOR   V4.B16, V0.B16, V0.B16
+	VEOR   V5.B16, V1.B16, V1.B16
+	VEOR   V6.B16, V2.B16, V2.B16
+	VEOR   V7.B16, V3.B16, V3.B16
+	VST1.P [V0.B16, V1.B16, V2.B16, V3.B16], 64(R0)
+	SUB  $8, R2
+	B    xorLoop
+
+xorTail:
+	CBZ    R2, xorDone
+	MOVD   (R0), R3
+	MOVD.P 8(R1), R4
+	EOR    R4, R3
+	MOVD.P R3, 8(R0)
+	SUB    $1, R2
+	B      xorTail
+
+xorDone:
+	RET
+
+// func countNEON(words []uint64) int
+TEXT ·countNEON(SB), NOSPLIT, $0-32
+	MOVD words_base+0(FP), R0
+	MOVD words_len+8(FP), R2
+	MOVD ZR, R5
+
+countLoop:
+	CMP     $8, R2
+	BLT     countTail
+	VLD1.P  64(R0), [V0.B16, V1.B16, V2.B16, V3.B16]
+	VCNT    V0.B16, V0.B16
+	VCNT    V1.B16, V1.B16
+	VCNT    V2.B16, V2.B16
+	VCNT    V3.B16, V3.B16
+	VADD    V1.B16, V0.B16, V0.B16
+	VADD    V3.B16, V2.B16, V2.B16
+	VADD    V2.B16, V0.B16, V0.B16
+	VUADDLV V0.B16, V0
+	VMOV    V0.H[0], R3
+	ADD     R3, R5
+	SUB     $8, R2
+	B       countLoop
+
+countTail:
+	CBZ     R2, countDone
+	MOVD.P  8(R0), R3
+	FMOVD   R3, F0
+	VCNT    V0.B8, V0.B8
+	VUADDLV V0.B8, V0
+	VMOV    V0.H[0], R3
+	ADD     R3, R5
+	SUB     $1, R2
+	B       countTail
+
+countDone:
+	MOVD R5, ret+24(FP)
+	RET
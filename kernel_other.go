@@ -0,0 +1,19 @@
+//go:build purego || tinygo || !(amd64 || arm64)
+
+package bitarray
+
+func andWords(dst, src []uint64) {
+	andWordsGeneric(dst, src)
+}
+
+func orWords(dst, src []uint64) {
+	orWordsGeneric(dst, src)
+}
+
+func xorWords(dst, src []uint64) {
+	xorWordsGeneric(dst, src)
+}
+
+func countWords(words []uint64) int {
+	return countWordsGeneric(words)
+}
@@ -0,0 +1,71 @@
+package bitarray
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomBlocks(r *rand.Rand, n int) []BitBlock {
+	blocks := make([]BitBlock, n)
+	for i := range blocks {
+		blocks[i] = BitBlock(r.Uint64())
+	}
+
+	return blocks
+}
+
+func TestKernels(t *testing.T) {
+	assert := assert.New(t)
+
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for _, n := range []int{0, 1, 3, 7, 8, 15, 16, 17, 33, 100, 1000} {
+		x, y := randomBlocks(r, n), randomBlocks(r, n+2)
+
+		for _, tc := range []struct {
+			kernel func(dst, src []BitBlock)
+			op     func(x, y BitBlock) BitBlock
+		}{
+			{andBlocks, func(x, y BitBlock) BitBlock { return x & y }},
+			{orBlocks, func(x, y BitBlock) BitBlock { return x | y }},
+			{xorBlocks, func(x, y BitBlock) BitBlock { return x ^ y }},
+		} {
+			got := slices.Clone(x)
+			tc.kernel(got, y)
+
+			for i := range x {
+				assert.Equal(tc.op(x[i], y[i]), got[i], "n=%d i=%d", n, i)
+			}
+		}
+
+		var count int64
+		for _, block := range x {
+			count += block.popcount()
+		}
+
+		assert.Equal(count, countBlocks(x), "n=%d", n)
+	}
+}
+
+func BenchmarkAnd(b *testing.B) {
+	x, y := NewBitArrayFull(1<<26), NewBitArrayFull(1<<26)
+
+	b.SetBytes(1 << 23)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		x.And(y)
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	x := NewBitArrayFull(1 << 26)
+
+	b.SetBytes(1 << 23)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		x.recount()
+	}
+}
@@ -0,0 +1,124 @@
+package bitarray
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHeldStack is the maximum number of frames reported for an allocation.
+// Twice as many are captured, since the frames of the package are dropped.
+const maxHeldStack = 16
+
+// Held describes a bit allocated by MarkFree that is still set.
+type Held struct {
+	Index  int64     // index of the bit
+	Since  time.Time // time of the allocation
+	Caller string    // function and position of the caller of the package
+	Stack  string    // call stack of the allocation, one frame per line
+}
+
+type heldRecord struct {
+	since time.Time
+	pcs   [2 * maxHeldStack]uintptr
+	n     int
+}
+
+// pkgPrefix is the prefix of the names of the functions of the package.
+var pkgPrefix = funcPackage()
+
+func funcPackage() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+
+	return name[:strings.LastIndexByte(name, '.')+1]
+}
+
+// internal reports whether the frame is of a function of the package other
+// than a test.
+func internal(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, pkgPrefix) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// WithLeakTracking makes the array record the time and the call stack of
+// every allocation made by MarkFree until the bit is cleared, which lets
+// HeldLongerThan find bits that are allocated and never released. It is meant
+// for debugging, since every allocation captures a stack trace.
+func WithLeakTracking() Option {
+	return func(c *config) {
+		c.leakTracking = true
+	}
+}
+
+// HeldLongerThan returns the bits allocated by MarkFree more than d ago that
+// are still set, ordered by index. It returns nil unless the array was created
+// with WithLeakTracking.
+func (b *BitArray) HeldLongerThan(d time.Duration) []Held {
+	b.lock()
+	defer b.unlock()
+
+	var res []Held
+	now := time.Now()
+
+	for index, r := range b.held {
+		if set, _ := b.get(index); !set {
+			delete(b.held, index) // cleared by a bulk operation
+			continue
+		}
+
+		if now.Sub(r.since) > d {
+			res = append(res, r.held(b.out(index)))
+		}
+	}
+
+	slices.SortFunc(res, func(x, y Held) int {
+		return cmp.Compare(x.Index, y.Index)
+	})
+
+	return res
+}
+
+// track records the allocation of the bit at index. The frames of the
+// package leading to it, which depend on the allocating method, are dropped
+// by held. Callers hold the write lock.
+func (b *BitArray) track(index int64) {
+	r := heldRecord{since: time.Now()}
+
+	r.n = runtime.Callers(2, r.pcs[:]) // skip runtime.Callers and track
+	b.held[index] = r
+}
+
+func (r *heldRecord) held(index int64) Held {
+	h := Held{Index: index, Since: r.since}
+
+	var sb strings.Builder
+	frames := runtime.CallersFrames(r.pcs[:r.n])
+
+	skip := true
+
+	for n, more := 0, r.n > 0; more && n < maxHeldStack; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+
+		if skip = skip && internal(frame); skip {
+			continue
+		}
+
+		n++
+
+		line := frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line)
+		if h.Caller == "" {
+			h.Caller = line
+		}
+
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+
+	h.Stack = sb.String()
+
+	return h
+}
@@ -0,0 +1,69 @@
+package bitarray
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayHeldLongerThan(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithLeakTracking())
+
+	b.MarkFree()
+	b.MarkFreeI()
+	b.MarkFree()
+	b.Unmark(1)
+
+	held := b.HeldLongerThan(0)
+	assert.Len(held, 2)
+	assert.Equal(int64(0), held[0].Index)
+	assert.Equal(int64(2), held[1].Index)
+	assert.Contains(held[0].Caller, "TestBitArrayHeldLongerThan")
+	assert.Contains(held[0].Stack, "leak_test.go")
+
+	assert.Empty(b.HeldLongerThan(time.Hour))
+
+	b.MapBlocks(func(uint64) uint64 { return 1 << 2 })
+	held = b.HeldLongerThan(0)
+	assert.Len(held, 1)
+	assert.Equal(int64(2), held[0].Index)
+
+	b.Reset()
+	assert.Empty(b.HeldLongerThan(0))
+}
+
+func TestBitArrayHeldLongerThanCaller(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithLeakTracking())
+	_, err := b.AcquireN(context.Background(), 2)
+	assert.NoError(err)
+
+	a := NewAllocator[listenPort](100, WithLeakTracking())
+	_, err = a.Acquire()
+	assert.NoError(err)
+	_, err = a.AcquireN(context.Background(), 1)
+	assert.NoError(err)
+
+	for _, b := range []*BitArray{b, a.BitArray()} {
+		held := b.HeldLongerThan(0)
+		assert.Len(held, 2)
+
+		for _, h := range held {
+			assert.Contains(h.Caller, "TestBitArrayHeldLongerThanCaller")
+			assert.Contains(h.Caller, "leak_test.go")
+			assert.NotContains(h.Stack, "bitarray.(*")
+		}
+	}
+}
+
+func TestBitArrayHeldLongerThanDisabled(t *testing.T) {
+	b := NewBitArray(100)
+	b.MarkFree()
+
+	assert.Nil(t, b.HeldLongerThan(0))
+}
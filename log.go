@@ -0,0 +1,36 @@
+package bitarray
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger makes the array trace Mark, Unmark, MarkFree, Reset and Grow
+// operations to logger at the debug level, with the index and the resulting
+// number of set bits. Without a logger, or when the debug level is disabled,
+// tracing costs a single check per operation.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// traceSet logs a change of the bit at index. Callers hold the write lock.
+func (b *BitArray) traceSet(index int64, mark, changed bool) {
+	msg := "bitarray: unmark"
+	if mark == bitBlockMark {
+		msg = "bitarray: mark"
+	}
+
+	b.trace(msg, slog.Int64("index", index), slog.Bool("changed", changed))
+}
+
+// trace logs msg with attrs and the current number of set bits.
+func (b *BitArray) trace(msg string, attrs ...slog.Attr) {
+	ctx := context.Background()
+
+	if b.logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, slog.Int64("count", b.count.Get64()))
+		b.logger.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
+	}
+}
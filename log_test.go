@@ -0,0 +1,51 @@
+package bitarray
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayWithLogger(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	b := NewBitArray(10, WithLogger(logger), WithAutoGrow())
+	b.Mark(3)
+	b.Unmark(3)
+	b.MarkFree()
+	b.Mark(100)
+	b.Reset()
+
+	assert.Equal([]string{
+		`level=DEBUG msg="bitarray: mark" index=3 changed=true count=1`,
+		`level=DEBUG msg="bitarray: unmark" index=3 changed=true count=0`,
+		`level=DEBUG msg="bitarray: mark" index=0 changed=true count=1`,
+		`level=DEBUG msg="bitarray: grow" capacity=101 count=1`,
+		`level=DEBUG msg="bitarray: mark" index=100 changed=true count=2`,
+		`level=DEBUG msg="bitarray: reset" count=0`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+}
+
+func TestBitArrayWithLoggerDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	b := NewBitArray(10, WithLogger(logger))
+	b.Mark(3)
+
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,213 @@
+//go:build linux || darwin || freebsd
+
+package bitarray
+
+import (
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"math/bits"
+	"os"
+	"syscall"
+)
+
+// Mapped is a read-only bit array memory-mapped from a file in the binary
+// encoding of MarshalBinary, e.g. a checkpoint of a Durable, so a bitmap of
+// gigabytes can be queried without loading it into the heap. The pages are
+// read from the file on demand by the kernel.
+//
+// Mapped only answers queries, which are safe for concurrent use, and the
+// file must not be modified while it is mapped. The methods must not be
+// called after Close.
+type Mapped struct {
+	data     []byte
+	words    []byte
+	capacity int64
+	count    int64
+}
+
+// OpenReadOnly maps the file at path and validates its header and checksum,
+// which reads the file once. The sparse encoding of MarshalSparse cannot be
+// mapped.
+func OpenReadOnly(path string) (*Mapped, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m, err := mapReadOnly(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+func mapReadOnly(file *os.File) (*Mapped, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+	if int64(int(size)) != size {
+		return nil, fmt.Errorf("size %d exceeds the address space", size)
+	}
+
+	if size < int64(binaryHeaderLen+checksumLen) {
+		return nil, fmt.Errorf("%w: %d bytes is too short", ErrFormat, size)
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMapped(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func newMapped(data []byte) (*Mapped, error) {
+	version, capacity, payload, err := decodeBinary(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != binaryVersion {
+		return nil, fmt.Errorf("%w: version %d cannot be mapped", ErrFormat, version)
+	}
+
+	if err := checkCapacity(capacity); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	if int64(len(payload)) != wordCount(capacity)*8 {
+		return nil, fmt.Errorf("%w: %d bytes of words for capacity %d", ErrFormat, len(payload), capacity)
+	}
+
+	m := &Mapped{data: data, words: payload, capacity: capacity}
+
+	n := wordCount(capacity)
+	if n > 0 && capacity%wordSize != 0 && m.word(n-1)>>(capacity%wordSize) != 0 {
+		return nil, fmt.Errorf("%w: bits set beyond capacity %d", ErrFormat, capacity)
+	}
+
+	for k := int64(0); k < n; k++ {
+		m.count += int64(bits.OnesCount64(m.word(k)))
+	}
+
+	return m, nil
+}
+
+// Close unmaps the file.
+func (m *Mapped) Close() error {
+	err := syscall.Munmap(m.data)
+	m.data, m.words = nil, nil
+
+	return err
+}
+
+// word returns the k-th 64-bit word.
+func (m *Mapped) word(k int64) uint64 {
+	return binary.LittleEndian.Uint64(m.words[k*8:])
+}
+
+// Get returns the value of the bit with the specified index. Indexes out of
+// range are reported as false.
+func (m *Mapped) Get(index int64) bool {
+	if index < 0 || index >= m.capacity {
+		return false
+	}
+
+	return m.word(index/wordSize)&(1<<(index%wordSize)) != 0
+}
+
+// Len64 returns the number of set bits.
+func (m *Mapped) Len64() int64 {
+	return m.count
+}
+
+// Len returns the number of set bits, saturating at math.MaxInt.
+func (m *Mapped) Len() int {
+	return toInt(m.count)
+}
+
+// Cap64 returns the capacity.
+func (m *Mapped) Cap64() int64 {
+	return m.capacity
+}
+
+// Cap returns the capacity, saturating at math.MaxInt.
+func (m *Mapped) Cap() int {
+	return toInt(m.capacity)
+}
+
+// CountRange returns the number of set bits in the half-open range
+// [from, to), clamped to the capacity.
+func (m *Mapped) CountRange(from, to int64) (n int64) {
+	from, to = max(from, 0), min(to, m.capacity)
+
+	for from < to {
+		k := from / wordSize
+		w := m.word(k) >> (from % wordSize)
+
+		if end := (k + 1) * wordSize; to < end {
+			w &= 1<<(to-from) - 1
+			from = to
+		} else {
+			from = end
+		}
+
+		n += int64(bits.OnesCount64(w))
+	}
+
+	return
+}
+
+// First returns the lowest index of a set bit, or BitBlockNotFound if no bit
+// is set.
+func (m *Mapped) First() int64 {
+	for k, n := int64(0), wordCount(m.capacity); k < n; k++ {
+		if w := m.word(k); w != 0 {
+			return k*wordSize + int64(bits.TrailingZeros64(w))
+		}
+	}
+
+	return BitBlockNotFound
+}
+
+// Last returns the highest index of a set bit, or BitBlockNotFound if no bit
+// is set.
+func (m *Mapped) Last() int64 {
+	for k := wordCount(m.capacity) - 1; k >= 0; k-- {
+		if w := m.word(k); w != 0 {
+			return k*wordSize + int64(bits.Len64(w)) - 1
+		}
+	}
+
+	return BitBlockNotFound
+}
+
+// ForEach calls fn for the index of every set bit in ascending order until
+// fn returns false.
+func (m *Mapped) ForEach(fn func(index int64) bool) {
+	for k, n := int64(0), wordCount(m.capacity); k < n; k++ {
+		for w := m.word(k); w != 0; w &= w - 1 {
+			if !fn(k*wordSize + int64(bits.TrailingZeros64(w))) {
+				return
+			}
+		}
+	}
+}
+
+// SetBits returns an iterator over the indexes of the set bits in ascending
+// order.
+func (m *Mapped) SetBits() iter.Seq[int64] {
+	return m.ForEach
+}
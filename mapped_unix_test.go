@@ -0,0 +1,75 @@
+//go:build linux || darwin || freebsd
+
+package bitarray
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenReadOnly(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+
+	for _, capacity := range []int64{0, 1, 64, 100, 1000} {
+		b := NewBitArray(capacity)
+		for i := int64(0); i < capacity; i += 7 {
+			b.Mark(i)
+		}
+
+		data, _ := b.MarshalBinary()
+		path := filepath.Join(dir, "bits")
+		assert.NoError(os.WriteFile(path, data, 0o644))
+
+		m, err := OpenReadOnly(path)
+		assert.NoError(err)
+
+		assert.Equal(b.Cap(), m.Cap())
+		assert.Equal(b.Len64(), m.Len64())
+		assert.Equal(b.First(), m.First())
+		assert.Equal(b.Last(), m.Last())
+		assert.Equal(slices.Collect(b.SetBits()), slices.Collect(m.SetBits()))
+
+		for _, r := range [][2]int64{{0, capacity}, {3, 70}, {64, 65}, {-5, capacity + 5}, {10, 5}} {
+			assert.Equal(b.CountRange(r[0], r[1]), m.CountRange(r[0], r[1]))
+		}
+
+		for i := int64(-1); i <= capacity; i++ {
+			assert.Equal(b.Get(i), m.Get(i))
+		}
+
+		assert.NoError(m.Close())
+	}
+}
+
+func TestOpenReadOnlyInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bits")
+
+	_, err := OpenReadOnly(path)
+	assert.True(errors.Is(err, os.ErrNotExist))
+
+	data, _ := newMarked(100, 1, 99).MarshalBinary()
+
+	data[len(data)-6] ^= 1
+	assert.NoError(os.WriteFile(path, data, 0o644))
+	_, err = OpenReadOnly(path)
+	assert.True(errors.Is(err, ErrFormat))
+
+	assert.NoError(os.WriteFile(path, data[:10], 0o644))
+	_, err = OpenReadOnly(path)
+	assert.True(errors.Is(err, ErrFormat))
+
+	sparse, _ := newMarked(100, 1, 99).MarshalSparse()
+	assert.NoError(os.WriteFile(path, sparse, 0o644))
+	_, err = OpenReadOnly(path)
+	assert.True(errors.Is(err, ErrFormat))
+}
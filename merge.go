@@ -0,0 +1,189 @@
+package bitarray
+
+import (
+	"cmp"
+	"slices"
+)
+
+// MergePolicy defines how Merge combines two replicas of a BitArray.
+type MergePolicy int
+
+const (
+	// MergeUnion keeps the bits that are set in either replica.
+	MergeUnion MergePolicy = iota
+
+	// MergeIntersection keeps only the bits that are set in both replicas.
+	MergeIntersection
+
+	// MergeLastWriter treats the other replica as the latest writer and
+	// adopts its bits for every index it covers.
+	MergeLastWriter
+
+	// MergeExclusive keeps the bits that are set in either replica, like
+	// MergeUnion, but expects no bit to be set in both, e.g. when allocators
+	// that were split rejoin. The bits set in both are conflicts, which
+	// MergeFrom reports.
+	MergeExclusive
+)
+
+// MergeReport describes the changes a MergeFrom made to b.
+type MergeReport struct {
+	Conflicts []int64 // bits set in both replicas under MergeExclusive
+	Added     int64   // number of bits of b set by the merge
+	Removed   int64   // number of bits of b cleared by the merge
+}
+
+// Merge combines other into b according to policy and reconciles the number
+// of set bits, so two replicas that were changed independently can converge.
+// Bits of other beyond the capacity of b are dropped. When other is smaller,
+// MergeIntersection treats its missing bits as false and MergeLastWriter
+// leaves the uncovered bits of b untouched. Under CapacityError arrays of
+// different capacities are not merged.
+func (b *BitArray) Merge(other *BitArray, policy MergePolicy) {
+	b.MergeE(other, policy)
+}
+
+// MergeE is like Merge but returns ErrCapacityMismatch if the capacities
+// differ under CapacityError.
+func (b *BitArray) MergeE(other *BitArray, policy MergePolicy) error {
+	return b.apply(other, mergeOp(policy))
+}
+
+// MergeFrom merges other into b like MergeE and reports the changes,
+// including the conflicting indexes in ascending order under
+// MergeExclusive. Merging an array into itself changes nothing.
+func (b *BitArray) MergeFrom(other *BitArray, policy MergePolicy) (report MergeReport, err error) {
+	op := mergeOp(policy)
+
+	if b == other {
+		return
+	}
+
+	unlock := lockPair(b, other)
+	defer unlock()
+
+	if err = b.matchCapacity(other); err != nil {
+		return
+	}
+
+	for i := int64(0); i < b.size; i++ {
+		old, o := b.blocks[i], other.blockAt(i)&b.validMask(i)
+
+		var block BitBlock
+
+		switch op {
+		case opOr:
+			block = old | o
+
+		case opAnd:
+			block = old & o
+
+		case opCopy:
+			m := capacityMask(i, other.capacity.Get64())
+			block = o&m | old&^m
+		}
+
+		if policy == MergeExclusive {
+			for c := old & o; c != 0; c &= c - 1 {
+				report.Conflicts = append(report.Conflicts, (i*blockSize)+c.ffs())
+			}
+		}
+
+		report.Added += (block &^ old).popcount()
+		report.Removed += (old &^ block).popcount()
+	}
+
+	b.combine(other, op, 1)
+
+	return
+}
+
+// mergeOp returns the operation of policy.
+func mergeOp(policy MergePolicy) bulkOp {
+	switch policy {
+	case MergeUnion, MergeExclusive:
+		return opOr
+
+	case MergeIntersection:
+		return opAnd
+
+	case MergeLastWriter:
+		return opCopy
+
+	default:
+		panic("unknown merge policy")
+	}
+}
+
+// lockPair write-locks dst and read-locks src in address order, so that
+// concurrent operations on the same pair in opposite directions cannot
+// deadlock. It returns the function releasing both locks.
+func lockPair(dst, src *BitArray) (unlock func()) {
+	if addr(dst) < addr(src) {
+		dst.lock()
+		src.rlock()
+	} else {
+		src.rlock()
+		dst.lock()
+	}
+
+	return func() {
+		src.runlock()
+		dst.unlock()
+	}
+}
+
+// rlockPair read-locks x and y in address order and returns the function
+// releasing the locks. x and y may be the same array.
+func rlockPair(x, y *BitArray) (unlock func()) {
+	if x == y {
+		x.rlock()
+		return x.runlock
+	}
+
+	if addr(x) > addr(y) {
+		x, y = y, x
+	}
+
+	x.rlock()
+	y.rlock()
+
+	return func() {
+		y.runlock()
+		x.runlock()
+	}
+}
+
+// lockSet write-locks dst and read-locks srcs in address order, like
+// lockPair for more arrays. The arrays may repeat, and dst may be among
+// srcs or nil; every array is locked once. It returns the function releasing
+// the locks.
+func lockSet(dst *BitArray, srcs ...*BitArray) (unlock func()) {
+	arrays := append([]*BitArray{dst}, srcs...)
+	slices.SortFunc(arrays, func(x, y *BitArray) int {
+		return cmp.Compare(addr(x), addr(y))
+	})
+	arrays = slices.Compact(arrays)
+
+	if arrays[0] == nil {
+		arrays = arrays[1:]
+	}
+
+	for _, x := range arrays {
+		if x == dst {
+			x.lock()
+		} else {
+			x.rlock()
+		}
+	}
+
+	return func() {
+		for _, x := range slices.Backward(arrays) {
+			if x == dst {
+				x.unlock()
+			} else {
+				x.runlock()
+			}
+		}
+	}
+}
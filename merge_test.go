@@ -0,0 +1,133 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMarked(capacity int64, indices ...int64) *BitArray {
+	b := NewBitArray(capacity)
+
+	for _, i := range indices {
+		b.Mark(i)
+	}
+
+	return b
+}
+
+func TestBitArrayMergeUnion(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newMarked(200, 1, 70, 150)
+	b := newMarked(200, 1, 2, 199)
+
+	a.Merge(b, MergeUnion)
+
+	for _, i := range []int64{1, 2, 70, 150, 199} {
+		assert.True(a.Get(i))
+	}
+	assert.Equal(5, a.Len())
+}
+
+func TestBitArrayMergeIntersection(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newMarked(200, 1, 70, 150)
+	b := newMarked(100, 1, 70)
+
+	a.Merge(b, MergeIntersection)
+
+	assert.True(a.Get(1))
+	assert.True(a.Get(70))
+	assert.False(a.Get(150))
+	assert.Equal(2, a.Len())
+}
+
+func TestBitArrayMergeLastWriter(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newMarked(300, 1, 70, 250)
+	b := newMarked(100, 2)
+
+	a.Merge(b, MergeLastWriter)
+
+	assert.False(a.Get(1))
+	assert.False(a.Get(70))
+	assert.True(a.Get(2))
+	assert.True(a.Get(250))
+	assert.Equal(2, a.Len())
+}
+
+func TestBitArrayMergeLastWriterTail(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, watched := range []bool{false, true} {
+		a := newMarked(300, 1, 99, 100, 110, 127, 128, 250)
+		if watched {
+			a.Watch(0, 300) // combines block by block through the journal
+		}
+
+		report, err := a.MergeFrom(newMarked(100, 2), MergeLastWriter)
+		assert.NoError(err)
+		assert.Equal(MergeReport{Added: 1, Removed: 2}, report)
+		assert.Equal("2,100,110,127-128,250", a.FormatRanges())
+		assert.NoError(a.Validate())
+	}
+}
+
+func TestBitArrayMergeCapacity(t *testing.T) {
+	assert := assert.New(t)
+
+	a := NewBitArray(10)
+	b := newMarked(100, 5, 20)
+
+	a.Merge(b, MergeUnion)
+
+	assert.True(a.Get(5))
+	assert.Equal(1, a.Len())
+}
+
+func TestBitArrayMergeSelf(t *testing.T) {
+	a := newMarked(100, 5)
+	a.Merge(a, MergeIntersection)
+
+	assert.Equal(t, 1, a.Len())
+}
+
+func TestBitArrayMergeFromExclusive(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newMarked(200, 1, 2, 100)
+	b := newMarked(300, 2, 3, 100, 250)
+
+	report, err := a.MergeFrom(b, MergeExclusive)
+	assert.NoError(err)
+	assert.Equal([]int64{2, 100}, report.Conflicts)
+	assert.Equal(int64(1), report.Added)
+	assert.Equal(int64(0), report.Removed)
+	assert.Equal("1-3,100", a.FormatRanges())
+	assert.Equal(4, a.Len())
+	assert.NoError(a.Validate())
+
+	report, err = a.MergeFrom(a, MergeExclusive)
+	assert.NoError(err)
+	assert.Empty(report.Conflicts)
+}
+
+func TestBitArrayMergeFromReport(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newMarked(200, 1, 2, 150)
+	report, err := a.MergeFrom(newMarked(100, 2, 3), MergeLastWriter)
+	assert.NoError(err)
+	assert.Equal(MergeReport{Added: 1, Removed: 1}, report)
+	assert.Equal("2-3,150", a.FormatRanges())
+
+	report, err = a.MergeFrom(newMarked(100, 3), MergeIntersection)
+	assert.NoError(err)
+	assert.Equal(MergeReport{Removed: 2}, report)
+	assert.Equal("3", a.FormatRanges())
+
+	assert.Panics(func() { a.MergeFrom(a, MergePolicy(42)) })
+}
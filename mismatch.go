@@ -0,0 +1,71 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCapacityMismatch is returned when an operation combines arrays of
+// different capacities under CapacityError.
+var ErrCapacityMismatch = errors.New("bitarray: capacity mismatch")
+
+// CapacityPolicy defines how the operations combining two arrays, like And,
+// Or, Xor, CopyFrom, Merge and Equal, treat arrays of different capacities.
+// The policy of the array the operation is called on applies.
+type CapacityPolicy int
+
+const (
+	// CapacityZero reads the bits missing from the smaller array as false.
+	// The result keeps the capacity of the array that is changed, unless an
+	// operation documents otherwise, like CopyFrom under RangeGrow.
+	CapacityZero CapacityPolicy = iota
+
+	// CapacityError rejects arrays of different capacities: the E-variants
+	// report ErrCapacityMismatch, the methods without an error result do
+	// nothing and Equal reports false.
+	CapacityError
+)
+
+// WithCapacityPolicy sets the treatment of arrays of different capacities
+// by the operations combining two arrays.
+func WithCapacityPolicy(policy CapacityPolicy) Option {
+	return func(c *config) {
+		c.mismatch = policy
+	}
+}
+
+// Equal reports whether b and other hold the same bits. Under CapacityZero
+// the bits beyond the capacity of an array read as false, so arrays of
+// different capacities are equal if the larger one has no bits set beyond
+// the smaller one; under CapacityError they are never equal.
+func (b *BitArray) Equal(other *BitArray) bool {
+	if b == other {
+		return true
+	}
+
+	unlock := rlockPair(b, other)
+	defer unlock()
+
+	if b.matchCapacity(other) != nil || b.count.Get64() != other.count.Get64() {
+		return false
+	}
+
+	for i, n := int64(0), max(b.size, other.size); i < n; i++ {
+		if b.blockAt(i) != other.blockAt(i) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchCapacity returns an error wrapping ErrCapacityMismatch if the
+// capacities of b and other differ under CapacityError. Callers hold the
+// locks of both arrays.
+func (b *BitArray) matchCapacity(other *BitArray) error {
+	if b.mismatch == CapacityError && b.capacity.Get64() != other.capacity.Get64() {
+		return fmt.Errorf("%w: %d and %d", ErrCapacityMismatch, b.capacity.Get64(), other.capacity.Get64())
+	}
+
+	return nil
+}
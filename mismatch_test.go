@@ -0,0 +1,70 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayEqual(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 70)
+
+	assert.True(b.Equal(b))
+	assert.True(b.Equal(newMarked(100, 1, 70)))
+	assert.True(b.Equal(newMarked(300, 1, 70)))
+	assert.False(b.Equal(newMarked(300, 1, 70, 200)))
+	assert.False(b.Equal(newMarked(100, 1)))
+	assert.False(b.Equal(newMarked(100, 1, 71)))
+
+	strict := NewBitArray(100, WithCapacityPolicy(CapacityError), WithInitialSet(1, 70))
+	assert.True(strict.Equal(b))
+	assert.False(strict.Equal(newMarked(300, 1, 70)))
+}
+
+func TestBitArrayCapacityError(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithCapacityPolicy(CapacityError), WithInitialSet(1, 2))
+	other := newMarked(200, 2, 3)
+
+	assert.True(errors.Is(b.AndE(other), ErrCapacityMismatch))
+	assert.True(errors.Is(b.OrE(other), ErrCapacityMismatch))
+	assert.True(errors.Is(b.XorE(other), ErrCapacityMismatch))
+	assert.True(errors.Is(b.CopyFromE(other), ErrCapacityMismatch))
+
+	b.And(other)
+	b.Or(other)
+	b.ParOr(other, 2)
+	b.Merge(other, MergeUnion)
+	assert.True(errors.Is(b.MergeE(other, MergeUnion), ErrCapacityMismatch))
+
+	report, err := b.MergeFrom(other, MergeExclusive)
+	assert.True(errors.Is(err, ErrCapacityMismatch))
+	assert.Equal(MergeReport{}, report)
+	assert.Equal("1-2", b.FormatRanges())
+
+	same := newMarked(100, 2, 3)
+	assert.NoError(b.OrE(same))
+	assert.Equal("1-3", b.FormatRanges())
+	assert.NoError(b.AndE(same))
+	assert.Equal("2-3", b.FormatRanges())
+	assert.NoError(b.XorE(same))
+	assert.Equal(0, b.Len())
+	assert.NoError(b.CopyFromE(same))
+	assert.Equal("2-3", b.FormatRanges())
+}
+
+func TestBitArrayCapacityZero(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 2, 90)
+
+	assert.NoError(b.AndE(newMarked(50, 1, 2)))
+	assert.Equal("1-2", b.FormatRanges())
+	assert.NoError(b.OrE(newMarked(200, 3, 150)))
+	assert.Equal("1-3", b.FormatRanges())
+	assert.Equal(100, b.Cap())
+}
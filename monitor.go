@@ -0,0 +1,119 @@
+package bitarray
+
+// WithFullCallbacks sets the functions called once on every transition of
+// the array from having room to full, onFull, and from full to having room,
+// onNotFull, e.g. for alerts on the exhaustion of a pool. Either may be nil.
+// A callback is called by the goroutine that made the change, after the
+// lock of the array is released, so it may use the array; callbacks of
+// transitions in quick succession may run concurrently.
+func WithFullCallbacks(onFull, onNotFull func()) Option {
+	return func(c *config) {
+		m := c.monitorConfig()
+		m.onFull, m.onNotFull = onFull, onNotFull
+	}
+}
+
+// Threshold is a utilization level whose crossings are reported, see
+// WithThresholds.
+type Threshold struct {
+	// Level is the utilization, from 0 to 1, at and above which the array
+	// is above the threshold.
+	Level float64
+
+	// Hysteresis debounces the crossings: once above the threshold, the
+	// array is below it again only when the utilization drops below
+	// Level-Hysteresis, so changes around the level do not fire repeatedly.
+	Hysteresis float64
+
+	// OnCross is called with the direction and the utilization after every
+	// crossing.
+	OnCross func(above bool, utilization float64)
+}
+
+// WithThresholds registers utilization thresholds, e.g. at 0.8 and 0.95 to
+// add capacity before the array is exhausted. The callbacks are called like
+// those of WithFullCallbacks. An array starts below its thresholds, so those
+// the initial bits exceed are reported by the first change.
+func WithThresholds(thresholds ...Threshold) Option {
+	return func(c *config) {
+		m := c.monitorConfig()
+
+		for _, t := range thresholds {
+			m.thresholds = append(m.thresholds, threshold{Threshold: t})
+		}
+	}
+}
+
+// monitor detects the transitions of the state of an array the callbacks
+// are registered for.
+type monitor struct {
+	onFull, onNotFull func()
+	full              bool
+	thresholds        []threshold
+}
+
+type threshold struct {
+	Threshold
+	above bool
+}
+
+// monitorConfig returns the monitor of the array being configured, creating
+// it on first use.
+func (c *config) monitorConfig() *monitor {
+	if c.monitor == nil {
+		c.monitor = new(monitor)
+	}
+
+	return c.monitor
+}
+
+// check compares the state of the array with the state after the previous
+// check and returns the function running the callbacks of the transitions,
+// or nil. Callers hold the write lock.
+func (m *monitor) check(b *BitArray) func() {
+	var calls []func()
+
+	if full := b.full(); full != m.full {
+		m.full = full
+
+		switch {
+		case full && m.onFull != nil:
+			calls = append(calls, m.onFull)
+
+		case !full && m.onNotFull != nil:
+			calls = append(calls, m.onNotFull)
+		}
+	}
+
+	u := ratio(b.count.Get64(), b.capacity.Get64())
+
+	for k := range m.thresholds {
+		t := &m.thresholds[k]
+
+		switch {
+		case !t.above && u >= t.Level:
+			t.above = true
+
+		case t.above && u < t.Level-t.Hysteresis:
+			t.above = false
+
+		default:
+			continue
+		}
+
+		if t.OnCross != nil {
+			fn, above := t.OnCross, t.above
+			calls = append(calls, func() { fn(above, u) })
+		}
+	}
+
+	if len(calls) == 0 {
+		return nil
+	}
+
+	return func() {
+		for _, fn := range calls {
+			fn()
+		}
+	}
+}
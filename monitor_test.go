@@ -0,0 +1,120 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayIsFull(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(2)
+	assert.False(b.IsFull())
+	b.MarkAll(0, 1)
+	assert.True(b.IsFull())
+	assert.True(NewBitArray(0).IsFull())
+
+	b = NewBitArray(100, WithReservedRanges(Range{0, 10}))
+	b.Exclude(10, 99)
+	assert.False(b.IsFull())
+	assert.Equal(int64(99), b.MarkFree())
+	assert.True(b.IsFull())
+	assert.True(b.HasRoom())
+	assert.False(b.IsEmpty())
+}
+
+func TestWithFullCallbacksFenced(t *testing.T) {
+	assert := assert.New(t)
+
+	var full, notFull int
+
+	b := NewBitArray(100, WithFullCallbacks(func() {
+		full++
+	}, func() {
+		notFull++
+	}))
+
+	b.Exclude(0, 99)
+	assert.Equal(int64(99), b.MarkFree())
+	assert.Equal(1, full)
+
+	b.Include(50, 60)
+	assert.Equal(1, notFull)
+}
+
+func TestWithFullCallbacks(t *testing.T) {
+	assert := assert.New(t)
+
+	var full, notFull int
+
+	var b *BitArray
+	b = NewBitArray(3, WithAutoGrow(), WithFullCallbacks(func() {
+		full++
+		assert.True(b.IsFull()) // the lock is released
+	}, func() {
+		notFull++
+	}))
+
+	b.MarkFree()
+	b.MarkFree()
+	assert.Equal(0, full)
+
+	b.MarkFree()
+	b.MarkFree()
+	assert.Equal(1, full)
+	assert.Equal(0, notFull)
+
+	b.Unmark(1)
+	b.Unmark(2)
+	assert.Equal(1, notFull)
+
+	b.Fill()
+	assert.Equal(2, full)
+
+	b.Mark(10) // grows
+	assert.Equal(2, notFull)
+
+	b.Reset()
+	assert.Equal(2, notFull)
+	assert.Equal(2, full)
+}
+
+func TestWithThresholds(t *testing.T) {
+	assert := assert.New(t)
+
+	type crossing struct {
+		level float64
+		above bool
+	}
+
+	var crossings []crossing
+
+	on := func(level float64) func(bool, float64) {
+		return func(above bool, _ float64) {
+			crossings = append(crossings, crossing{level, above})
+		}
+	}
+
+	b := NewBitArray(100, WithThresholds(
+		Threshold{Level: 0.8, Hysteresis: 0.05, OnCross: on(0.8)},
+		Threshold{Level: 0.95, OnCross: on(0.95)},
+	))
+
+	for n := 0; n < 80; n++ {
+		b.MarkFree()
+	}
+	assert.Equal([]crossing{{0.8, true}}, crossings)
+
+	b.Unmark(0)
+	b.Unmark(1)
+	b.Mark(0)
+	b.Mark(1)
+	assert.Len(crossings, 1) // debounced
+
+	b.Fill()
+	assert.Equal([]crossing{{0.8, true}, {0.95, true}}, crossings)
+
+	b.Reset()
+	assert.Equal([]crossing{{0.8, true}, {0.95, true}, {0.8, false}, {0.95, false}}, crossings)
+}
@@ -0,0 +1,293 @@
+package bitarray
+
+// bulkOp is a whole-array operation of apply.
+type bulkOp int
+
+const (
+	opAnd bulkOp = iota
+	opOr
+	opXor
+	opCopy
+)
+
+// block returns the result of op on a block of the destination and the
+// matching block of the source.
+func (op bulkOp) block(dst, src BitBlock) BitBlock {
+	switch op {
+	case opAnd:
+		return dst & src
+
+	case opOr:
+		return dst | src
+
+	case opXor:
+		return dst ^ src
+
+	default:
+		return src
+	}
+}
+
+// And clears the bits of b that are not set in other. The bits of b beyond
+// the capacity of other are cleared. Arrays of different capacities are
+// handled according to the CapacityPolicy of b.
+func (b *BitArray) And(other *BitArray) {
+	b.apply(other, opAnd)
+}
+
+// AndE is like And but returns ErrCapacityMismatch if the capacities differ
+// under CapacityError.
+func (b *BitArray) AndE(other *BitArray) error {
+	return b.apply(other, opAnd)
+}
+
+// Or sets the bits of b that are set in other. Bits of other beyond the
+// capacity of b are dropped. Arrays of different capacities are handled
+// according to the CapacityPolicy of b.
+func (b *BitArray) Or(other *BitArray) {
+	b.apply(other, opOr)
+}
+
+// OrE is like Or but returns ErrCapacityMismatch if the capacities differ
+// under CapacityError.
+func (b *BitArray) OrE(other *BitArray) error {
+	return b.apply(other, opOr)
+}
+
+// Xor flips the bits of b that are set in other. Bits of other beyond the
+// capacity of b are dropped. Arrays of different capacities are handled
+// according to the CapacityPolicy of b.
+func (b *BitArray) Xor(other *BitArray) {
+	b.XorE(other)
+}
+
+// XorE is like Xor but returns ErrCapacityMismatch if the capacities differ
+// under CapacityError.
+func (b *BitArray) XorE(other *BitArray) error {
+	if b == other {
+		b.lock()
+		defer b.unlock()
+
+		b.clearAll()
+
+		return nil
+	}
+
+	return b.apply(other, opXor)
+}
+
+// Not flips all the bits up to the capacity. The bits beyond the capacity
+// stay clear.
+func (b *BitArray) Not() {
+	b.lock()
+	defer b.unlock()
+
+	if b.blockwise() {
+		for i := int64(0); i < b.size; i++ {
+			b.setBlock(i, ^b.blocks[i])
+		}
+	} else {
+		for i := int64(0); i < b.size; i++ {
+			b.blocks[i] = ^b.blocks[i]
+		}
+
+		if b.size > 0 {
+			b.blocks[b.size-1] &= b.tailMask()
+		}
+
+		b.count.Set64(b.capacity.Get64() - b.count.Get64())
+
+		if b.counts != nil {
+			b.recache()
+		}
+	}
+
+	b.curIndex = 0
+}
+
+// clearAll clears all the bits block by block, which is what the Xor of an
+// array with itself does. Unlike Reset, it leaves the epoch and the reserved
+// ranges alone. Callers hold the write lock.
+func (b *BitArray) clearAll() {
+	for i := int64(0); i < b.size; i++ {
+		b.setBlock(i, 0)
+	}
+
+	b.curIndex = 0
+}
+
+// XorWithMask flips exactly the bits of b that are set in mask and adjusts
+// the number of set bits, e.g. to apply a replicated change set computed as
+// the Xor of two states. Unlike Xor, it applies a mask of another capacity
+// whatever the CapacityPolicy, so a change set is never skipped; the bits of
+// mask beyond the capacity of b are dropped.
+func (b *BitArray) XorWithMask(mask *BitArray) {
+	if b == mask {
+		b.lock()
+		defer b.unlock()
+
+		b.clearAll()
+
+		return
+	}
+
+	unlock := lockPair(b, mask)
+	defer unlock()
+
+	b.combine(mask, opXor, 1)
+}
+
+// UnionAll returns a new array with the bits that are set in any of the
+// arrays, with the largest of their capacities. The arrays are combined in
+// one pass over the blocks, which moves less memory than folding them
+// pairwise with Or.
+func UnionAll(arrays ...*BitArray) *BitArray {
+	return combineAll(arrays, opOr)
+}
+
+// IntersectAll returns a new array with the bits that are set in all the
+// arrays, with the smallest of their capacities. Like UnionAll, the arrays
+// are combined in one pass over the blocks.
+func IntersectAll(arrays ...*BitArray) *BitArray {
+	return combineAll(arrays, opAnd)
+}
+
+// combineAll combines the blocks of the arrays with op, opOr or opAnd, into
+// a new array.
+func combineAll(arrays []*BitArray, op bulkOp) *BitArray {
+	if len(arrays) == 0 {
+		return NewBitArray(0)
+	}
+
+	unlock := lockSet(nil, arrays...)
+	defer unlock()
+
+	capacity := arrays[0].capacity.Get64()
+
+	for _, x := range arrays[1:] {
+		if op == opOr {
+			capacity = max(capacity, x.capacity.Get64())
+		} else {
+			capacity = min(capacity, x.capacity.Get64())
+		}
+	}
+
+	res := NewBitArray(capacity)
+
+	for i := int64(0); i < res.size; i++ {
+		var block BitBlock
+		if op == opAnd {
+			block = bitBlockFull
+		}
+
+		for _, x := range arrays {
+			switch {
+			case i >= x.size:
+				// a smaller array of a union
+			case op == opOr:
+				block |= x.blocks[i]
+			default:
+				block &= x.blocks[i]
+			}
+		}
+
+		res.blocks[i] = block
+	}
+
+	if res.size > 0 {
+		res.blocks[res.size-1] &= res.tailMask()
+	}
+
+	res.recount()
+
+	return res
+}
+
+// apply combines the blocks of other into b. Unless the changes of b are
+// journaled block by block, the blocks are combined by the vectorized
+// kernels and counted afterwards.
+func (b *BitArray) apply(other *BitArray, op bulkOp) error {
+	return b.applyPar(other, op, 1)
+}
+
+// combined returns block i of b combined with block i of other. A copy keeps
+// the bits of b beyond the capacity of other. Callers hold the locks of both
+// arrays.
+func (b *BitArray) combined(other *BitArray, op bulkOp, i int64) BitBlock {
+	v := op.block(b.blocks[i], other.blocks[i])
+
+	if op == opCopy {
+		m := capacityMask(i, other.capacity.Get64())
+		v = v&m | b.blocks[i]&^m
+	}
+
+	return v
+}
+
+// combine combines the blocks of other into b like apply, running the
+// kernels on up to workers goroutines. Callers hold the locks of both
+// arrays.
+func (b *BitArray) combine(other *BitArray, op bulkOp, workers int) {
+	n := min(b.size, other.size)
+
+	if b.blockwise() {
+		for i := int64(0); i < n; i++ {
+			b.setBlock(i, b.combined(other, op, i))
+		}
+
+		if op == opAnd {
+			for i := n; i < b.size; i++ {
+				b.setBlock(i, 0)
+			}
+		}
+	} else {
+		covered := n
+		if op == opCopy {
+			// the bits of b beyond the capacity of other are kept
+			covered = min(n, other.capacity.Get64()/blockSize)
+
+			for i := covered; i < n; i++ {
+				b.blocks[i] = b.combined(other, op, i)
+			}
+		}
+
+		parallel(covered, workers, func(lo, hi int64) {
+			dst, src := b.blocks[lo:hi], other.blocks[lo:hi]
+
+			switch op {
+			case opAnd:
+				andBlocks(dst, src)
+
+			case opOr:
+				orBlocks(dst, src)
+
+			case opXor:
+				xorBlocks(dst, src)
+
+			case opCopy:
+				copy(dst, src)
+			}
+		})
+
+		if op == opAnd {
+			clear(b.blocks[n:b.size])
+		}
+
+		// only the last block can hold bits of other beyond the capacity
+		if b.size > 0 {
+			b.blocks[b.size-1] &= b.tailMask()
+		}
+
+		if workers > 1 {
+			b.count.Set64(b.parCount(0, b.capacity.Get64(), workers))
+
+			if b.counts != nil {
+				b.recache()
+			}
+		} else {
+			b.recount()
+		}
+	}
+
+	b.curIndex = 0
+}
@@ -0,0 +1,168 @@
+package bitarray
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayAndOrXor(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 1, 2, 100, 250)
+	b.And(newMarked(200, 2, 100, 150))
+	assert.Equal("2,100", b.FormatRanges())
+	assert.Equal(2, b.Len())
+
+	b.Or(newMarked(1000, 5, 299, 300, 999))
+	assert.Equal("2,5,100,299", b.FormatRanges())
+	assert.Equal(4, b.Len())
+	assert.NoError(b.Validate())
+
+	b.Xor(newMarked(300, 2, 3))
+	assert.Equal("3,5,100,299", b.FormatRanges())
+	assert.Equal(4, b.Len())
+
+	b.Xor(b)
+	assert.Equal(0, b.Len())
+
+	b.Or(b)
+	assert.Equal(0, b.Len())
+}
+
+func TestBitArrayXorSelf(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, xor := range []func(b *BitArray){
+		func(b *BitArray) { assert.NoError(b.XorE(b)) },
+		func(b *BitArray) { assert.NoError(b.XorCtx(context.Background(), b)) },
+		func(b *BitArray) { b.ApplyDelta(b) },
+	} {
+		b := NewBitArray(100, WithReservedRanges(Range{0, 10}), WithStats())
+		b.Mark(50)
+
+		xor(b)
+		assert.Zero(b.Len())
+		assert.Zero(b.Epoch())
+		assert.Zero(b.Stats().Resets)
+		assert.NoError(b.Validate())
+		assert.Equal(int64(10), b.MarkFree()) // the reserved bits stay fenced
+	}
+}
+
+func TestBitArrayAndJournaled(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 300)
+	assert.NoError(err)
+
+	d.MarkAll(1, 2, 250)
+	d.And(newMarked(200, 2))
+	d.Xor(newMarked(300, 7))
+	assert.Equal("2,7", d.FormatRanges())
+	assert.NoError(d.Close())
+
+	r, err := Recover(path, 0)
+	assert.NoError(err)
+	assert.Equal("2,7", r.FormatRanges())
+	assert.NoError(r.Close())
+}
+
+func TestBitArrayXorWithMask(t *testing.T) {
+	assert := assert.New(t)
+
+	prev, next := newMarked(200, 1, 5, 150), newMarked(200, 5, 7, 199)
+
+	delta := newMarked(200)
+	delta.Or(prev)
+	delta.Xor(next)
+
+	prev.XorWithMask(delta)
+	assert.Equal(next.FormatRanges(), prev.FormatRanges())
+	assert.Equal(3, prev.Len())
+	assert.NoError(prev.Validate())
+
+	// unlike Xor, the mask is applied under CapacityError
+	b := NewBitArray(200, WithCapacityPolicy(CapacityError), WithInitialSet(1, 150))
+	b.Xor(newMarked(300, 1, 250))
+	assert.Equal("1,150", b.FormatRanges())
+
+	b.XorWithMask(newMarked(300, 1, 250))
+	assert.Equal("150", b.FormatRanges())
+	b.XorWithMask(newMarked(100, 2))
+	assert.Equal("2,150", b.FormatRanges())
+	assert.NoError(b.Validate())
+
+	b.XorWithMask(b)
+	assert.Zero(b.Len())
+}
+
+func TestBitArrayNot(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, capacity := range []int64{0, 1, 63, 64, 100, 128} {
+		b := newMarked(capacity)
+		b.Not()
+		assert.Equal(capacity, b.Len64())
+		assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+		assert.NoError(b.Validate(), "capacity %d", capacity)
+
+		b.Not()
+		assert.Equal(0, b.Len())
+		assert.NoError(b.Validate())
+	}
+
+	b := newMarked(100, 0, 50, 99)
+	b.Not()
+	assert.Equal("1-49,51-98", b.FormatRanges())
+	assert.Equal(97, b.Len())
+
+	var z BitArray
+	z.Not()
+	z.And(b)
+	assert.NoError(z.Validate())
+}
+
+func TestBitArrayNotJournaled(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100)
+	assert.NoError(err)
+
+	d.MarkAll(0, 99)
+	d.Not()
+	assert.Equal(98, d.Len())
+	assert.NoError(d.Validate())
+	assert.NoError(d.Close())
+
+	r, err := Recover(path, 0)
+	assert.NoError(err)
+	assert.Equal("1-98", r.FormatRanges())
+	assert.NoError(r.Close())
+}
+
+func TestUnionAll(t *testing.T) {
+	assert := assert.New(t)
+
+	a, b, c := newMarked(100, 1, 64), newMarked(200, 2, 64, 150), newMarked(70, 3)
+
+	u := UnionAll(a, b, c, a)
+	assert.Equal(200, u.Cap())
+	assert.Equal("1-3,64,150", u.FormatRanges())
+	assert.NoError(u.Validate())
+
+	i := IntersectAll(a, b)
+	assert.Equal(100, i.Cap())
+	assert.Equal("64", i.FormatRanges())
+	assert.Equal("", IntersectAll(a, b, c).FormatRanges())
+	assert.Equal(70, IntersectAll(a, b, c).Cap())
+	assert.Equal("1,64", IntersectAll(a).FormatRanges())
+	assert.Equal(0, UnionAll().Cap())
+	assert.NoError(i.Validate())
+}
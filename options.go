@@ -0,0 +1,158 @@
+package bitarray
+
+import "log/slog"
+
+// Option configures a BitArray created by NewBitArray.
+type Option func(*config)
+
+// config holds the settings collected from the options.
+type config struct {
+	policy   RangePolicy
+	mismatch CapacityPolicy
+	locking  LockStrategy
+	source   BlockSource
+	align    int64
+	initial  []int64
+	view     []BitBlock
+
+	base     int64
+	rotate   int64
+	scan     ScanStrategy
+	tiers    [][]Range
+	reserved []Range
+
+	leakTracking bool
+	logger       *slog.Logger
+	inst         Instrumentation
+	limiter      *limiter
+	countCache   bool
+	monitor      *monitor
+	tally        *tally
+
+	snapshotEvery int
+	sync          SyncPolicy
+}
+
+// LockStrategy defines how a BitArray synchronizes concurrent access.
+type LockStrategy int
+
+const (
+	// LockRW allows concurrent readers and exclusive writers.
+	LockRW LockStrategy = iota
+
+	// LockMutex serializes readers and writers alike, which is cheaper when
+	// reads do not dominate.
+	LockMutex
+
+	// LockNone disables locking. The caller must serialize access.
+	LockNone
+)
+
+// BlockSource allocates zeroed storage for n blocks. The returned slice must
+// have length n.
+type BlockSource func(n int64) []BitBlock
+
+// WithRangePolicy sets the behavior for indexes beyond the capacity.
+func WithRangePolicy(policy RangePolicy) Option {
+	return func(c *config) {
+		c.policy = policy
+	}
+}
+
+// WithAutoGrow makes the array grow to fit the indexes it is written to.
+// It is a shorthand for WithRangePolicy(RangeGrow).
+func WithAutoGrow() Option {
+	return WithRangePolicy(RangeGrow)
+}
+
+// WithLockStrategy sets the synchronization strategy of the array.
+func WithLockStrategy(strategy LockStrategy) Option {
+	return func(c *config) {
+		c.locking = strategy
+	}
+}
+
+// WithBlockSource sets the allocator of the block storage, e.g. a pool or
+// a memory-mapped region.
+func WithBlockSource(source BlockSource) Option {
+	return func(c *config) {
+		c.source = source
+	}
+}
+
+// WithInitialSet marks the bits at the specified indexes on construction.
+// Indexes out of range are handled according to the RangePolicy.
+func WithInitialSet(indices ...int64) Option {
+	return func(c *config) {
+		c.initial = append(c.initial, indices...)
+	}
+}
+
+func (b *BitArray) alloc(n int64) []BitBlock {
+	if b.align > 0 {
+		return b.allocAligned(n)
+	}
+
+	return b.allocRaw(n)
+}
+
+func (b *BitArray) allocRaw(n int64) []BitBlock {
+	if b.source != nil {
+		return b.source(n)
+	}
+
+	return make([]BitBlock, n)
+}
+
+func (b *BitArray) lock() {
+	if b.locking != LockNone {
+		b.mu.Lock()
+	}
+
+	if b.pins.Get64() > 0 {
+		b.unshare()
+	}
+}
+
+// unlock releases the write lock and then runs the callbacks of the state
+// transitions the changes made, if any. It wakes the waiters of AcquireN if
+// enough bits are free.
+func (b *BitArray) unlock() {
+	var notify func()
+	if b.monitor != nil {
+		notify = b.monitor.check(b)
+	}
+
+	if b.released != nil && b.allocatable() >= b.wanted {
+		close(b.released)
+		b.released = nil
+	}
+
+	if b.locking != LockNone {
+		b.mu.Unlock()
+	}
+
+	if notify != nil {
+		notify()
+	}
+}
+
+func (b *BitArray) rlock() {
+	switch b.locking {
+	case LockRW:
+		b.mu.RLock()
+
+	case LockMutex:
+		b.mu.Lock()
+	}
+}
+
+func (b *BitArray) runlock() {
+	switch b.locking {
+	case LockRW:
+		b.mu.RUnlock()
+
+	case LockMutex:
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,58 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayWithAutoGrow(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(0, WithAutoGrow())
+
+	b.Mark(200)
+	assert.True(b.Get(200))
+	assert.Equal(201, b.Cap())
+}
+
+func TestBitArrayWithLockStrategy(t *testing.T) {
+	for _, strategy := range []LockStrategy{LockRW, LockMutex, LockNone} {
+		b := NewBitArray(100, WithLockStrategy(strategy))
+
+		b.Mark(10)
+		assert.True(t, b.Get(10))
+		assert.Equal(t, int64(0), b.MarkFree())
+	}
+}
+
+func TestBitArrayWithBlockSource(t *testing.T) {
+	assert := assert.New(t)
+
+	var allocated []int64
+	source := func(n int64) []BitBlock {
+		allocated = append(allocated, n)
+		return make([]BitBlock, n)
+	}
+
+	b := NewBitArray(100, WithBlockSource(source), WithAutoGrow())
+	b.Mark(99)
+	b.Mark(1000)
+
+	assert.Equal([]int64{100/blockSize + 1, 1024 / blockSize}, allocated)
+	assert.True(b.Get(99))
+	assert.True(b.Get(1000))
+}
+
+func TestBitArrayWithInitialSet(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithInitialSet(1, 2, 3), WithInitialSet(99, 100, -1))
+	assert.Equal(4, b.Len())
+	assert.True(b.Get(99))
+	assert.Equal(int64(0), b.MarkFree())
+
+	b = NewBitArray(10, WithInitialSet(50), WithAutoGrow())
+	assert.True(b.Get(50))
+	assert.Equal(51, b.Cap())
+}
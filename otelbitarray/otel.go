@@ -0,0 +1,94 @@
+// Package otelbitarray reports the allocations of a bitarray.BitArray to
+// OpenTelemetry metrics.
+package otelbitarray
+
+import (
+	"context"
+	"time"
+
+	"github.com/aermolaev/bitarray"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Instrumentation implements bitarray.Instrumentation on top of OpenTelemetry
+// instruments:
+//
+//	bitarray.allocations      counter of the bits allocated by MarkFree
+//	bitarray.exhaustions      counter of the MarkFree calls that found no bit
+//	bitarray.markfree.latency histogram of the MarkFree latency in seconds
+type Instrumentation struct {
+	allocations metric.Int64Counter
+	exhaustions metric.Int64Counter
+	latency     metric.Float64Histogram
+	attrs       metric.MeasurementOption
+}
+
+var _ bitarray.Instrumentation = (*Instrumentation)(nil)
+
+// NewInstrumentation creates the instruments with meter. The attributes are
+// added to every measurement.
+func NewInstrumentation(meter metric.Meter, attrs ...attribute.KeyValue) (*Instrumentation, error) {
+	i := &Instrumentation{attrs: metric.WithAttributes(attrs...)}
+
+	var err error
+
+	if i.allocations, err = meter.Int64Counter("bitarray.allocations",
+		metric.WithDescription("Number of bits allocated by MarkFree.")); err != nil {
+		return nil, err
+	}
+
+	if i.exhaustions, err = meter.Int64Counter("bitarray.exhaustions",
+		metric.WithDescription("Number of MarkFree calls that found no free bit.")); err != nil {
+		return nil, err
+	}
+
+	if i.latency, err = meter.Float64Histogram("bitarray.markfree.latency",
+		metric.WithDescription("Latency of MarkFree calls."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// Allocated implements bitarray.Instrumentation.
+func (i *Instrumentation) Allocated(index int64, latency time.Duration) {
+	ctx := context.Background()
+
+	i.allocations.Add(ctx, 1, i.attrs)
+	i.latency.Record(ctx, latency.Seconds(), i.attrs)
+}
+
+// Exhausted implements bitarray.Instrumentation.
+func (i *Instrumentation) Exhausted(latency time.Duration) {
+	ctx := context.Background()
+
+	i.exhaustions.Add(ctx, 1, i.attrs)
+	i.latency.Record(ctx, latency.Seconds(), i.attrs)
+}
+
+// Observe registers the gauges bitarray.len and bitarray.cap reporting the
+// number of set bits and the capacity of b. Unregister the returned
+// registration when b is no longer used.
+func Observe(meter metric.Meter, b *bitarray.BitArray, attrs ...attribute.KeyValue) (metric.Registration, error) {
+	length, err := meter.Int64ObservableGauge("bitarray.len",
+		metric.WithDescription("Number of set bits."))
+	if err != nil {
+		return nil, err
+	}
+
+	capacity, err := meter.Int64ObservableGauge("bitarray.cap",
+		metric.WithDescription("Capacity in bits."))
+	if err != nil {
+		return nil, err
+	}
+
+	opt := metric.WithAttributes(attrs...)
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(length, b.Len64(), opt)
+		o.ObserveInt64(capacity, b.Cap64(), opt)
+
+		return nil
+	}, length, capacity)
+}
@@ -0,0 +1,56 @@
+package otelbitarray
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aermolaev/bitarray"
+	"github.com/stretchr/testify/assert"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInstrumentation(t *testing.T) {
+	assert := assert.New(t)
+
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+
+	inst, err := NewInstrumentation(meter)
+	assert.NoError(err)
+
+	b := bitarray.NewBitArray(2, bitarray.WithInstrumentation(inst))
+
+	reg, err := Observe(meter, b)
+	assert.NoError(err)
+	defer reg.Unregister()
+
+	b.MarkFree()
+	b.MarkFree()
+	b.MarkFree()
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(reader.Collect(context.Background(), &rm))
+
+	values := map[string]int64{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			values[m.Name] = data.DataPoints[0].Value
+
+		case metricdata.Gauge[int64]:
+			values[m.Name] = data.DataPoints[0].Value
+
+		case metricdata.Histogram[float64]:
+			values[m.Name] = int64(data.DataPoints[0].Count)
+		}
+	}
+
+	assert.Equal(map[string]int64{
+		"bitarray.allocations":      2,
+		"bitarray.exhaustions":      1,
+		"bitarray.markfree.latency": 3,
+		"bitarray.len":              2,
+		"bitarray.cap":              2,
+	}, values)
+}
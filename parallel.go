@@ -0,0 +1,128 @@
+package bitarray
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parMinBlocks is the smallest number of blocks a worker of the parallel
+// operations is given, below which spawning it does not pay off.
+const parMinBlocks = 1 << 12
+
+// ParAnd is like And, but splits the blocks across up to workers goroutines,
+// for arrays of billions of bits. A non-positive number of workers means
+// GOMAXPROCS.
+func (b *BitArray) ParAnd(other *BitArray, workers int) {
+	b.applyPar(other, opAnd, workers)
+}
+
+// ParAndE is like ParAnd but returns ErrCapacityMismatch if the capacities
+// differ under CapacityError.
+func (b *BitArray) ParAndE(other *BitArray, workers int) error {
+	return b.applyPar(other, opAnd, workers)
+}
+
+// ParOr is like Or, but splits the blocks across up to workers goroutines
+// like ParAnd.
+func (b *BitArray) ParOr(other *BitArray, workers int) {
+	b.applyPar(other, opOr, workers)
+}
+
+// ParOrE is like ParOr but returns ErrCapacityMismatch if the capacities
+// differ under CapacityError.
+func (b *BitArray) ParOrE(other *BitArray, workers int) error {
+	return b.applyPar(other, opOr, workers)
+}
+
+// ParCount returns the number of set bits in the half-open range
+// [from, to) like CountRange, counting the blocks on up to workers
+// goroutines like ParAnd.
+func (b *BitArray) ParCount(from, to int64, workers int) int64 {
+	b.rlock()
+	defer b.runlock()
+
+	if from, to = b.clamp(from, to); from >= to {
+		return 0
+	}
+
+	return b.parCount(from, to, workers)
+}
+
+// CountParallel counts the set bits of the whole array from its blocks on
+// up to workers goroutines like ParAnd. Unlike Len64, which returns the
+// maintained count, it reads every block, e.g. to verify the count of a huge
+// array at the memory bandwidth of all cores.
+func (b *BitArray) CountParallel(workers int) int64 {
+	b.rlock()
+	defer b.runlock()
+
+	if b.size == 0 {
+		return 0
+	}
+
+	return b.parCount(0, b.size*blockSize, workers)
+}
+
+// applyPar combines other into b with op on up to workers goroutines,
+// applying the CapacityPolicy of b.
+func (b *BitArray) applyPar(other *BitArray, op bulkOp, workers int) error {
+	if b == other {
+		return nil
+	}
+
+	unlock := lockPair(b, other)
+	defer unlock()
+
+	if err := b.matchCapacity(other); err != nil {
+		return err
+	}
+
+	b.combine(other, op, workers)
+
+	return nil
+}
+
+// parCount counts the set bits of [from, to) on up to workers goroutines.
+// Callers hold the read lock.
+func (b *BitArray) parCount(from, to int64, workers int) int64 {
+	var n atomic.Int64
+
+	first := from / blockSize
+
+	parallel((to-1)/blockSize-first+1, workers, func(lo, hi int64) {
+		lo = max(from, (first+lo)*blockSize)
+		hi = min(to, (first+hi)*blockSize)
+		n.Add(b.scanRange(lo, hi))
+	})
+
+	return n.Load()
+}
+
+// parallel splits the range [0, n) into parts of at least parMinBlocks,
+// calls fn for each part on up to workers goroutines and waits for them. A
+// non-positive number of workers means GOMAXPROCS.
+func parallel(n int64, workers int, fn func(lo, hi int64)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	parts := min(int64(workers), n/parMinBlocks)
+	if parts <= 1 {
+		fn(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	for k := int64(0); k < parts; k++ {
+		wg.Add(1)
+
+		go func(lo, hi int64) {
+			defer wg.Done()
+			fn(lo, hi)
+		}(n*k/parts, n*(k+1)/parts)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package bitarray
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func randomArray(rng *rand.Rand, capacity, n int64) *BitArray {
+	b := NewBitArray(capacity)
+	for ; n > 0; n-- {
+		b.Mark(rng.Int64N(capacity))
+	}
+
+	return b
+}
+
+func TestBitArrayParallel(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(5, 6))
+	capacity := int64(10*parMinBlocks*blockSize + 77)
+
+	x, y := randomArray(rng, capacity, capacity/3), randomArray(rng, capacity-1000, capacity/3)
+
+	for _, workers := range []int{0, 1, 4, 100} {
+		and, or := x.Freeze().Thaw(), x.Freeze().Thaw()
+		and.ParAnd(y, workers)
+		or.ParOr(y, workers)
+
+		want := x.Freeze().Thaw()
+		want.And(y)
+		assert.Equal(want.String(), and.String())
+		assert.NoError(and.Validate())
+
+		want = x.Freeze().Thaw()
+		want.Or(y)
+		assert.Equal(want.String(), or.String())
+		assert.NoError(or.Validate())
+
+		assert.Equal(x.CountRange(0, capacity), x.ParCount(0, capacity, workers))
+		assert.Equal(x.CountRange(12345, capacity-999), x.ParCount(12345, capacity-999, workers))
+		assert.Equal(x.CountRange(100, 101), x.ParCount(100, 101, workers))
+		assert.Equal(int64(0), x.ParCount(50, 50, workers))
+		assert.Equal(x.Len64(), x.CountParallel(workers))
+	}
+
+	assert.Zero(NewBitArray(0).CountParallel(4))
+}
+
+func TestBitArrayParallelCapacityError(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithCapacityPolicy(CapacityError))
+	b.MarkAll(1, 2)
+
+	assert.True(errors.Is(b.ParAndE(newMarked(200, 1), 4), ErrCapacityMismatch))
+	assert.True(errors.Is(b.ParOrE(newMarked(200, 3), 4), ErrCapacityMismatch))
+	assert.Equal("1-2", b.FormatRanges())
+
+	assert.NoError(b.ParAndE(newMarked(100, 1), 4))
+	assert.NoError(b.ParOrE(newMarked(100, 3), 4))
+	assert.Equal("1,3", b.FormatRanges())
+}
+
+func BenchmarkCountParallel(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	x := randomArray(rng, 1<<26, 1<<20)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.CountParallel(0)
+	}
+}
+
+func BenchmarkParOr(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	x, y := randomArray(rng, 1<<26, 1<<20), randomArray(rng, 1<<26, 1<<20)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.ParOr(y, 0)
+	}
+}
@@ -0,0 +1,33 @@
+package bitarray
+
+import "math/bits"
+
+// ReduceXor returns the Xor of all the 64-bit words of the array, the words
+// of the binary encoding, computed in one pass over the storage. It is a
+// cheap checksum that is the same on every platform, e.g. to verify pages
+// of a bitmap; unlike a CRC it does not detect reordered words.
+func (b *BitArray) ReduceXor() uint64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.reduceXor()
+}
+
+// Parity reports whether an odd number of bits is set, computed from the
+// storage in one pass rather than from the maintained count, so comparing
+// it with Len64()%2 detects a corrupted count or storage.
+func (b *BitArray) Parity() bool {
+	b.rlock()
+	defer b.runlock()
+
+	return bits.OnesCount64(b.reduceXor())%2 == 1
+}
+
+// reduceXor returns the Xor of the words. Callers hold the read lock.
+func (b *BitArray) reduceXor() (x uint64) {
+	for i, block := range b.blocks[:b.size] {
+		x ^= uint64(block) << ((int64(i) * blockSize) % wordSize)
+	}
+
+	return
+}
@@ -0,0 +1,27 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayReduceXor(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(uint64(0), NewBitArray(0).ReduceXor())
+	assert.False(NewBitArray(0).Parity())
+
+	b := newMarked(200, 0, 33, 64, 97, 130)
+	assert.Equal(uint64(1<<0^1<<33^1<<0^1<<33^1<<2), b.ReduceXor())
+	assert.True(b.Parity())
+
+	b.Unmark(130)
+	assert.Equal(uint64(0), b.ReduceXor())
+	assert.False(b.Parity())
+	assert.Equal(b.Len64()%2 == 1, b.Parity())
+
+	b.Mark(199)
+	assert.Equal(uint64(1<<7), b.ReduceXor())
+	assert.True(b.Parity())
+}
@@ -0,0 +1,35 @@
+package bitarray
+
+import "fmt"
+
+// Permute returns a copy of the array with the bits rearranged by perm: the
+// bit at index i moves to index perm[i]. perm must be a permutation of the
+// indexes below the capacity, otherwise Permute panics.
+func (b *BitArray) Permute(perm []int64) *BitArray {
+	b.rlock()
+	defer b.runlock()
+
+	capacity := b.capacity.Get64()
+	if int64(len(perm)) != capacity {
+		panic(fmt.Errorf("bitarray: permutation of %d indexes for capacity %d", len(perm), capacity))
+	}
+
+	dst := NewBitArray(capacity)
+
+	// the set bits of seen are the targets taken so far
+	seen := NewBitArray(capacity, WithLockStrategy(LockNone))
+
+	for i, p := range perm {
+		if p < 0 || p >= capacity || !seen.blocks[p/blockSize].compareAndMark(p%blockSize) {
+			panic(fmt.Errorf("bitarray: perm[%d] = %d is not a permutation", i, p))
+		}
+
+		if b.bit(int64(i)) {
+			dst.blocks[p/blockSize].mark(p % blockSize)
+		}
+	}
+
+	dst.count.Set64(b.count.Get64())
+
+	return dst
+}
@@ -0,0 +1,23 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayPermute(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(5, 0, 3)
+	p := b.Permute([]int64{4, 2, 0, 1, 3})
+	assert.Equal("1,4", p.FormatRanges())
+	assert.Equal(2, p.Len())
+	assert.NoError(p.Validate())
+
+	assert.Panics(func() { b.Permute([]int64{0, 1, 2}) })
+	assert.Panics(func() { b.Permute([]int64{0, 1, 2, 3, 5}) })
+	assert.Panics(func() { b.Permute([]int64{0, 1, 1, 3, 4}) })
+
+	assert.Equal(0, NewBitArray(0).Permute(nil).Cap())
+}
@@ -0,0 +1,111 @@
+package bitarray
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// The binary format of the PostgreSQL bit varying type, as sent by varbit_send
+// and used by the extended protocol of drivers like pgx, consists of
+//
+//	length int32   big-endian, the number of bits
+//	bits   []byte  ceil(length/8) bytes, bit i of the array is bit 7-i%8 of
+//	               byte i/8, the padding bits of the last byte are clear
+//
+// and the text format is a string of '0' and '1' characters, the first bit
+// first, which is the format of String and Parse.
+const varbitHeaderLen = 4
+
+// Value implements the driver.Valuer interface, so the array can be stored
+// in a bit varying column. It returns the text format.
+func (b *BitArray) Value() (driver.Value, error) {
+	return b.String(), nil
+}
+
+// Scan implements the sql.Scanner interface for a bit varying column in the
+// text format, received as a string or a []byte. It replaces the contents
+// and the capacity of the array; a NULL makes it empty.
+func (b *BitArray) Scan(src any) error {
+	var s string
+
+	switch v := src.(type) {
+	case string:
+		s = v
+
+	case []byte:
+		s = string(v)
+
+	case nil:
+		// NULL
+
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrFormat, src)
+	}
+
+	words := make([]uint64, wordCount(int64(len(s))))
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '1':
+			words[i/wordSize] |= 1 << (i % wordSize)
+
+		case '0':
+			// bits are clear by default
+
+		default:
+			return fmt.Errorf("%w: unexpected %q at position %d", ErrSyntax, s[i], i)
+		}
+	}
+
+	return b.load(int64(len(s)), words)
+}
+
+// MarshalVarbit returns the bits of the array in the binary format of the
+// PostgreSQL bit varying type. It fails for arrays with more bits than the
+// format can hold.
+func (b *BitArray) MarshalVarbit() ([]byte, error) {
+	b.rlock()
+	defer b.runlock()
+
+	capacity := b.capacity.Get64()
+	if capacity > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: capacity %d exceeds bit varying", ErrOutOfRange, capacity)
+	}
+
+	data := make([]byte, varbitHeaderLen+(capacity+7)/8)
+	binary.BigEndian.PutUint32(data, uint32(capacity))
+
+	for n := range data[varbitHeaderLen:] {
+		i := int64(n) * 8
+		data[varbitHeaderLen+n] = bits.Reverse8(uint8(b.word(i/wordSize) >> (i % wordSize)))
+	}
+
+	return data, nil
+}
+
+// UnmarshalVarbit replaces the contents and the capacity of the array with
+// the bits in the binary format of the PostgreSQL bit varying type.
+func (b *BitArray) UnmarshalVarbit(data []byte) error {
+	if len(data) < varbitHeaderLen {
+		return fmt.Errorf("%w: %d bytes is too short", ErrFormat, len(data))
+	}
+
+	length := int32(binary.BigEndian.Uint32(data))
+	payload := data[varbitHeaderLen:]
+
+	if length < 0 || len(payload) != (int(length)+7)/8 {
+		return fmt.Errorf("%w: %d bytes of bits for length %d", ErrFormat, len(payload), length)
+	}
+
+	words := make([]uint64, wordCount(int64(length)))
+
+	for n, v := range payload {
+		i := n * 8
+		words[i/wordSize] |= uint64(bits.Reverse8(v)) << (i % wordSize)
+	}
+
+	return b.load(int64(length), words)
+}
@@ -0,0 +1,66 @@
+package bitarray
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ sql.Scanner   = (*BitArray)(nil)
+	_ driver.Valuer = (*BitArray)(nil)
+)
+
+func TestBitArrayVarbitText(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := newMarked(5, 0, 3).Value()
+	assert.NoError(err)
+	assert.Equal("10010", v)
+
+	b := newMarked(100, 50)
+	assert.NoError(b.Scan("0110"))
+	assert.Equal(4, b.Cap())
+	assert.Equal("1-2", b.FormatRanges())
+
+	assert.NoError(b.Scan([]byte("1")))
+	assert.Equal("0", b.FormatRanges())
+
+	assert.NoError(b.Scan(nil))
+	assert.Equal(0, b.Cap())
+
+	assert.True(errors.Is(b.Scan("012"), ErrSyntax))
+	assert.True(errors.Is(b.Scan(42), ErrFormat))
+	assert.NoError(b.Validate())
+}
+
+func TestBitArrayVarbitBinary(t *testing.T) {
+	assert := assert.New(t)
+
+	data, err := newMarked(10, 0, 2, 9).MarshalVarbit()
+	assert.NoError(err)
+	assert.Equal([]byte{0, 0, 0, 10, 0xa0, 0x40}, data)
+
+	b := NewBitArray(0)
+	assert.NoError(b.UnmarshalVarbit(data))
+	assert.Equal(10, b.Cap())
+	assert.Equal("0,2,9", b.FormatRanges())
+
+	data, err = newMarked(130, 1, 64, 129).MarshalVarbit()
+	assert.NoError(err)
+	assert.NoError(b.UnmarshalVarbit(data))
+	assert.Equal("1,64,129", b.FormatRanges())
+	assert.NoError(b.Validate())
+
+	data, err = NewBitArray(0).MarshalVarbit()
+	assert.NoError(err)
+	assert.Equal([]byte{0, 0, 0, 0}, data)
+
+	assert.True(errors.Is(b.UnmarshalVarbit([]byte{0, 0}), ErrFormat))
+	assert.True(errors.Is(b.UnmarshalVarbit([]byte{0, 0, 0, 9, 0}), ErrFormat))
+	assert.True(errors.Is(b.UnmarshalVarbit([]byte{0, 0, 0, 4, 0x08}), ErrFormat))
+	assert.True(errors.Is(b.UnmarshalVarbit([]byte{0xff, 0, 0, 0}), ErrFormat))
+}
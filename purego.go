@@ -0,0 +1,33 @@
+//go:build purego
+
+// The purego build tag builds the package without unsafe and assembly, for
+// TinyGo and WebAssembly.
+
+package bitarray
+
+import "reflect"
+
+// overlay returns the number of blocks common to x and y. The blocks are
+// never overlaid by words.
+func overlay(x, y []BitBlock) (n, w int) {
+	return min(len(x), len(y)), 0
+}
+
+func asWords(blocks []BitBlock, w int) []uint64 {
+	panic("bitarray: no word overlay in the purego build")
+}
+
+// asBlocks returns nil, the blocks are never overlaid on words.
+func asBlocks(words []uint64) []BitBlock {
+	return nil
+}
+
+// blocksAddr returns the address of the first block.
+func blocksAddr(blocks []BitBlock) uintptr {
+	return reflect.ValueOf(blocks).Pointer()
+}
+
+// addr returns the address of b, which orders the locks of several arrays.
+func addr(b *BitArray) uintptr {
+	return reflect.ValueOf(b).Pointer()
+}
@@ -0,0 +1,96 @@
+package bitarray
+
+import "math/rand/v2"
+
+// RandomSet returns the index of a set bit chosen uniformly at random by
+// rng, or BitBlockNotFound if no bit is set. A nil rng means the global
+// source of math/rand/v2.
+func (b *BitArray) RandomSet(rng *rand.Rand) int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.out(b.random(rng, false))
+}
+
+// RandomClear returns the index of a clear bit below the capacity chosen
+// uniformly at random by rng, or BitBlockNotFound if the array is full. Like
+// MarkFree, it skips the reserved and excluded ranges. A nil rng means the
+// global source of math/rand/v2.
+func (b *BitArray) RandomClear(rng *rand.Rand) int64 {
+	b.rlock()
+	defer b.runlock()
+
+	return b.out(b.random(rng, true))
+}
+
+// MarkFreeRandom finds a clear bit chosen uniformly at random by rng and
+// sets it to true, spreading the allocations over the array instead of
+// handing out the lowest free index. Returns the index of the bit, or
+// BitBlockNotFound if the array is full. A nil rng means the global source
+// of math/rand/v2.
+func (b *BitArray) MarkFreeRandom(rng *rand.Rand) int64 {
+	return b.limited(func() int64 {
+		return b.allocateRandom(rng)
+	})
+}
+
+func (b *BitArray) allocateRandom(rng *rand.Rand) (index int64) {
+	index = BitBlockNotFound
+
+	if !b.HasRoom() { // fast check w/o lock
+		return
+	}
+
+	b.lock()
+	defer b.unlock()
+
+	if index = b.random(rng, true); index != BitBlockNotFound {
+		b.claim(index)
+	}
+
+	return
+}
+
+// random returns the index of a random set bit, or a random free bit
+// outside the fence if free is set. Callers hold the read lock.
+func (b *BitArray) random(rng *rand.Rand, free bool) int64 {
+	n := b.count.Get64()
+	if free {
+		n = b.allocatable() // nth skips the fence
+	}
+
+	if n <= 0 {
+		return BitBlockNotFound
+	}
+
+	if rng == nil {
+		return b.nth(rand.Int64N(n), free)
+	}
+
+	return b.nth(rng.Int64N(n), free)
+}
+
+// nth returns the index of the k-th set bit, or the k-th free bit if free
+// is set, counting from 0, or BitBlockNotFound if there are not as many.
+// Whole blocks are skipped by their popcounts. Callers hold the read lock.
+func (b *BitArray) nth(k int64, free bool) int64 {
+	for i := int64(0); i < b.size; i++ {
+		block := b.blocks[i]
+		if free {
+			block = ^b.occupied(i)
+		}
+
+		if n := block.popcount(); k >= n {
+			k -= n
+			continue
+		}
+
+		for ; k > 0; k-- {
+			block &= block - 1
+		}
+
+		return (i * blockSize) + block.ffs()
+	}
+
+	return BitBlockNotFound
+}
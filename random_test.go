@@ -0,0 +1,103 @@
+package bitarray
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayRandomSet(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	b := newMarked(300, 3, 64, 130, 299)
+
+	hits := make(map[int64]int)
+	for n := 0; n < 4000; n++ {
+		hits[b.RandomSet(rng)]++
+	}
+
+	assert.Len(hits, 4)
+	for _, index := range []int64{3, 64, 130, 299} {
+		assert.InDelta(1000, hits[index], 150, "index %d", index)
+	}
+
+	assert.True(b.Get(b.RandomSet(nil)))
+	assert.Equal(int64(BitBlockNotFound), NewBitArray(100).RandomSet(rng))
+}
+
+func TestBitArrayRandomClear(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	b := NewBitArrayFull(300)
+	b.Unmark(5)
+	b.Unmark(299)
+
+	hits := make(map[int64]int)
+	for n := 0; n < 1000; n++ {
+		hits[b.RandomClear(rng)]++
+	}
+
+	assert.Len(hits, 2)
+	assert.InDelta(500, hits[5], 100)
+
+	b.Mark(5)
+	b.Mark(299)
+	assert.Equal(int64(BitBlockNotFound), b.RandomClear(rng))
+
+	seen := make(map[int64]bool)
+	for b = NewBitArray(100); len(seen) < 100; {
+		index := b.RandomClear(rng)
+		assert.True(index >= 0 && index < 100)
+		seen[index] = true
+	}
+}
+
+func TestBitArrayRandomClearFenced(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	b := NewBitArray(200, WithReservedRanges(Range{0, 64}))
+	b.Exclude(64, 190)
+
+	hits := make(map[int64]int)
+	for n := 0; n < 2000; n++ {
+		hits[b.RandomClear(rng)]++
+	}
+
+	assert.Len(hits, 10)
+	for index := int64(190); index < 200; index++ {
+		assert.InDelta(200, hits[index], 80, "index %d", index)
+	}
+
+	for b.MarkFreeRandom(rng) != BitBlockNotFound {
+	}
+
+	assert.Equal(64+10, b.Len())
+	assert.Equal(int64(BitBlockNotFound), b.RandomClear(rng))
+	assert.NoError(b.Validate())
+}
+
+func TestBitArrayMarkFreeRandom(t *testing.T) {
+	assert := assert.New(t)
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	b := NewBitArray(100, WithLeakTracking())
+
+	var indexes []int64
+	for b.HasRoom() {
+		indexes = append(indexes, b.MarkFreeRandom(rng))
+	}
+
+	assert.Len(indexes, 100)
+	assert.NotEqual(int64(0), indexes[0])
+	assert.Equal(100, b.Len())
+	assert.Equal(int64(BitBlockNotFound), b.MarkFreeRandom(rng))
+	assert.NoError(b.Validate())
+
+	held := b.HeldLongerThan(0)
+	assert.Len(held, 100)
+	assert.Contains(held[0].Caller, "TestBitArrayMarkFreeRandom")
+}
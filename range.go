@@ -0,0 +1,136 @@
+package bitarray
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// RangePolicy defines how a BitArray treats indexes beyond its capacity.
+type RangePolicy int
+
+const (
+	// RangeError reports ErrOutOfRange from the E-variants of the methods.
+	// Methods without an error result treat such indexes as a no-op.
+	RangeError RangePolicy = iota
+
+	// RangeIgnore silently ignores out-of-range indexes in all methods,
+	// including the E-variants.
+	RangeIgnore
+
+	// RangePanic panics with an error wrapping ErrOutOfRange, for negative
+	// indexes as well.
+	RangePanic
+
+	// RangeGrow extends the capacity to fit the index on writes setting a
+	// bit. Clearing an out-of-range index is a no-op, and reads of such
+	// indexes report false.
+	RangeGrow
+)
+
+// Grow increases the capacity by n bits. It panics with an error wrapping
+// ErrCapacity if the capacity would exceed MaxCapacity.
+func (b *BitArray) Grow(n int64) {
+	if n <= 0 {
+		return
+	}
+
+	b.lock()
+	defer b.unlock()
+
+	if n > MaxCapacity-b.capacity.Get64() {
+		panic(fmt.Errorf("%w: growing %d by %d", ErrCapacity, b.capacity.Get64(), n))
+	}
+
+	b.grow(b.capacity.Get64() + n)
+}
+
+// grow extends the capacity to the specified value. Callers hold the write
+// lock.
+func (b *BitArray) grow(capacity int64) {
+	if capacity <= b.capacity.Get64() {
+		return
+	}
+
+	if size := (capacity / blockSize) + 1; size > b.size {
+		if size > int64(cap(b.blocks)) {
+			n := min(2*int64(cap(b.blocks)), MaxCapacity/blockSize+1)
+			if n < size {
+				n = size
+			}
+
+			blocks := b.alloc(n)
+			copy(blocks, b.blocks)
+			b.blocks = blocks
+		}
+
+		b.blocks = b.blocks[:size]
+		b.size = size
+	}
+
+	b.capacity.Set64(capacity)
+
+	if b.counts != nil {
+		b.growCache()
+	}
+
+	if b.journal != nil {
+		b.journal.grow(capacity)
+	}
+
+	if b.logger != nil {
+		b.trace("bitarray: grow", slog.Int64("capacity", capacity))
+	}
+}
+
+// checkIndex applies the range policy to the index and reports whether it
+// addresses a bit of the array. Only writes setting the bit pass write, so
+// RangeGrow does not grow the array to clear bits that are clear anyway.
+// Negative indexes are always rejected with ErrNegativeIndex, unless the
+// policy is RangePanic.
+func (b *BitArray) checkIndex(index int64, write bool) (ok bool, err error) {
+	switch {
+	case index < 0:
+		if b.policy == RangePanic {
+			panic(fmt.Errorf("%w: %d", ErrNegativeIndex, index))
+		}
+
+		err = ErrNegativeIndex
+
+	case index >= b.capacity.Get64():
+		if err = b.outOfRange(index, write); err == nil {
+			ok = index < b.capacity.Get64()
+		}
+
+	default:
+		ok = true
+	}
+
+	return
+}
+
+// outOfRange applies the range policy to an index beyond the capacity.
+// Writes may grow the array, so callers hold the write lock when write is
+// true.
+func (b *BitArray) outOfRange(index int64, write bool) error {
+	switch b.policy {
+	case RangeIgnore:
+		return nil
+
+	case RangePanic:
+		panic(fmt.Errorf("%w: %d with capacity %d", ErrOutOfRange, index, b.capacity.Get64()))
+
+	case RangeGrow:
+		if write && index >= MaxCapacity {
+			return fmt.Errorf("%w: %d", ErrCapacity, index)
+		}
+
+		if write {
+			b.grow(index + 1)
+		}
+
+		return nil
+
+	default:
+		return ErrOutOfRange
+	}
+}
@@ -0,0 +1,94 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayRangeIgnore(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10, WithRangePolicy(RangeIgnore))
+
+	assert.NoError(b.MarkE(100))
+	res, err := b.GetE(100)
+	assert.NoError(err)
+	assert.False(res)
+	assert.Zero(b.Len())
+}
+
+func TestBitArrayRangePanic(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10, WithRangePolicy(RangePanic))
+
+	assert.Panics(func() { b.Mark(10) })
+
+	defer func() {
+		err, _ := recover().(error)
+		assert.True(errors.Is(err, ErrOutOfRange))
+	}()
+
+	b.Get(10)
+}
+
+func TestBitArrayRangeGrow(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10, WithRangePolicy(RangeGrow))
+
+	assert.False(b.Get(1000))
+	assert.Equal(10, b.Cap())
+
+	assert.NoError(b.MarkE(1000))
+	assert.True(b.Get(1000))
+	assert.Equal(1001, b.Cap())
+	assert.Equal(1, b.Len())
+
+	changed, err := b.SetE(5000, bitBlockUnmark)
+	assert.False(changed)
+	assert.NoError(err)
+	b.Unmark(6000)
+	assert.Equal(0, b.UnmarkMany([]int64{7000, 8000}))
+	assert.Equal(1, b.Len())
+	assert.Equal(1001, b.Cap())
+}
+
+func TestBitArrayGrow(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(64)
+	b.Mark(63)
+
+	b.Grow(64)
+	assert.Equal(128, b.Cap())
+	assert.True(b.Get(63))
+
+	b.Mark(127)
+	assert.True(b.Get(127))
+	assert.Equal(2, b.Len())
+}
+
+func TestBitArrayNegativeIndex(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, policy := range []RangePolicy{RangeError, RangeIgnore, RangeGrow} {
+		b := NewBitArray(10, WithRangePolicy(policy))
+
+		assert.False(b.Set(-1, true))
+		assert.False(b.Get(-65))
+		assert.Equal(ErrNegativeIndex, b.MarkE(-1))
+		assert.Equal(ErrNegativeIndex, b.UnmarkE(-100))
+
+		_, err := b.GetE(-1)
+		assert.True(errors.Is(err, ErrOutOfRange))
+		assert.Zero(b.Len())
+		assert.Equal(10, b.Cap())
+	}
+
+	b := NewBitArray(10, WithRangePolicy(RangePanic))
+	assert.Panics(func() { b.Mark(-1) })
+	assert.Panics(func() { b.Get(-1) })
+}
@@ -0,0 +1,108 @@
+package bitarray
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitMode defines how a rate-limited BitArray treats the allocations
+// in excess of the rate.
+type RateLimitMode int
+
+const (
+	// RateLimitWait blocks the allocation until the rate allows it.
+	RateLimitWait RateLimitMode = iota
+
+	// RateLimitFail fails the allocation with BitBlockNotFound.
+	RateLimitFail
+)
+
+// WithRateLimit throttles MarkFree and MarkFreeRandom to perSecond
+// allocations per second on average with bursts of up to burst allocations,
+// using a token bucket that starts full. The allocations in excess wait or
+// fail according to mode. The calls that find no free bit do not use up the
+// rate. AcquireN and Tx.Reserve are not throttled. A non-positive rate
+// disables the limit.
+func WithRateLimit(perSecond float64, burst int, mode RateLimitMode) Option {
+	return func(c *config) {
+		if perSecond <= 0 {
+			c.limiter = nil
+			return
+		}
+
+		c.limiter = &limiter{
+			rate:   perSecond,
+			burst:  float64(max(burst, 1)),
+			tokens: float64(max(burst, 1)),
+			last:   time.Now(),
+			mode:   mode,
+		}
+	}
+}
+
+// limiter is a token bucket. Waiting allocations reserve their token in
+// advance, so the number of tokens may be negative.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mode   RateLimitMode
+}
+
+// take takes a token, waiting for it unless the mode is RateLimitFail, and
+// reports whether it got one.
+func (l *limiter) take() bool {
+	l.mu.Lock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	if l.tokens < 1 && l.mode == RateLimitFail {
+		l.mu.Unlock()
+		return false
+	}
+
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+
+	return true
+}
+
+// put returns a token taken for an allocation that failed.
+func (l *limiter) put() {
+	l.mu.Lock()
+	l.tokens = min(l.burst, l.tokens+1)
+	l.mu.Unlock()
+}
+
+// limited runs allocate like instrumented under the rate limit. A token is
+// taken only if the array has room, and is returned if the allocation fails
+// anyway, so the failing calls neither wait nor use up the rate.
+func (b *BitArray) limited(allocate func() int64) int64 {
+	if b.limiter == nil || !b.HasRoom() {
+		return b.instrumented(allocate)
+	}
+
+	if !b.limiter.take() {
+		if b.tally != nil {
+			b.tally.failed.Add(1)
+		}
+
+		return BitBlockNotFound
+	}
+
+	index := b.instrumented(allocate)
+	if index == BitBlockNotFound {
+		b.limiter.put()
+	}
+
+	return index
+}
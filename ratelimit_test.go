@@ -0,0 +1,65 @@
+package bitarray
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRateLimitFail(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithRateLimit(1, 2, RateLimitFail))
+	assert.Equal(int64(0), b.MarkFree())
+	assert.NotEqual(int64(BitBlockNotFound), b.MarkFreeRandom(nil))
+	assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+	assert.Equal(2, b.Len())
+
+	b.Unmark(0) // not limited
+	assert.Equal(1, b.Len())
+}
+
+func TestWithRateLimitWait(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithRateLimit(100, 1, RateLimitWait))
+
+	start := time.Now()
+	for n := 0; n < 5; n++ {
+		assert.Equal(int64(n), b.MarkFree())
+	}
+
+	assert.True(time.Since(start) >= 35*time.Millisecond)
+}
+
+func TestWithRateLimitFull(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(1, WithRateLimit(10, 1, RateLimitWait))
+	assert.Equal(int64(0), b.MarkFree()) // the burst
+
+	start := time.Now()
+	for range 5 {
+		assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+		assert.Equal(int64(BitBlockNotFound), b.MarkFreeRandom(nil))
+	}
+
+	assert.True(time.Since(start) < 50*time.Millisecond) // no waits for nothing
+
+	b = NewBitArray(1, WithRateLimit(1, 1, RateLimitFail))
+	b.Mark(0)
+	assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+	b.Unmark(0)
+	assert.Equal(int64(0), b.MarkFree()) // the token is still there
+}
+
+func TestWithRateLimitDisabled(t *testing.T) {
+	b := NewBitArray(100, WithRateLimit(0, 1, RateLimitFail))
+
+	for n := 0; n < 100; n++ {
+		b.MarkFree()
+	}
+
+	assert.Equal(t, 100, b.Len())
+}
@@ -0,0 +1,139 @@
+package bitarray
+
+import (
+	"iter"
+	"slices"
+	"sync/atomic"
+)
+
+// ReadTxn is a consistent view of a BitArray for a sequence of reads, e.g.
+// a Get followed by a CountRange and an iteration, that writers interleaved
+// with the reads do not affect. It is created by BeginRead and must be
+// closed by Close. The view shares the storage of the array until the next
+// write, which copies the storage first, so opening a ReadTxn is cheap and
+// the first write after it pays for one copy. A ReadTxn may be read by
+// several goroutines.
+type ReadTxn struct {
+	b      *BitArray
+	view   *BitArray
+	shares uint64
+	closed *atomic.Bool
+}
+
+// BeginRead opens a ReadTxn pinning the current state of the array.
+func (b *BitArray) BeginRead() ReadTxn {
+	b.rlock()
+	defer b.runlock()
+
+	view := &BitArray{
+		blocks:  b.blocks[:b.size:b.size],
+		size:    b.size,
+		locking: LockNone,
+		counts:  b.counts,
+		base:    b.base,
+	}
+	view.capacity.Set64(b.capacity.Get64())
+	view.count.Set64(b.count.Get64())
+
+	b.pins.Inc()
+
+	return ReadTxn{b: b, view: view, shares: b.shares, closed: new(atomic.Bool)}
+}
+
+// Close releases the view, so later writes do not copy the storage on its
+// account. The ReadTxn must not be used after Close. Calling Close again
+// has no effect.
+func (t ReadTxn) Close() {
+	if t.closed.Swap(true) {
+		return
+	}
+
+	t.b.rlock()
+	defer t.b.runlock()
+
+	if t.shares == t.b.shares { // a write has not copied the storage yet
+		t.b.pins.Dec()
+	}
+}
+
+// unshare replaces the storage pinned by open ReadTxns with a copy before a
+// write. Callers hold the write lock.
+func (b *BitArray) unshare() {
+	blocks := b.alloc(b.size)
+	copy(blocks, b.blocks[:b.size])
+	b.blocks = blocks
+
+	if b.counts != nil {
+		b.counts = slices.Clone(b.counts)
+	}
+
+	b.pins.Set64(0)
+	b.shares++
+}
+
+// Get returns the value of the bit at the specified index in the view.
+// Indexes out of range are reported as false.
+func (t ReadTxn) Get(index int64) bool {
+	return t.view.Get(index)
+}
+
+// Len returns the number of set bits in the view.
+func (t ReadTxn) Len() int {
+	return t.view.Len()
+}
+
+// Len64 returns the number of set bits in the view.
+func (t ReadTxn) Len64() int64 {
+	return t.view.Len64()
+}
+
+// Cap returns the capacity of the view.
+func (t ReadTxn) Cap() int {
+	return t.view.Cap()
+}
+
+// Cap64 returns the capacity of the view.
+func (t ReadTxn) Cap64() int64 {
+	return t.view.Cap64()
+}
+
+// CountRange returns the number of set bits of the view in the half-open
+// range [from, to).
+func (t ReadTxn) CountRange(from, to int64) int64 {
+	return t.view.CountRange(from, to)
+}
+
+// First returns the index of the first set bit of the view, or
+// BitBlockNotFound if no bit is set.
+func (t ReadTxn) First() int64 {
+	return t.view.First()
+}
+
+// Last returns the index of the last set bit of the view, or
+// BitBlockNotFound if no bit is set.
+func (t ReadTxn) Last() int64 {
+	return t.view.Last()
+}
+
+// ForEachInRange calls fn for the index of every set bit of the view in the
+// half-open range [from, to) in ascending order until fn returns false.
+func (t ReadTxn) ForEachInRange(from, to int64, fn func(index int64) bool) {
+	t.view.ForEachInRange(from, to, fn)
+}
+
+// SetBits returns an iterator over the indexes of the set bits of the view
+// in ascending order.
+func (t ReadTxn) SetBits() iter.Seq[int64] {
+	return t.view.SetBits()
+}
+
+// ClearBits returns an iterator over the indexes of the clear bits of the
+// view below its capacity in ascending order.
+func (t ReadTxn) ClearBits() iter.Seq[int64] {
+	return t.view.ClearBits()
+}
+
+// FormatRanges formats the set bits of the view like BitArray.FormatRanges.
+func (t ReadTxn) FormatRanges() string {
+	return t.view.FormatRanges()
+}
@@ -0,0 +1,89 @@
+package bitarray
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeginRead(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(100, 1, 2, 70)
+
+	r := b.BeginRead()
+	defer r.Close()
+
+	b.Mark(3)
+	b.Unmark(70)
+	b.Grow(200)
+	b.Mark(150)
+
+	assert.True(r.Get(70))
+	assert.False(r.Get(3))
+	assert.Equal(3, r.Len())
+	assert.Equal(100, r.Cap())
+	assert.Equal(int64(2), r.CountRange(0, 64))
+	assert.Equal(int64(1), r.First())
+	assert.Equal(int64(70), r.Last())
+	assert.Equal([]int64{1, 2, 70}, slices.Collect(r.SetBits()))
+	assert.Equal("1-3,150", b.FormatRanges())
+	assert.Equal("1-2,70", r.FormatRanges())
+	assert.NoError(b.Validate())
+}
+
+func TestBeginReadClose(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithCountCache(), WithInitialSet(1))
+
+	r := b.BeginRead()
+	r.Close()
+	r.Close()
+	assert.Equal(int64(0), b.pins.Get64())
+
+	r = b.BeginRead()
+	b.Mark(2)
+	assert.Equal(int64(0), b.pins.Get64()) // copied by the write
+	r.Close()
+	assert.Equal(int64(0), b.pins.Get64())
+
+	r = b.BeginRead()
+	blocks := b.blocks
+	b.Mark(3)
+	b.Mark(4)
+	assert.NotSame(&blocks[0], &b.blocks[0])
+	assert.Equal(int64(2), r.CountRange(0, 100))
+	r.Close()
+	assert.NoError(b.Validate())
+}
+
+func TestBeginReadConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(1000)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for range 1000 {
+			b.MarkFree()
+		}
+	}()
+
+	for range 100 {
+		r := b.BeginRead()
+		n := r.Len64()
+		assert.Equal(n, r.CountRange(0, 1000))
+		assert.Equal(int(n), len(slices.Collect(r.SetBits())))
+		r.Close()
+	}
+
+	wg.Wait()
+	assert.Equal(1000, b.Len())
+}
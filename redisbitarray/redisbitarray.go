@@ -0,0 +1,288 @@
+// Package redisbitarray implements a bit array stored in a Redis string, so
+// several processes can share one bitmap through a Redis server. It mirrors
+// the methods of bitarray.BitArray and speaks the Redis protocol (RESP)
+// itself, without dependencies.
+//
+// Bit i of the array is the Redis bit offset i, as used by SETBIT, GETBIT
+// and BITPOS.
+package redisbitarray
+
+import (
+	"bufio"
+	"fmt"
+	"iter"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aermolaev/bitarray"
+)
+
+// NotFound is returned by MarkFree if there is no free bit.
+const NotFound = bitarray.BitBlockNotFound
+
+// scanChunk is the number of bytes fetched per GETRANGE while iterating.
+const scanChunk = 4096
+
+// BitArray is a bit array of a fixed capacity stored under a key of a Redis
+// server. Every method is a round trip to the server; Set, Mark, Unmark and
+// MarkFree are atomic with respect to the other clients of the key.
+//
+// The methods cannot report I/O errors, so, like bitarray.Durable, the
+// first failure is kept and reported by Err. The connection is unusable
+// after a failure: the operations return zero values from then on.
+type BitArray struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	r        *bufio.Reader
+	w        *bufio.Writer
+	key      string
+	capacity int64
+	timeout  time.Duration
+	hint     int64
+	err      error
+}
+
+// Dial connects to the Redis server at addr and returns the bit array of
+// the specified capacity stored under key. A positive timeout limits every
+// round trip.
+func Dial(addr, key string, capacity int64, timeout time.Duration) (*BitArray, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	b := NewBitArray(conn, key, capacity)
+	b.timeout = timeout
+
+	return b, nil
+}
+
+// NewBitArray returns the bit array of the specified capacity stored under
+// key, talking to the server over conn.
+func NewBitArray(conn net.Conn, key string, capacity int64) *BitArray {
+	if capacity < 0 {
+		panic(fmt.Errorf("redisbitarray: negative capacity %d", capacity))
+	}
+
+	return &BitArray{
+		conn:     conn,
+		r:        bufio.NewReader(conn),
+		w:        bufio.NewWriter(conn),
+		key:      key,
+		capacity: capacity,
+	}
+}
+
+// Close closes the connection. The bits stay on the server.
+func (b *BitArray) Close() error {
+	return b.conn.Close()
+}
+
+// Err returns the first error of the connection.
+func (b *BitArray) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.err
+}
+
+// Cap64 returns the capacity.
+func (b *BitArray) Cap64() int64 {
+	return b.capacity
+}
+
+// Cap returns the capacity.
+func (b *BitArray) Cap() int {
+	return int(b.capacity)
+}
+
+// Len64 returns the number of set bits.
+func (b *BitArray) Len64() int64 {
+	n, _ := b.integer("BITCOUNT", b.key)
+	return n
+}
+
+// Len returns the number of set bits.
+func (b *BitArray) Len() int {
+	return int(b.Len64())
+}
+
+// HasRoom reports whether there are bits that are set to false.
+func (b *BitArray) HasRoom() bool {
+	return b.Len64() < b.capacity
+}
+
+// Get returns the value of the bit with the specified index. Indexes out of
+// range are reported as false.
+func (b *BitArray) Get(index int64) bool {
+	if index < 0 || index >= b.capacity {
+		return false
+	}
+
+	n, _ := b.integer("GETBIT", b.key, strconv.FormatInt(index, 10))
+
+	return n == 1
+}
+
+// Set sets the bit at the specified index to the specified value and
+// reports whether it changed. Indexes out of range are ignored.
+func (b *BitArray) Set(index int64, mark bool) bool {
+	if index < 0 || index >= b.capacity {
+		return false
+	}
+
+	value := "0"
+	if mark {
+		value = "1"
+	}
+
+	old, err := b.integer("SETBIT", b.key, strconv.FormatInt(index, 10), value)
+
+	return err == nil && (old == 1) != mark
+}
+
+// Mark sets the bit at the specified index to true.
+func (b *BitArray) Mark(index int64) {
+	b.Set(index, true)
+}
+
+// Unmark sets the bit at the specified index to false.
+func (b *BitArray) Unmark(index int64) {
+	b.Set(index, false)
+}
+
+// MarkFree finds the index of a bit that is set to false and sets the bit
+// to true. Concurrent clients never get the same index: a bit found by
+// BITPOS is taken only if SETBIT reports that it was clear, otherwise the
+// search goes on. Returns NotFound unless the array has room.
+func (b *BitArray) MarkFree() int64 {
+	b.mu.Lock()
+	start := b.hint / 8
+	b.mu.Unlock()
+
+	for wrapped := start == 0; ; {
+		pos, err := b.integer("BITPOS", b.key, "0", strconv.FormatInt(start, 10))
+		if err != nil {
+			return NotFound
+		}
+
+		if pos < 0 || pos >= b.capacity {
+			if wrapped {
+				return NotFound
+			}
+
+			start, wrapped = 0, true
+
+			continue
+		}
+
+		old, err := b.integer("SETBIT", b.key, strconv.FormatInt(pos, 10), "1")
+		if err != nil {
+			return NotFound
+		}
+
+		if old == 0 {
+			b.mu.Lock()
+			b.hint = pos
+			b.mu.Unlock()
+
+			return pos
+		}
+
+		start = pos / 8
+	}
+}
+
+// Reset deletes the key, clearing all the bits.
+func (b *BitArray) Reset() {
+	b.do("DEL", b.key)
+}
+
+// SetBits returns an iterator over the indexes of the set bits in
+// ascending order. The bits are fetched in chunks, so the iteration is not
+// atomic with respect to the other clients.
+func (b *BitArray) SetBits() iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		size := (b.capacity + 7) / 8
+
+		for off := int64(0); off < size; off += scanChunk {
+			v, err := b.do("GETRANGE", b.key, strconv.FormatInt(off, 10), strconv.FormatInt(min(off+scanChunk, size)-1, 10))
+			s, ok := v.(string)
+
+			if err != nil || !ok {
+				return
+			}
+
+			for k := 0; k < len(s); k++ {
+				for m := int64(0); m < 8 && s[k] != 0; m++ {
+					if s[k]&(0x80>>m) == 0 {
+						continue
+					}
+
+					if index := (off+int64(k))*8 + m; index >= b.capacity || !yield(index) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+var _ bitarray.BitSet = (*BitArray)(nil)
+
+// integer runs a command replying with an integer.
+func (b *BitArray) integer(args ...string) (int64, error) {
+	v, err := b.do(args...)
+	if err != nil {
+		return 0, err
+	}
+
+	n, ok := v.(int64)
+	if !ok {
+		return 0, b.fail(fmt.Errorf("%w: %s replied %v", ErrProtocol, args[0], v))
+	}
+
+	return n, nil
+}
+
+// do runs a command and returns its reply.
+func (b *BitArray) do(args ...string) (any, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	if b.timeout > 0 {
+		b.conn.SetDeadline(time.Now().Add(b.timeout))
+	}
+
+	if err := writeCommand(b.w, args...); err != nil {
+		return nil, b.failLocked(err)
+	}
+
+	v, err := readReply(b.r)
+	if err != nil {
+		return nil, b.failLocked(err)
+	}
+
+	return v, nil
+}
+
+func (b *BitArray) fail(err error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failLocked(err)
+}
+
+func (b *BitArray) failLocked(err error) error {
+	if b.err == nil {
+		b.err = err
+	}
+
+	return err
+}
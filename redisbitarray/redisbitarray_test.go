@@ -0,0 +1,167 @@
+package redisbitarray
+
+import (
+	"bufio"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis serves the string commands used by BitArray from memory.
+type fakeRedis struct {
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+
+	for {
+		v, err := readReply(r)
+		if err != nil {
+			return
+		}
+
+		var args []string
+		for _, a := range v.([]any) {
+			args = append(args, a.(string))
+		}
+
+		w.WriteString(f.exec(args))
+		w.Flush()
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	num := func(i int) int64 {
+		n, _ := strconv.ParseInt(args[i], 10, 64)
+		return n
+	}
+	integer := func(n int64) string {
+		return ":" + strconv.FormatInt(n, 10) + "\r\n"
+	}
+	s := f.keys[args[1]]
+
+	switch args[0] {
+	case "GETBIT":
+		if i := num(2); i/8 < int64(len(s)) {
+			return integer(int64(s[i/8]>>(7-i%8)) & 1)
+		}
+
+		return integer(0)
+
+	case "SETBIT":
+		i := num(2)
+		for int64(len(s)) <= i/8 {
+			s = append(s, 0)
+		}
+
+		old := int64(s[i/8]>>(7-i%8)) & 1
+		s[i/8] &^= 0x80 >> (i % 8)
+		s[i/8] |= byte(num(3)) << (7 - i%8)
+		f.keys[args[1]] = s
+
+		return integer(old)
+
+	case "BITCOUNT":
+		var n int64
+		for i := int64(0); i < int64(len(s))*8; i++ {
+			n += int64(s[i/8]>>(7-i%8)) & 1
+		}
+
+		return integer(n)
+
+	case "BITPOS":
+		bit := num(2)
+		for i := num(3) * 8; i < int64(len(s))*8; i++ {
+			if int64(s[i/8]>>(7-i%8))&1 == bit {
+				return integer(i)
+			}
+		}
+
+		if bit == 0 {
+			return integer(max(int64(len(s))*8, num(3)*8))
+		}
+
+		return integer(-1)
+
+	case "GETRANGE":
+		from, to := min(num(2), int64(len(s))), min(num(3)+1, int64(len(s)))
+		return "$" + strconv.Itoa(int(to-from)) + "\r\n" + string(s[from:to]) + "\r\n"
+
+	case "DEL":
+		delete(f.keys, args[1])
+		return integer(1)
+	}
+
+	return "-ERR unknown command\r\n"
+}
+
+func newTestArray(t *testing.T, f *fakeRedis, capacity int64) *BitArray {
+	client, server := net.Pipe()
+	go f.serve(server)
+	t.Cleanup(func() { client.Close() })
+
+	return NewBitArray(client, "bits", capacity)
+}
+
+func TestBitArray(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &fakeRedis{keys: make(map[string][]byte)}
+	b := newTestArray(t, f, 20)
+
+	assert.Equal(20, b.Cap())
+	assert.True(b.Set(3, true))
+	assert.False(b.Set(3, true))
+	assert.True(b.Get(3))
+	assert.False(b.Get(4))
+	assert.False(b.Set(20, true))
+	b.Mark(19)
+	assert.Equal(2, b.Len())
+
+	for i := int64(0); i < 18; i++ {
+		assert.NotEqual(int64(3), b.MarkFree())
+	}
+	assert.False(b.HasRoom())
+	assert.Equal(int64(NotFound), b.MarkFree())
+
+	b.Unmark(7)
+	assert.Equal(int64(7), b.MarkFree())
+
+	b.Unmark(0)
+	b.Unmark(12)
+	assert.Equal([]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 14, 15, 16, 17, 18, 19}, slices.Collect(b.SetBits()))
+
+	// another client of the same key
+	o := newTestArray(t, f, 20)
+	assert.True(o.Get(19))
+	assert.Equal(int64(0), o.MarkFree())
+	assert.Equal(int64(12), b.MarkFree())
+
+	b.Reset()
+	assert.Equal(0, o.Len())
+	assert.NoError(b.Err())
+}
+
+func TestBitArrayErr(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &fakeRedis{keys: make(map[string][]byte)}
+	b := newTestArray(t, f, 20)
+
+	b.conn.Close()
+	b.Mark(1)
+	assert.Error(b.Err())
+	assert.False(b.Get(1))
+	assert.Equal(int64(NotFound), b.MarkFree())
+}
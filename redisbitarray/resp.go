@@ -0,0 +1,117 @@
+package redisbitarray
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ErrProtocol is returned when a reply of the server cannot be parsed.
+var ErrProtocol = errors.New("redisbitarray: protocol error")
+
+// Error is an error reply of the server.
+type Error string
+
+func (e Error) Error() string {
+	return "redisbitarray: " + string(e)
+}
+
+// writeCommand writes a command in the RESP array of bulk strings.
+func writeCommand(w *bufio.Writer, args ...string) error {
+	w.WriteByte('*')
+	w.WriteString(strconv.Itoa(len(args)))
+	w.WriteString("\r\n")
+
+	for _, arg := range args {
+		w.WriteByte('$')
+		w.WriteString(strconv.Itoa(len(arg)))
+		w.WriteString("\r\n")
+		w.WriteString(arg)
+		w.WriteString("\r\n")
+	}
+
+	return w.Flush()
+}
+
+// readReply reads a reply: an int64 for integers, a string for simple and
+// bulk strings, nil for a null bulk string and []any for arrays. Error
+// replies are returned as an Error.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, fmt.Errorf("%w: empty reply", ErrProtocol)
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+
+	case '-':
+		return nil, Error(line[1:])
+
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad integer %q", ErrProtocol, line)
+		}
+
+		return n, nil
+
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("%w: bad length %q", ErrProtocol, line)
+		}
+
+		if n == -1 {
+			return nil, nil
+		}
+
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:n]), nil
+
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("%w: bad length %q", ErrProtocol, line)
+		}
+
+		if n == -1 {
+			return nil, nil
+		}
+
+		v := make([]any, n)
+		for i := range v {
+			if v[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("%w: unexpected reply %q", ErrProtocol, line)
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("%w: bad line %q", ErrProtocol, line)
+	}
+
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,198 @@
+package bitarray
+
+import (
+	"slices"
+	"sort"
+)
+
+// Range is the half-open range of indexes [From, To).
+type Range struct {
+	From, To int64
+}
+
+// WithPreferredRanges makes MarkFree allocate from the specified ranges
+// first, in the order of preference, e.g. the slots local to a NUMA node.
+// When the preferred ranges are full, MarkFree falls back to the rest of the
+// array.
+func WithPreferredRanges(ranges ...Range) Option {
+	return func(c *config) {
+		for _, r := range ranges {
+			c.tiers = append(c.tiers, []Range{r})
+		}
+	}
+}
+
+// WithTiers makes MarkFree allocate from the ranges of the first tier until
+// they are full, then from those of the second tier and so on, e.g. cheap
+// slots before premium ones. When all the tiers are full, MarkFree falls
+// back to the rest of the array. The ranges of the tiers should not overlap.
+// WithPreferredRanges adds a tier for every range.
+func WithTiers(tiers ...[]Range) Option {
+	return func(c *config) {
+		c.tiers = append(c.tiers, tiers...)
+	}
+}
+
+// Tiers returns the number of tiers of WithTiers and WithPreferredRanges.
+func (b *BitArray) Tiers() int {
+	return len(b.tiers)
+}
+
+// TierUtilization returns the fraction of the bits of the ranges of the
+// specified tier that is set, within the capacity. It panics if the tier is
+// out of range.
+func (b *BitArray) TierUtilization(tier int) float64 {
+	b.rlock()
+	defer b.runlock()
+
+	var n, size int64
+
+	for _, r := range b.tiers[tier] {
+		from, to := b.clamp(r.From, r.To)
+		if from < to {
+			n += b.countRange(from, to)
+			size += to - from
+		}
+	}
+
+	return ratio(n, size)
+}
+
+// WithReservedRanges marks the specified ranges on construction and after
+// every Reset, and excludes them from MarkFree permanently, even if their
+// bits are cleared, e.g. for well-known ports or broadcast IDs.
+func WithReservedRanges(ranges ...Range) Option {
+	return func(c *config) {
+		c.reserved = append(c.reserved, ranges...)
+	}
+}
+
+// Exclude fences off the half-open range [from, to) from MarkFree, e.g. to
+// drain a shard. The bits in the range keep their values and may still be
+// changed by the other methods.
+func (b *BitArray) Exclude(from, to int64) {
+	b.lock()
+	defer b.unlock()
+
+	b.excluded = b.excluded.add(Range{from, to})
+	b.refence()
+}
+
+// Include lifts the exclusion of the half-open range [from, to) made by
+// Exclude. The reserved ranges stay excluded.
+func (b *BitArray) Include(from, to int64) {
+	b.lock()
+	defer b.unlock()
+
+	b.excluded = b.excluded.remove(Range{from, to})
+	b.refence()
+
+	if i := max(from, 0) / blockSize; i < b.curIndex {
+		b.curIndex = i // the included bits may be free
+	}
+}
+
+// refence recomputes the indexes MarkFree skips. Callers hold the write
+// lock.
+func (b *BitArray) refence() {
+	fence := slices.Clone(b.excluded)
+
+	for _, r := range b.reserved {
+		fence = fence.add(r)
+	}
+
+	b.fence = fence
+}
+
+// markReserved sets the bits of the reserved ranges. Callers hold the write
+// lock.
+func (b *BitArray) markReserved() {
+	for _, r := range b.reserved {
+		b.setRange(r.From, r.To, bitBlockMark)
+	}
+}
+
+// nextPreferred returns the lowest free index of the first range of the
+// first tier that has room, or BitBlockNotFound. Callers hold the read lock.
+func (b *BitArray) nextPreferred() int64 {
+	for _, tier := range b.tiers {
+		for _, r := range tier {
+			if index := b.nextFreeIn(r.From, r.To); index != BitBlockNotFound {
+				return index
+			}
+		}
+	}
+
+	return BitBlockNotFound
+}
+
+// nextFreeIn returns the lowest free index in the half-open range
+// [from, to), or BitBlockNotFound. Callers hold the read lock.
+func (b *BitArray) nextFreeIn(from, to int64) int64 {
+	if from, to = b.clamp(from, to); from >= to {
+		return BitBlockNotFound
+	}
+
+	for i, last := from/blockSize, (to-1)/blockSize; i <= last; i++ {
+		if block := ^b.occupied(i) & rangeMask(i, from, to); block != 0 {
+			return (i * blockSize) + block.ffs()
+		}
+	}
+
+	return BitBlockNotFound
+}
+
+// rangeSet is a sorted list of disjoint ranges that are not adjacent.
+type rangeSet []Range
+
+// add returns the set with the indexes of r added.
+func (s rangeSet) add(r Range) rangeSet {
+	if r.From = max(r.From, 0); r.From >= r.To {
+		return s
+	}
+
+	// the ranges overlapping or adjacent to r are merged into it
+	first := sort.Search(len(s), func(k int) bool { return s[k].To >= r.From })
+	last := first
+
+	for ; last < len(s) && s[last].From <= r.To; last++ {
+		r.From, r.To = min(r.From, s[last].From), max(r.To, s[last].To)
+	}
+
+	return append(s[:first:first], append(rangeSet{r}, s[last:]...)...)
+}
+
+// remove returns the set with the indexes of r removed.
+func (s rangeSet) remove(r Range) rangeSet {
+	var res rangeSet
+
+	for _, x := range s {
+		if x.To <= r.From || x.From >= r.To {
+			res = append(res, x)
+			continue
+		}
+
+		if x.From < r.From {
+			res = append(res, Range{x.From, r.From})
+		}
+
+		if x.To > r.To {
+			res = append(res, Range{r.To, x.To})
+		}
+	}
+
+	return res
+}
+
+// mask returns the bits of block i that lie in the ranges of the set.
+func (s rangeSet) mask(i int64) (m BitBlock) {
+	start := i * blockSize
+
+	k := sort.Search(len(s), func(k int) bool { return s[k].To > start })
+
+	for ; k < len(s) && s[k].From < start+blockSize; k++ {
+		m |= rangeMask(i, s[k].From, s[k].To)
+	}
+
+	return
+}
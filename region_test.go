@@ -0,0 +1,133 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayPreferredRanges(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(300, WithPreferredRanges(Range{200, 203}, Range{100, 102}, Range{290, 400}))
+	b.Mark(201)
+
+	var indexes []int64
+	for n := 0; n < 16; n++ {
+		peek := b.PeekFree()
+		indexes = append(indexes, b.MarkFree())
+		assert.Equal(peek, indexes[n])
+	}
+
+	assert.Equal([]int64{200, 202, 100, 101, 290, 291, 292, 293, 294, 295, 296, 297, 298, 299, 0, 1}, indexes)
+
+	// a freed preferred index is reused first
+	b.Unmark(100)
+	assert.Equal(int64(100), b.MarkFree())
+	assert.Equal(int64(2), b.MarkFree())
+	assert.NoError(b.Validate())
+}
+
+func TestWithReservedRanges(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithReservedRanges(Range{0, 2}, Range{64, 66}, Range{98, 200}))
+	assert.Equal("0-1,64-65,98-99", b.FormatRanges())
+	assert.Equal(6, b.Len())
+	assert.Equal(int64(2), b.MarkFree())
+
+	b.Unmark(0)
+	b.Unmark(65)
+
+	for b.MarkFree() != BitBlockNotFound {
+	}
+
+	assert.False(b.Get(0))
+	assert.False(b.Get(65))
+	assert.Equal(98, b.Len())
+	assert.Equal(int64(BitBlockNotFound), b.MarkFreeRandom(nil))
+
+	b.Reset()
+	assert.Equal("0-1,64-65,98-99", b.FormatRanges())
+	assert.NoError(b.Validate())
+}
+
+func TestRangeSet(t *testing.T) {
+	assert := assert.New(t)
+
+	var s rangeSet
+	s = s.add(Range{10, 20})
+	s = s.add(Range{30, 40})
+	s = s.add(Range{-5, 2})
+	s = s.add(Range{5, 5})
+	assert.Equal(rangeSet{{0, 2}, {10, 20}, {30, 40}}, s)
+
+	s = s.add(Range{20, 30})
+	assert.Equal(rangeSet{{0, 2}, {10, 40}}, s)
+
+	s = s.add(Range{1, 100})
+	assert.Equal(rangeSet{{0, 100}}, s)
+
+	s = rangeSet{{3, 5}, {60, 70}}
+	assert.Equal(BitBlock(0b11000), s.mask(0)&0xff)
+	assert.Equal(BitBlock(0), s.mask(3))
+}
+
+func TestBitArrayExclude(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithReservedRanges(Range{0, 1}))
+	b.Mark(10)
+	b.Exclude(1, 50)
+	assert.True(b.Get(10))
+	assert.Equal(int64(50), b.MarkFree())
+
+	b.Unmark(10)
+	assert.Equal(int64(51), b.MarkFree())
+
+	b.Include(0, 20)
+	assert.Equal(int64(1), b.MarkFree())
+	assert.Equal(int64(2), b.PeekFree())
+
+	b.Include(0, 100)
+	b.Reset()
+	assert.Equal(int64(1), b.MarkFree())
+
+	b.Exclude(0, 100)
+	assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+	assert.Equal(int64(BitBlockNotFound), b.MarkFreeRandom(nil))
+	assert.True(b.HasRoom())
+}
+
+func TestRangeSetRemove(t *testing.T) {
+	assert := assert.New(t)
+
+	s := rangeSet{{0, 10}, {20, 30}, {40, 50}}
+	assert.Equal(rangeSet{{0, 5}, {25, 30}, {40, 50}}, s.remove(Range{5, 25}))
+	assert.Equal(rangeSet{{0, 10}, {20, 22}, {28, 30}, {40, 50}}, s.remove(Range{22, 28}))
+	assert.Nil(s.remove(Range{-1, 100}))
+}
+
+func TestWithTiers(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithTiers(
+		[]Range{{90, 92}, {10, 11}},
+		[]Range{{50, 52}},
+	))
+	assert.Equal(2, b.Tiers())
+
+	var got []int64
+	for n := 0; n < 6; n++ {
+		got = append(got, b.MarkFree())
+	}
+	assert.Equal([]int64{90, 91, 10, 50, 51, 0}, got)
+	assert.Equal(1.0, b.TierUtilization(0))
+
+	b.Unmark(50)
+	assert.Equal(0.5, b.TierUtilization(1))
+	assert.Equal(int64(50), b.MarkFree())
+
+	assert.Equal(0, NewBitArray(10).Tiers())
+	assert.Panics(func() { b.TierUtilization(2) })
+}
@@ -0,0 +1,138 @@
+package bitarray
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Registry keeps named BitArrays, e.g. one per tenant, safe for concurrent
+// use. The arrays are created on first use and do their own locking, so the
+// registry is locked only to find them.
+type Registry struct {
+	mu     sync.RWMutex
+	arrays map[string]*BitArray
+}
+
+// RegistryStats are the aggregate statistics of the arrays of a Registry.
+type RegistryStats struct {
+	Arrays      int     // number of arrays
+	Len         int64   // number of set bits
+	Cap         int64   // total capacity
+	Utilization float64 // Len / Cap, 0 for a zero capacity
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{arrays: make(map[string]*BitArray)}
+}
+
+// GetOrCreate returns the array registered under name, creating it with the
+// specified capacity and options if there is none. The capacity and the
+// options are ignored for an existing array. Reports whether the array was
+// created.
+func (r *Registry) GetOrCreate(name string, capacity int64, opts ...Option) (b *BitArray, created bool) {
+	if b, ok := r.Lookup(name); ok {
+		return b, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, ok := r.arrays[name]; ok { // created concurrently
+		return b, false
+	}
+
+	b = NewBitArray(capacity, opts...)
+	r.arrays[name] = b
+
+	return b, true
+}
+
+// Lookup returns the array registered under name and reports whether there
+// is one.
+func (r *Registry) Lookup(name string) (*BitArray, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b, ok := r.arrays[name]
+	return b, ok
+}
+
+// Register registers b under name, replacing the array registered under it,
+// if any.
+func (r *Registry) Register(name string, b *BitArray) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.arrays[name] = b
+}
+
+// Remove unregisters the array registered under name and reports whether
+// there was one. The array itself is not modified.
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.arrays[name]
+	delete(r.arrays, name)
+
+	return ok
+}
+
+// Len returns the number of registered arrays.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.arrays)
+}
+
+// Names returns the names of the registered arrays in ascending order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.arrays))
+}
+
+// All returns an iterator over the registered arrays and their names in
+// ascending order of the names. The arrays are those registered when the
+// iteration starts; the registry is not locked while the loop body runs, so
+// it may register and remove arrays.
+func (r *Registry) All() iter.Seq2[string, *BitArray] {
+	return func(yield func(string, *BitArray) bool) {
+		r.mu.RLock()
+		names := slices.Sorted(maps.Keys(r.arrays))
+		arrays := make([]*BitArray, len(names))
+
+		for i, name := range names {
+			arrays[i] = r.arrays[name]
+		}
+		r.mu.RUnlock()
+
+		for i, name := range names {
+			if !yield(name, arrays[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Stats returns the aggregate statistics of the registered arrays. Each
+// array is read separately, so the totals are not a consistent snapshot
+// while the arrays are modified.
+func (r *Registry) Stats() RegistryStats {
+	var s RegistryStats
+
+	for _, b := range r.All() {
+		s.Arrays++
+		s.Len += b.Len64()
+		s.Cap += b.Cap64()
+	}
+
+	s.Utilization = ratio(s.Len, s.Cap)
+
+	return s
+}
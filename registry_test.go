@@ -0,0 +1,70 @@
+package bitarray
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRegistry()
+
+	a, created := r.GetOrCreate("b", 100)
+	assert.True(created)
+	a.MarkFree()
+
+	again, created := r.GetOrCreate("b", 10)
+	assert.False(created)
+	assert.Same(a, again)
+	assert.Equal(100, again.Cap())
+
+	r.Register("a", newMarked(200, 1, 2, 3))
+
+	b, ok := r.Lookup("a")
+	assert.True(ok)
+	assert.Equal(3, b.Len())
+
+	_, ok = r.Lookup("c")
+	assert.False(ok)
+
+	assert.Equal([]string{"a", "b"}, r.Names())
+	assert.Equal(2, r.Len())
+	assert.Equal(RegistryStats{Arrays: 2, Len: 4, Cap: 300, Utilization: 4.0 / 300}, r.Stats())
+
+	var names []string
+	for name := range r.All() {
+		names = append(names, name)
+		r.Remove(name)
+	}
+	assert.Equal([]string{"a", "b"}, names)
+
+	assert.False(r.Remove("a"))
+	assert.Equal(RegistryStats{}, r.Stats())
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	r := NewRegistry()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			b, _ := r.GetOrCreate("shared", 1000)
+			for range 100 {
+				b.MarkFree()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(1, r.Len())
+	assert.Equal(int64(800), r.Stats().Len)
+}
@@ -0,0 +1,28 @@
+package bitarray
+
+// WithRoundRobin makes MarkFree rotate over the regions of regionSize bits,
+// rounded up to whole blocks: every allocation starts the scan for a free
+// bit at the region after the one of the previous allocation, wrapping
+// around at the end, rather than at the lowest block with room. This spreads
+// the allocations over the array, so concurrent users of the allocated
+// resources contend less and wear them evenly. Preferred ranges still take
+// precedence. A non-positive regionSize means a block.
+func WithRoundRobin(regionSize int64) Option {
+	return func(c *config) {
+		c.rotate = max((regionSize+blockSize-1)/blockSize, 1)
+	}
+}
+
+// advance moves the scan pointer to the block cursor of an allocation and,
+// under WithRoundRobin, the rotor to the region after it. Callers hold the
+// write lock.
+func (b *BitArray) advance(cursor int64) {
+	b.curIndex = cursor
+
+	if b.rotate > 0 {
+		b.rotor = (cursor/b.rotate + 1) * b.rotate
+		if b.rotor >= b.size {
+			b.rotor = 0
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRoundRobin(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(1024, WithRoundRobin(256))
+
+	var got []int64
+	for range 6 {
+		got = append(got, b.MarkFree())
+	}
+	assert.Equal([]int64{0, 256, 512, 768, 1, 257}, got)
+
+	b.Unmark(0)
+	assert.Equal(int64(513), b.MarkFree()) // the freed bit does not pull the scan back
+
+	for b.HasRoom() {
+		b.MarkFree()
+	}
+	assert.Equal(1024, b.Len())
+	assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+
+	b.Unmark(700)
+	assert.Equal(int64(700), b.MarkFree())
+}
+
+func TestWithRoundRobinTx(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(200, WithRoundRobin(100))
+
+	assert.NoError(b.Update(func(tx *Tx) error {
+		x, _ := tx.Reserve()
+		y, _ := tx.Reserve()
+		assert.Equal([]int64{0, 128}, []int64{x, y})
+
+		return nil
+	}))
+}
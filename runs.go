@@ -0,0 +1,129 @@
+package bitarray
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Run is a maximal run of bits with the same value: the bits
+// [Start, Start+Length), all set if Set is true and all clear otherwise.
+type Run struct {
+	Start  int64
+	Length int64
+	Set    bool
+}
+
+// End returns the index after the last bit of the run.
+func (r Run) End() int64 {
+	return r.Start + r.Length
+}
+
+// Runs returns the maximal runs of set bits in ascending order, which is
+// much more compact than the indexes of the set bits for clustered arrays.
+func (b *BitArray) Runs() []Run {
+	var res []Run
+
+	for r := range b.SetRuns() {
+		res = append(res, r)
+	}
+
+	return res
+}
+
+// SetRuns returns an iterator over the maximal runs of set bits in ascending
+// order. The array is read-locked while the iteration is in progress, so the
+// loop body must not modify it.
+func (b *BitArray) SetRuns() iter.Seq[Run] {
+	return func(yield func(Run) bool) {
+		b.rlock()
+		defer b.runlock()
+
+		b.forEachRun(func(start, end int64) bool {
+			return yield(Run{Start: start, Length: end - start, Set: true})
+		})
+	}
+}
+
+// FreeRanges returns an iterator over the maximal runs of clear bits below
+// the capacity in ascending order, e.g. to place blocks of consecutive
+// indexes. The runs include the clear bits fenced off from MarkFree. The
+// array is read-locked while the iteration is in progress, so the loop body
+// must not modify it.
+func (b *BitArray) FreeRanges() iter.Seq[Run] {
+	return func(yield func(Run) bool) {
+		b.rlock()
+		defer b.runlock()
+
+		capacity := b.capacity.Get64()
+
+		for start := b.nextClear(0); start < capacity; {
+			end := b.nextSet(start)
+
+			if !yield(Run{Start: start, Length: end - start}) {
+				return
+			}
+
+			start = b.nextClear(end)
+		}
+	}
+}
+
+// EncodeRuns returns the run-length encoding of the array: the maximal runs
+// of set and of clear bits, alternating, in ascending order and covering the
+// bits up to the capacity. FromRuns is its inverse.
+func (b *BitArray) EncodeRuns() []Run {
+	b.rlock()
+	defer b.runlock()
+
+	var res []Run
+
+	next := int64(0)
+
+	b.forEachRun(func(start, end int64) bool {
+		if start > next {
+			res = append(res, Run{Start: next, Length: start - next})
+		}
+
+		res = append(res, Run{Start: start, Length: end - start, Set: true})
+		next = end
+
+		return true
+	})
+
+	if capacity := b.capacity.Get64(); capacity > next {
+		res = append(res, Run{Start: next, Length: capacity - next})
+	}
+
+	return res
+}
+
+// FromRuns creates a BitArray of the specified capacity with the bits of the
+// set runs set, e.g. from the result of EncodeRuns. The runs must be in
+// ascending order without overlapping and lie below the capacity; the clear
+// runs are accepted but have no effect, and gaps between runs are clear.
+func FromRuns(capacity int64, runs []Run, opts ...Option) (*BitArray, error) {
+	b, err := NewBitArrayE(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	next := int64(0)
+
+	for _, r := range runs {
+		switch {
+		case r.Start < next || r.Length < 0:
+			return nil, fmt.Errorf("%w: run %d+%d out of order", ErrFormat, r.Start, r.Length)
+
+		case r.Length > capacity-r.Start:
+			return nil, fmt.Errorf("%w: run %d+%d with capacity %d", ErrOutOfRange, r.Start, r.Length, capacity)
+		}
+
+		if r.Set {
+			b.setRange(r.Start, r.End(), bitBlockMark)
+		}
+
+		next = r.End()
+	}
+
+	return b, nil
+}
@@ -0,0 +1,107 @@
+package bitarray
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayRuns(t *testing.T) {
+	assert := assert.New(t)
+
+	b, err := ParseRanges("0-3,7,60-130,199", 200)
+	assert.NoError(err)
+
+	runs := b.Runs()
+	assert.Equal([]Run{
+		{Start: 0, Length: 4, Set: true},
+		{Start: 7, Length: 1, Set: true},
+		{Start: 60, Length: 71, Set: true},
+		{Start: 199, Length: 1, Set: true},
+	}, runs)
+	assert.Equal(int64(131), runs[2].End())
+
+	var first []Run
+	for r := range b.SetRuns() {
+		first = append(first, r)
+		break
+	}
+	assert.Equal(runs[:1], first)
+
+	assert.Nil(NewBitArray(100).Runs())
+	assert.Equal([]Run{{Start: 0, Length: 100, Set: true}}, slices.Collect(NewBitArrayFull(100).SetRuns()))
+}
+
+func TestBitArrayFreeRanges(t *testing.T) {
+	assert := assert.New(t)
+
+	b, err := ParseRanges("0-3,7,60-130,198", 200)
+	assert.NoError(err)
+
+	assert.Equal([]Run{
+		{Start: 4, Length: 3},
+		{Start: 8, Length: 52},
+		{Start: 131, Length: 67},
+		{Start: 199, Length: 1},
+	}, slices.Collect(b.FreeRanges()))
+
+	for r := range b.FreeRanges() {
+		assert.Equal(Run{Start: 4, Length: 3}, r)
+		break
+	}
+
+	assert.Equal([]Run{{Start: 0, Length: 100}}, slices.Collect(NewBitArray(100).FreeRanges()))
+	assert.Empty(slices.Collect(NewBitArrayFull(100).FreeRanges()))
+	assert.Empty(slices.Collect(NewBitArray(0).FreeRanges()))
+}
+
+func TestBitArrayEncodeRuns(t *testing.T) {
+	assert := assert.New(t)
+
+	b, err := ParseRanges("3-5,64-127", 200)
+	assert.NoError(err)
+
+	runs := b.EncodeRuns()
+	assert.Equal([]Run{
+		{Start: 0, Length: 3},
+		{Start: 3, Length: 3, Set: true},
+		{Start: 6, Length: 58},
+		{Start: 64, Length: 64, Set: true},
+		{Start: 128, Length: 72},
+	}, runs)
+
+	c, err := FromRuns(200, runs)
+	assert.NoError(err)
+	assert.Equal(b.FormatRanges(), c.FormatRanges())
+	assert.Equal(b.Len(), c.Len())
+	assert.NoError(c.Validate())
+
+	assert.Equal([]Run{{Start: 0, Length: 10, Set: true}}, NewBitArrayFull(10).EncodeRuns())
+	assert.Equal([]Run{{Start: 0, Length: 10}}, NewBitArray(10).EncodeRuns())
+	assert.Nil(NewBitArray(0).EncodeRuns())
+
+	c, err = FromRuns(100, []Run{{Start: 10, Length: 5, Set: true}, {Start: 99, Length: 1, Set: true}})
+	assert.NoError(err)
+	assert.Equal("10-14,99", c.FormatRanges())
+}
+
+func TestFromRunsInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := FromRuns(100, []Run{{Start: 10, Length: 5, Set: true}, {Start: 12, Length: 1, Set: true}})
+	assert.True(errors.Is(err, ErrFormat))
+
+	_, err = FromRuns(100, []Run{{Start: 10, Length: -1}})
+	assert.True(errors.Is(err, ErrFormat))
+
+	_, err = FromRuns(100, []Run{{Start: -1, Length: 2, Set: true}})
+	assert.True(errors.Is(err, ErrFormat))
+
+	_, err = FromRuns(100, []Run{{Start: 90, Length: 11, Set: true}})
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	_, err = FromRuns(-1, nil)
+	assert.True(errors.Is(err, ErrCapacity))
+}
@@ -0,0 +1,57 @@
+package bitarray
+
+// ScanStrategy defines where MarkFree starts the search for a free bit.
+type ScanStrategy int
+
+const (
+	// ScanResume resumes the search at the block of the previous
+	// allocation, moved back by the methods clearing bits before it, and
+	// wraps around at the end. It makes MarkFree fast for arrays filled
+	// from the start, but the allocated index is not necessarily the
+	// lowest free one.
+	ScanResume ScanStrategy = iota
+
+	// ScanFromStart searches from the first block on every allocation, so
+	// MarkFree always allocates the lowest free index, at the cost of
+	// scanning the full blocks before it.
+	ScanFromStart
+
+	// ScanLowest allocates the lowest free index like ScanFromStart, but
+	// skips the full superblocks of 512 bits using the count cache, which
+	// it enables, see WithCountCache.
+	ScanLowest
+)
+
+// WithScanStrategy sets where MarkFree starts the search for a free bit.
+// Preferred ranges still take precedence, and the strategies other than
+// ScanResume override WithRoundRobin.
+func WithScanStrategy(strategy ScanStrategy) Option {
+	return func(c *config) {
+		c.scan = strategy
+
+		if strategy == ScanLowest {
+			c.countCache = true
+		}
+	}
+}
+
+// lowestFree returns the index of the first block that has room, skipping
+// the superblocks the count cache reports as full, or BitBlockNotFound.
+// Callers hold the read lock.
+func (b *BitArray) lowestFree() int64 {
+	const blocks = superBlockSize / blockSize
+
+	for k, n := range b.counts {
+		if n == superBlockSize {
+			continue
+		}
+
+		for i, last := int64(k)*blocks, min(int64(k+1)*blocks, b.size); i < last; i++ {
+			if b.occupied(i).hasRoom() {
+				return i
+			}
+		}
+	}
+
+	return BitBlockNotFound
+}
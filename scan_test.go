@@ -0,0 +1,49 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithScanStrategy(t *testing.T) {
+	assert := assert.New(t)
+
+	const capacity = 4 * superBlockSize
+
+	for _, tc := range []struct {
+		strategy ScanStrategy
+		want     int64
+	}{
+		{ScanResume, 3 * superBlockSize},
+		{ScanFromStart, 5},
+		{ScanLowest, 5},
+	} {
+		b := NewBitArray(capacity, WithScanStrategy(tc.strategy))
+		for i := 0; i < 3*superBlockSize; i++ {
+			b.MarkFree()
+		}
+
+		// clearing a bit through a whole block leaves the scan pointer
+		b.lock()
+		b.setBlock(0, b.blocks[0]&^mask(5))
+		b.unlock()
+
+		assert.Equal(tc.want, b.MarkFree(), "strategy %d", tc.strategy)
+		assert.NoError(b.Validate())
+	}
+
+	b := NewBitArray(capacity, WithScanStrategy(ScanLowest))
+	assert.NotNil(b.counts)
+
+	b.Fill()
+	b.Unmark(capacity - 1)
+	b.Unmark(2*superBlockSize + 3)
+	b.Exclude(2*superBlockSize, 3*superBlockSize)
+
+	assert.EqualValues(capacity-1, b.MarkFree())
+	assert.EqualValues(BitBlockNotFound, b.MarkFree())
+
+	b.Include(0, capacity)
+	assert.EqualValues(2*superBlockSize+3, b.MarkFree())
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client calls the API of a Server.
+type Client struct {
+	base string
+	hc   *http.Client
+}
+
+// NewClient returns a Client for the Server at baseURL, e.g.
+// "http://ids.internal:8080". A nil hc means http.DefaultClient.
+func NewClient(baseURL string, hc *http.Client) *Client {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	return &Client{base: strings.TrimSuffix(baseURL, "/"), hc: hc}
+}
+
+// Acquire allocates a free index. It returns ErrExhausted if there is none.
+func (c *Client) Acquire(ctx context.Context) (int64, error) {
+	var v acquireResponse
+
+	err := c.call(ctx, http.MethodPost, "/acquire", nil, &v)
+
+	return v.Index, err
+}
+
+// Release frees the index and reports whether it was allocated.
+func (c *Client) Release(ctx context.Context, index int64) (bool, error) {
+	var v releaseResponse
+
+	err := c.call(ctx, http.MethodPost, "/release", indexQuery(index), &v)
+
+	return v.Released, err
+}
+
+// Query reports whether the index is allocated.
+func (c *Client) Query(ctx context.Context, index int64) (bool, error) {
+	var v queryResponse
+
+	err := c.call(ctx, http.MethodGet, "/query", indexQuery(index), &v)
+
+	return v.Set, err
+}
+
+// Stats returns the number of allocated indexes and the capacity.
+func (c *Client) Stats(ctx context.Context) (count, capacity int64, err error) {
+	var v statsResponse
+
+	err = c.call(ctx, http.MethodGet, "/query", nil, &v)
+
+	return v.Len, v.Cap, err
+}
+
+func indexQuery(index int64) url.Values {
+	return url.Values{"index": {strconv.FormatInt(index, 10)}}
+}
+
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, v any) error {
+	u := c.base + path
+	if query != nil {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		return ErrExhausted
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		json.NewDecoder(resp.Body).Decode(&e)
+
+		return fmt.Errorf("server: %s %s: %s: %s", method, path, resp.Status, e.Error)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,133 @@
+// Package server shares the ID space of a BitArray between services over
+// HTTP and JSON. A Server allocates from the array on behalf of its
+// clients:
+//
+//	POST /acquire            {"index": 42}
+//	POST /release?index=42   {"released": true}
+//	GET  /query?index=42     {"index": 42, "set": false}
+//	GET  /query              {"len": 1, "cap": 1000}
+//
+// Errors are reported with a non-2xx status and {"error": "..."}; a full
+// array makes /acquire fail with 409 Conflict.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aermolaev/bitarray"
+)
+
+// ErrExhausted is returned by Client.Acquire when the array has no room.
+var ErrExhausted = errors.New("server: no free index")
+
+// Server is an http.Handler serving the allocation API for a BitArray.
+type Server struct {
+	b   *bitarray.BitArray
+	mux *http.ServeMux
+}
+
+// New returns a Server allocating from b.
+func New(b *bitarray.BitArray) *Server {
+	s := &Server{b: b, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("POST /acquire", s.acquire)
+	s.mux.HandleFunc("POST /release", s.release)
+	s.mux.HandleFunc("GET /query", s.query)
+
+	return s
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type acquireResponse struct {
+	Index int64 `json:"index"`
+}
+
+type releaseResponse struct {
+	Released bool `json:"released"`
+}
+
+type queryResponse struct {
+	Index int64 `json:"index"`
+	Set   bool  `json:"set"`
+}
+
+type statsResponse struct {
+	Len int64 `json:"len"`
+	Cap int64 `json:"cap"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) acquire(w http.ResponseWriter, r *http.Request) {
+	index := s.b.MarkFree()
+	if index == bitarray.BitBlockNotFound {
+		reply(w, http.StatusConflict, errorResponse{ErrExhausted.Error()})
+		return
+	}
+
+	reply(w, http.StatusOK, acquireResponse{index})
+}
+
+func (s *Server) release(w http.ResponseWriter, r *http.Request) {
+	index, ok := indexParam(w, r)
+	if !ok {
+		return
+	}
+
+	released, err := s.b.SetE(index, false)
+	if err != nil {
+		reply(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+
+	reply(w, http.StatusOK, releaseResponse{released})
+}
+
+func (s *Server) query(w http.ResponseWriter, r *http.Request) {
+	if !r.URL.Query().Has("index") {
+		reply(w, http.StatusOK, statsResponse{s.b.Len64(), s.b.Cap64()})
+		return
+	}
+
+	index, ok := indexParam(w, r)
+	if !ok {
+		return
+	}
+
+	set, err := s.b.GetE(index)
+	if err != nil {
+		reply(w, http.StatusBadRequest, errorResponse{err.Error()})
+		return
+	}
+
+	reply(w, http.StatusOK, queryResponse{index, set})
+}
+
+// indexParam parses the index query parameter, replying with an error if
+// it is malformed.
+func indexParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	s := r.URL.Query().Get("index")
+
+	index, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		reply(w, http.StatusBadRequest, errorResponse{"bad index " + strconv.Quote(s)})
+		return 0, false
+	}
+
+	return index, true
+}
+
+func reply(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
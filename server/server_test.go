@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aermolaev/bitarray"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := httptest.NewServer(New(bitarray.NewBitArray(2)))
+	defer ts.Close()
+
+	ctx := context.Background()
+	c := NewClient(ts.URL+"/", ts.Client())
+
+	i, err := c.Acquire(ctx)
+	assert.NoError(err)
+	assert.Equal(int64(0), i)
+
+	i, err = c.Acquire(ctx)
+	assert.NoError(err)
+	assert.Equal(int64(1), i)
+
+	_, err = c.Acquire(ctx)
+	assert.True(errors.Is(err, ErrExhausted))
+
+	set, err := c.Query(ctx, 1)
+	assert.NoError(err)
+	assert.True(set)
+
+	released, err := c.Release(ctx, 1)
+	assert.NoError(err)
+	assert.True(released)
+
+	released, err = c.Release(ctx, 1)
+	assert.NoError(err)
+	assert.False(released)
+
+	count, capacity, err := c.Stats(ctx)
+	assert.NoError(err)
+	assert.Equal(int64(1), count)
+	assert.Equal(int64(2), capacity)
+
+	_, err = c.Query(ctx, 5)
+	assert.Error(err)
+	assert.Contains(err.Error(), "400")
+}
@@ -0,0 +1,157 @@
+package bitarray
+
+import (
+	"fmt"
+	"iter"
+	"sync/atomic"
+)
+
+// Sharded is a bit array split into shards of consecutive indexes, each a
+// BitArray with its own lock, so allocations from different shards do not
+// contend. Workers may pin their allocations to a shard with
+// MarkFreeInShard. The capacity of a Sharded array is fixed.
+type Sharded struct {
+	shards   []*BitArray
+	per      int64 // indexes per shard, a multiple of 64
+	capacity int64
+	next     atomic.Uint64
+}
+
+// NewSharded creates a Sharded array of the specified capacity split into
+// the specified number of shards. The options apply to every shard, so the
+// indexes passed to the options are relative to the shard. It panics unless
+// shards is positive.
+func NewSharded(capacity int64, shards int, opts ...Option) *Sharded {
+	if shards <= 0 {
+		panic(fmt.Errorf("bitarray: %d shards", shards))
+	}
+
+	capacity = max(capacity, 0)
+
+	s := &Sharded{
+		shards:   make([]*BitArray, shards),
+		per:      max(wordCount((capacity+int64(shards)-1)/int64(shards)), 1) * wordSize,
+		capacity: capacity,
+	}
+
+	for i := range s.shards {
+		from := min(int64(i)*s.per, capacity)
+		s.shards[i] = NewBitArray(min(from+s.per, capacity)-from, opts...)
+	}
+
+	return s
+}
+
+// Shards returns the number of shards.
+func (s *Sharded) Shards() int {
+	return len(s.shards)
+}
+
+// ShardOf returns the shard holding the bit at the specified index.
+func (s *Sharded) ShardOf(index int64) int {
+	return int(min(max(index, 0)/s.per, int64(len(s.shards)-1)))
+}
+
+// MarkFreeInShard finds a clear bit in the specified shard and sets it to
+// true. If the shard is full, the following shards are tried in turn.
+// Returns the index of the bit, or BitBlockNotFound if the array is full.
+// It panics if the shard is out of range.
+func (s *Sharded) MarkFreeInShard(shard int) int64 {
+	if shard < 0 || shard >= len(s.shards) {
+		panic(fmt.Errorf("bitarray: shard %d of %d", shard, len(s.shards)))
+	}
+
+	for n := range s.shards {
+		i := (shard + n) % len(s.shards)
+
+		if index := s.shards[i].MarkFree(); index != BitBlockNotFound {
+			return int64(i)*s.per + index
+		}
+	}
+
+	return BitBlockNotFound
+}
+
+// MarkFree finds a clear bit and sets it to true, starting with the shards
+// in turn to spread the allocations. Returns the index of the bit, or
+// BitBlockNotFound if the array is full.
+func (s *Sharded) MarkFree() int64 {
+	return s.MarkFreeInShard(int((s.next.Add(1) - 1) % uint64(len(s.shards))))
+}
+
+// Get returns the value of the bit with the specified index. Indexes out of
+// range are reported as false.
+func (s *Sharded) Get(index int64) bool {
+	if index < 0 || index >= s.capacity {
+		return false
+	}
+
+	return s.shards[s.ShardOf(index)].Get(index % s.per)
+}
+
+// Set sets the bit at the specified index to the specified value. Indexes
+// out of range are ignored.
+func (s *Sharded) Set(index int64, mark bool) bool {
+	if index < 0 || index >= s.capacity {
+		return false
+	}
+
+	return s.shards[s.ShardOf(index)].Set(index%s.per, mark)
+}
+
+// Mark sets the bit at the specified index to true.
+func (s *Sharded) Mark(index int64) {
+	s.Set(index, bitBlockMark)
+}
+
+// Unmark sets the bit at the specified index to false.
+func (s *Sharded) Unmark(index int64) {
+	s.Set(index, bitBlockUnmark)
+}
+
+// Len64 returns the number of set bits. The shards are counted one by one,
+// so the result is not atomic with respect to concurrent changes.
+func (s *Sharded) Len64() (n int64) {
+	for _, b := range s.shards {
+		n += b.Len64()
+	}
+
+	return
+}
+
+// Len returns the number of set bits.
+func (s *Sharded) Len() int {
+	return toInt(s.Len64())
+}
+
+// Cap64 returns the capacity.
+func (s *Sharded) Cap64() int64 {
+	return s.capacity
+}
+
+// Cap returns the capacity.
+func (s *Sharded) Cap() int {
+	return toInt(s.capacity)
+}
+
+// Shard returns the BitArray of the specified shard, whose index 0 is the
+// index shard*n of s for n indexes per shard.
+func (s *Sharded) Shard(shard int) *BitArray {
+	return s.shards[shard]
+}
+
+// SetBits returns an iterator over the indexes of the set bits in ascending
+// order. Every shard is read under its lock on its own.
+func (s *Sharded) SetBits() iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		for i, b := range s.shards {
+			for index := range b.SetBits() {
+				if !yield(int64(i)*s.per + index) {
+					return
+				}
+			}
+		}
+	}
+}
+
+var _ BitSet = (*Sharded)(nil)
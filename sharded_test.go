@@ -0,0 +1,57 @@
+package bitarray
+
+import (
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharded(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSharded(300, 4)
+	assert.Equal(4, s.Shards())
+	assert.Equal(300, s.Cap())
+	assert.Equal(0, s.ShardOf(127))
+	assert.Equal(1, s.ShardOf(128))
+	assert.Equal(2, s.ShardOf(299))
+
+	assert.Equal(int64(128), s.MarkFreeInShard(1))
+	assert.Equal(int64(129), s.MarkFreeInShard(1))
+	assert.Equal(int64(0), s.MarkFreeInShard(3)) // shard 3 is empty
+	assert.True(s.Set(5, true))
+	assert.False(s.Set(300, true))
+	assert.True(s.Get(129))
+	assert.False(s.Get(300))
+	assert.Equal([]int64{0, 5, 128, 129}, slices.Collect(s.SetBits()))
+	assert.Equal(4, s.Len())
+
+	assert.Panics(func() { s.MarkFreeInShard(4) })
+	assert.Panics(func() { NewSharded(10, 0) })
+
+	testBitSet(t, NewSharded(100, 3))
+}
+
+func TestShardedConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSharded(1000, 8)
+
+	var wg sync.WaitGroup
+	for shard := 0; shard < s.Shards(); shard++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for n := 0; n < 200; n++ {
+				s.MarkFreeInShard(shard)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(1000, s.Len())
+	assert.Equal(int64(BitBlockNotFound), s.MarkFree())
+}
@@ -0,0 +1,472 @@
+//go:build (linux || darwin || freebsd) && !purego
+
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+)
+
+// The shared file consists of a sharedHeader followed by ceil(capacity/64)
+// 64-bit words in the byte order of the host. It is meant for processes of
+// the same host, e.g. in /dev/shm, and is not portable across architectures.
+const (
+	sharedMagic     = "BSHM"
+	sharedVersion   = 1
+	sharedHeaderLen = int(unsafe.Sizeof(sharedHeader{}))
+)
+
+type sharedHeader struct {
+	magic    [4]byte
+	version  uint32
+	capacity int64
+	count    int64
+	curIndex int64
+	_        [32]byte
+}
+
+// Shared is a bit array stored in a memory-mapped file, so several processes
+// on the same host can allocate from one bitmap without a broker. Every
+// operation holds a file lock (flock) on the file, in addition to a mutex
+// serializing the goroutines of the process, so the updates of all processes
+// are atomic with respect to each other.
+//
+// The kernel writes the changes back to the file on its own; WithSyncPolicy
+// sets when they are also committed to stable storage. Since the methods
+// cannot report I/O errors, the first failure is kept and reported by Sync
+// and Close.
+//
+// After Close, the queries report an empty array of zero capacity, the
+// updates are ignored, and Sync and Close return fs.ErrClosed.
+type Shared struct {
+	mu     sync.Mutex
+	file   *os.File
+	data   []byte
+	header *sharedHeader
+	blocks []BitBlock
+	sync   syncer
+	err    error
+}
+
+// OpenShared maps the shared bit array stored in the file at path, creating
+// it with the specified capacity if it does not exist. The capacity of an
+// existing file takes precedence, and its count of set bits is recomputed
+// from the words, which repairs a corrupt header. Of the options, only
+// WithSyncPolicy applies.
+func OpenShared(path string, capacity int64, opts ...Option) (*Shared, error) {
+	if err := checkCapacity(capacity); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := mapShared(file, capacity)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var c config
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	s.sync.policy = c.sync
+	s.sync.start(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.data != nil && s.sync.pending > 0 {
+			s.msync()
+		}
+	})
+
+	return s, nil
+}
+
+func mapShared(file *os.File, capacity int64) (*Shared, error) {
+	fd := int(file.Fd())
+
+	if err := flock(fd, syscall.LOCK_EX); err != nil {
+		return nil, err
+	}
+	defer flock(fd, syscall.LOCK_UN)
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	create := info.Size() == 0
+	if !create {
+		if capacity, err = readSharedCapacity(file); err != nil {
+			return nil, err
+		}
+	}
+
+	size := int64(sharedHeaderLen) + wordCount(capacity)*8
+	if int64(int(size)) != size {
+		return nil, fmt.Errorf("capacity %d exceeds the address space", capacity)
+	}
+
+	if create {
+		if err = file.Truncate(size); err != nil {
+			return nil, err
+		}
+	} else if info.Size() != size {
+		return nil, fmt.Errorf("%w: size %d for capacity %d", ErrFormat, info.Size(), capacity)
+	}
+
+	data, err := syscall.Mmap(fd, 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Shared{
+		file:   file,
+		data:   data,
+		header: (*sharedHeader)(unsafe.Pointer(&data[0])),
+	}
+
+	if n := wordCount(capacity) * (wordSize / blockSize); n > 0 {
+		s.blocks = unsafe.Slice((*BitBlock)(unsafe.Pointer(&data[sharedHeaderLen])), n)
+	}
+
+	if create {
+		copy(s.header.magic[:], sharedMagic)
+		s.header.version = sharedVersion
+		s.header.capacity = capacity
+	} else {
+		s.repair()
+	}
+
+	return s, nil
+}
+
+// repair recounts the set bits and resets a scan pointer out of range, so a
+// corrupt header cannot make the methods fail or lie. Callers hold the
+// exclusive file lock.
+func (s *Shared) repair() {
+	h := s.header
+
+	var count int64
+	for i := range s.blocks {
+		count += (s.blocks[i] & capacityMask(int64(i), h.capacity)).popcount()
+	}
+
+	atomic.StoreInt64(&h.count, count)
+
+	if h.curIndex < 0 || h.curIndex >= int64(len(s.blocks)) {
+		h.curIndex = 0
+	}
+}
+
+func readSharedCapacity(file *os.File) (int64, error) {
+	var buf [sharedHeaderLen]byte
+
+	if _, err := file.ReadAt(buf[:], 0); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+
+	h := (*sharedHeader)(unsafe.Pointer(&buf[0]))
+
+	if string(h.magic[:]) != sharedMagic || h.version != sharedVersion {
+		return 0, fmt.Errorf("%w: bad header", ErrFormat)
+	}
+
+	if err := checkCapacity(h.capacity); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	return h.capacity, nil
+}
+
+// Sync commits the mapped file to stable storage.
+func (s *Shared) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data == nil {
+		return fs.ErrClosed
+	}
+
+	s.msync()
+
+	return s.err
+}
+
+// Close commits the mapped file to stable storage, unmaps the file and
+// closes it. The bits stay in the file.
+func (s *Shared) Close() error {
+	s.sync.halt()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data == nil {
+		return fs.ErrClosed
+	}
+
+	s.msync()
+
+	err := syscall.Munmap(s.data)
+	s.data, s.header, s.blocks = nil, nil, nil
+
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+
+	if s.err != nil {
+		err = s.err
+	}
+
+	return err
+}
+
+// changed commits a change if the sync policy asks for it.
+func (s *Shared) changed() {
+	if s.sync.changed() {
+		s.msync()
+	}
+}
+
+// msync synchronously writes the mapped file back to stable storage.
+func (s *Shared) msync() {
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&s.data[0])), uintptr(len(s.data)), syscall.MS_SYNC)
+
+	if errno != 0 && s.err == nil {
+		s.err = errno
+	}
+
+	s.sync.pending = 0
+}
+
+// Len64 returns the number of occupied bits.
+func (s *Shared) Len64() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.header == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&s.header.count)
+}
+
+// Len returns the number of occupied bits.
+func (s *Shared) Len() int {
+	return toInt(s.Len64())
+}
+
+// Cap64 returns the capacity.
+func (s *Shared) Cap64() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.header == nil {
+		return 0
+	}
+
+	return s.header.capacity
+}
+
+// Cap returns the capacity.
+func (s *Shared) Cap() int {
+	return toInt(s.Cap64())
+}
+
+// HasRoom reports whether there are bits that are set to false.
+func (s *Shared) HasRoom() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.header != nil && atomic.LoadInt64(&s.header.count) < s.header.capacity
+}
+
+// Get returns the value of the bit with the specified index. Indexes out of
+// range are reported as false.
+func (s *Shared) Get(index int64) (res bool) {
+	if index < 0 || !s.rlock() {
+		return
+	}
+	defer s.runlock()
+
+	if index >= s.header.capacity {
+		return
+	}
+
+	i, j := bitIndexAndNum(index)
+
+	return s.blocks[i].value(j)
+}
+
+// Set sets the bit at the specified index to the specified value. Indexes
+// out of range are ignored.
+func (s *Shared) Set(index int64, mark bool) (changed bool) {
+	if index < 0 || !s.lock() {
+		return
+	}
+	defer s.unlock()
+
+	if index >= s.header.capacity {
+		return
+	}
+
+	i, j := bitIndexAndNum(index)
+	block := &s.blocks[i]
+
+	if mark == bitBlockMark {
+		if changed = block.compareAndMark(j); changed {
+			atomic.AddInt64(&s.header.count, 1)
+		}
+	} else {
+		if changed = block.compareAndUnmark(j); changed {
+			atomic.AddInt64(&s.header.count, -1)
+
+			if i < s.header.curIndex {
+				s.header.curIndex = i
+			}
+		}
+	}
+
+	if changed {
+		s.changed()
+	}
+
+	return
+}
+
+// Mark sets the bit at the specified index to true.
+func (s *Shared) Mark(index int64) {
+	s.Set(index, bitBlockMark)
+}
+
+// Unmark sets the bit at the specified index to false.
+func (s *Shared) Unmark(index int64) {
+	s.Set(index, bitBlockUnmark)
+}
+
+// MarkFree finds the index of the first bit that is set to false and sets
+// the bit to true, atomically across all the processes sharing the file.
+// Returns BitBlockNotFound unless the array has room.
+func (s *Shared) MarkFree() int64 {
+	if !s.lock() {
+		return BitBlockNotFound
+	}
+	defer s.unlock()
+
+	h := s.header
+	size := int64(len(s.blocks))
+
+	if h.count >= h.capacity {
+		return BitBlockNotFound
+	}
+
+	i := h.curIndex
+	if i < 0 || i >= size {
+		i = 0 // corrupted by another process
+	}
+
+	for n := int64(0); n < size; n++ {
+		if block := s.blocks[i] | ^capacityMask(i, h.capacity); block.hasRoom() {
+			j := block.ffz()
+			s.blocks[i].mark(j)
+			atomic.AddInt64(&h.count, 1)
+			h.curIndex = i
+			s.changed()
+
+			return (i * blockSize) + j
+		}
+
+		i = (i + 1) % size
+	}
+
+	return BitBlockNotFound
+}
+
+// SetBits returns an iterator over the indexes of the set bits in
+// ascending order. Every block is read under the lock on its own, so the
+// iteration is not atomic with respect to the other processes.
+func (s *Shared) SetBits() iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		for i := int64(0); s.rlock(); i++ {
+			if i >= int64(len(s.blocks)) {
+				s.runlock()
+				return
+			}
+
+			block := s.blocks[i] & capacityMask(i, s.header.capacity)
+			s.runlock()
+
+			for ; block != 0; block &= block - 1 {
+				if !yield((i * blockSize) + block.ffs()) {
+					return
+				}
+			}
+		}
+	}
+}
+
+var _ BitSet = (*Shared)(nil)
+
+// lock takes the mutex and an exclusive file lock. It reports false, leaving
+// both unlocked, if the array is closed.
+func (s *Shared) lock() bool {
+	s.mu.Lock()
+
+	if s.data == nil {
+		s.mu.Unlock()
+		return false
+	}
+
+	flock(int(s.file.Fd()), syscall.LOCK_EX)
+
+	return true
+}
+
+func (s *Shared) unlock() {
+	flock(int(s.file.Fd()), syscall.LOCK_UN)
+	s.mu.Unlock()
+}
+
+// rlock takes a shared file lock like lock. The goroutines of the process
+// still serialize, since a file lock is released as a whole by its first
+// unlock.
+func (s *Shared) rlock() bool {
+	s.mu.Lock()
+
+	if s.data == nil {
+		s.mu.Unlock()
+		return false
+	}
+
+	flock(int(s.file.Fd()), syscall.LOCK_SH)
+
+	return true
+}
+
+func (s *Shared) runlock() {
+	flock(int(s.file.Fd()), syscall.LOCK_UN)
+	s.mu.Unlock()
+}
+
+// flock applies the file lock operation how, retrying on interrupts.
+func flock(fd, how int) error {
+	for {
+		if err := syscall.Flock(fd, how); !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
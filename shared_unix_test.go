@@ -0,0 +1,180 @@
+//go:build (linux || darwin || freebsd) && !purego
+
+package bitarray
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShared(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "shared")
+
+	s, err := OpenShared(path, 100)
+	assert.NoError(err)
+	defer s.Close()
+
+	assert.Equal(100, s.Cap())
+	assert.Equal(int64(0), s.MarkFree())
+	s.Mark(70)
+	assert.True(s.Get(70))
+	assert.False(s.Get(100))
+	assert.False(s.Set(-1, true))
+
+	// a second mapping, as another process would have
+	o, err := OpenShared(path, 0)
+	assert.NoError(err)
+	defer o.Close()
+
+	assert.Equal(100, o.Cap())
+	assert.Equal(2, o.Len())
+	assert.True(o.Get(70))
+	assert.Equal(int64(1), o.MarkFree())
+
+	o.Unmark(0)
+	assert.False(s.Get(0))
+	assert.Equal(int64(0), s.MarkFree())
+}
+
+func TestSharedConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "shared")
+
+	const count = 1_000
+
+	var handles []*Shared
+	for i := 0; i < 4; i++ {
+		s, err := OpenShared(path, count)
+		assert.NoError(err)
+		defer s.Close()
+
+		handles = append(handles, s)
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = map[int64]bool{}
+		wg   sync.WaitGroup
+	)
+
+	for _, s := range handles {
+		wg.Add(1)
+		go func(s *Shared) {
+			defer wg.Done()
+
+			for i := s.MarkFree(); i != BitBlockNotFound; i = s.MarkFree() {
+				mu.Lock()
+				assert.False(seen[i])
+				seen[i] = true
+				mu.Unlock()
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	assert.Len(seen, count)
+	assert.Equal(count, handles[0].Len())
+	assert.False(handles[0].HasRoom())
+}
+
+func TestSharedBadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared")
+	assert.NoError(t, os.WriteFile(path, make([]byte, 100), 0o644))
+
+	_, err := OpenShared(path, 10)
+	assert.True(t, errors.Is(err, ErrFormat))
+}
+
+func TestSharedSync(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "shared")
+
+	s, err := OpenShared(path, 100, WithSyncPolicy(SyncAlways()))
+	assert.NoError(err)
+
+	s.Mark(3)
+	assert.Equal(0, s.sync.pending)
+	assert.Equal(int64(0), s.MarkFree())
+	assert.Equal(0, s.sync.pending)
+	assert.NoError(s.Sync())
+	assert.NoError(s.Close())
+	assert.True(errors.Is(s.Sync(), os.ErrClosed))
+
+	s, err = OpenShared(path, 0, WithSyncPolicy(SyncInterval(time.Millisecond)))
+	assert.NoError(err)
+	defer s.Close()
+
+	s.Unmark(3)
+	assert.Eventually(func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return s.sync.pending == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(1, s.Len())
+}
+
+func TestSharedCorruptHeader(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "shared")
+
+	s, err := OpenShared(path, 100)
+	assert.NoError(err)
+	s.Mark(3)
+	s.header.count, s.header.curIndex = 1000, 1<<40
+	assert.NoError(s.Close())
+
+	s, err = OpenShared(path, 0)
+	assert.NoError(err)
+	defer s.Close()
+
+	assert.Equal(1, s.Len())
+	assert.True(s.HasRoom())
+	assert.Equal(int64(0), s.MarkFree())
+
+	s.header.curIndex = -5
+	assert.Equal(int64(1), s.MarkFree())
+}
+
+func TestSharedClosed(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "shared")
+
+	_, err := OpenShared(path, -1)
+	assert.True(errors.Is(err, ErrCapacity))
+
+	s, err := OpenShared(path, 100)
+	assert.NoError(err)
+	s.Mark(3)
+	assert.NoError(s.Close())
+
+	assert.False(s.Get(3))
+	assert.False(s.Set(4, bitBlockMark))
+	assert.Equal(int64(BitBlockNotFound), s.MarkFree())
+	assert.Zero(s.Len64())
+	assert.Zero(s.Cap64())
+	assert.False(s.HasRoom())
+	assert.Empty(slices.Collect(s.SetBits()))
+	assert.True(errors.Is(s.Close(), os.ErrClosed))
+}
+
+func TestSharedBitSet(t *testing.T) {
+	s, err := OpenShared(filepath.Join(t.TempDir(), "shared"), 100)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	testBitSet(t, s)
+}
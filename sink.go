@@ -0,0 +1,76 @@
+package bitarray
+
+import "math"
+
+// Sink receives the changes of the bits of an array, see Subscribe.
+type Sink interface {
+	// OnChange is called for every change, in the order they are made.
+	OnChange(c Change)
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(c Change)
+
+// OnChange calls f(c).
+func (f SinkFunc) OnChange(c Change) {
+	f(c)
+}
+
+// Backpressure defines what happens to the changes for a Sink when its
+// buffer is full.
+type Backpressure int
+
+const (
+	// BackpressureDrop drops the changes and counts them in the Dropped
+	// field of the next delivered change, like Watch, so the writers are
+	// never slowed down.
+	BackpressureDrop Backpressure = iota
+
+	// BackpressureBlock makes the writers wait for room in the buffer, so
+	// no change is lost. The sink must not use the array, which it would
+	// wait for.
+	BackpressureBlock
+)
+
+// Subscribe forwards every change of the bits of the array, including those
+// of bulk operations, to sink, e.g. to feed an audit pipeline with the
+// allocations and releases. The changes are queued in a buffer of the
+// specified size, 1024 if it is not positive, and delivered by a goroutine
+// of their own, so a slow sink does not slow the writers down until the
+// buffer is full, when the policy applies. Like Watch, the subscription
+// keeps a copy of the bits to find the changes of bulk operations. The
+// returned function cancels the subscription and waits for the queued
+// changes to be delivered.
+func (b *BitArray) Subscribe(sink Sink, buffer int, policy Backpressure) (cancel func()) {
+	if buffer <= 0 {
+		buffer = watchBuffer
+	}
+
+	w := &watch{
+		b:    b,
+		to:   math.MaxInt64,
+		ch:   make(chan Change, buffer),
+		wait: policy == BackpressureBlock,
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for c := range w.ch {
+			sink.OnChange(c)
+		}
+	}()
+
+	b.lock()
+	w.grow(b.capacity.Get64())
+	w.sync(func(Change) {})
+	b.attach(w)
+	b.unlock()
+
+	return func() {
+		b.Unwatch(w.ch)
+		<-done
+	}
+}
@@ -0,0 +1,75 @@
+package bitarray
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArraySubscribe(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10, WithAutoGrow(), WithBaseOffset(100), WithInitialSet(3))
+
+	var (
+		mu  sync.Mutex
+		got []Change
+	)
+
+	cancel := b.Subscribe(SinkFunc(func(c Change) {
+		mu.Lock()
+		got = append(got, c)
+		mu.Unlock()
+	}), 0, BackpressureDrop)
+
+	assert.EqualValues(100, b.MarkFree())
+	b.Unmark(103)
+	b.Mark(170) // grows
+	b.Or(newMarked(100, 1))
+	b.Reset()
+
+	cancel()
+	cancel()
+
+	assert.Equal([]Change{
+		{Index: 100, Mark: true},
+		{Index: 103, Mark: false},
+		{Index: 170, Mark: true},
+		{Index: 101, Mark: true},
+		{Index: 100, Mark: false},
+		{Index: 101, Mark: false},
+		{Index: 170, Mark: false},
+	}, got)
+
+	b.Mark(105)
+	assert.Len(got, 7)
+}
+
+func TestBitArraySubscribeBackpressure(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(1000)
+
+	release := make(chan struct{})
+	n := 0
+
+	cancel := b.Subscribe(SinkFunc(func(c Change) {
+		<-release
+		assert.Zero(c.Dropped)
+		n++
+	}), 1, BackpressureBlock)
+
+	go func() {
+		for i := 0; i < 1000; i++ {
+			release <- struct{}{}
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		b.MarkFree()
+	}
+
+	cancel()
+	assert.Equal(1000, n)
+}
@@ -0,0 +1,105 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// The sparse encoding has the header and the checksum of the binary
+// encoding with version 2, and lists the set bits instead of the words:
+//
+//	magic    [4]byte    "BARR"
+//	version  uint8      2
+//	capacity uint64     little-endian
+//	count    uvarint    number of set bits
+//	deltas   []uvarint  the index of the first set bit, then for every next
+//	                    one the distance from the previous one minus 1
+//	checksum uint32     little-endian CRC-32 (IEEE) of all the preceding bytes
+const sparseVersion = 2
+
+// MarshalSparse encodes the array like MarshalBinary, but with the indexes
+// of the set bits as varint deltas, so a mostly empty array takes a few
+// bytes per set bit instead of a bit per index. The result is decoded by
+// UnmarshalBinary.
+func (b *BitArray) MarshalSparse() ([]byte, error) {
+	b.rlock()
+	defer b.runlock()
+
+	data := make([]byte, 0, binaryHeaderLen+binary.MaxVarintLen64+int(b.count.Get64())*2+checksumLen)
+	data = append(data, binaryMagic...)
+	data = append(data, sparseVersion)
+	data = binary.LittleEndian.AppendUint64(data, uint64(b.capacity.Get64()))
+	data = binary.AppendUvarint(data, uint64(b.count.Get64()))
+
+	next := int64(0)
+
+	for i := int64(0); i < b.size; i++ {
+		for block := b.blocks[i]; block != 0; block &= block - 1 {
+			index := (i * blockSize) + block.ffs()
+			data = binary.AppendUvarint(data, uint64(index-next))
+			next = index + 1
+		}
+	}
+
+	return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// unmarshalSparse decodes the payload of the sparse encoding. The payload is
+// validated before the storage is allocated, and the capacity, which a
+// small payload may claim to be huge, is allocated only if the platform can.
+func (b *BitArray) unmarshalSparse(capacity int64, payload []byte) error {
+	if err := checkCapacity(capacity); err != nil {
+		return fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	count, n := binary.Uvarint(payload)
+	if n <= 0 || count > uint64(capacity) || count > uint64(len(payload)-n) {
+		return fmt.Errorf("%w: bad count for capacity %d", ErrFormat, capacity)
+	}
+
+	deltas := payload[n:]
+
+	if err := sparseBits(deltas, count, capacity, func(int64) {}); err != nil {
+		return err
+	}
+
+	blocks, err := b.tryAlloc(capacity/blockSize + 1)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+
+	sparseBits(deltas, count, capacity, func(index int64) {
+		i, j := bitIndexAndNum(index)
+		blocks[i].mark(j)
+	})
+
+	b.install(capacity, blocks)
+
+	return nil
+}
+
+// sparseBits decodes the count index deltas of the sparse encoding, calling
+// fn for every index, and checks that they lie below the capacity and take
+// all of data.
+func sparseBits(data []byte, count uint64, capacity int64, fn func(index int64)) error {
+	next := int64(0)
+
+	for k := uint64(0); k < count; k++ {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 || delta >= uint64(capacity-next) {
+			return fmt.Errorf("%w: bad index delta after %d", ErrFormat, next)
+		}
+
+		data = data[n:]
+		index := next + int64(delta)
+		fn(index)
+		next = index + 1
+	}
+
+	if len(data) != 0 {
+		return fmt.Errorf("%w: %d bytes after the set bits", ErrFormat, len(data))
+	}
+
+	return nil
+}
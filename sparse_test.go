@@ -0,0 +1,74 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayMarshalSparse(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(1<<20, 0, 1, 300, 1<<20-1)
+
+	data, err := b.MarshalSparse()
+	assert.NoError(err)
+	assert.Less(len(data), 30)
+
+	c := NewBitArray(10)
+	assert.NoError(c.UnmarshalBinary(data))
+	assert.Equal(b.Cap(), c.Cap())
+	assert.Equal(b.FormatRanges(), c.FormatRanges())
+	assert.NoError(c.Validate())
+
+	data, err = NewBitArray(0).MarshalSparse()
+	assert.NoError(err)
+	assert.NoError(c.UnmarshalBinary(data))
+	assert.Equal(0, c.Cap())
+}
+
+func TestBitArrayUnmarshalSparseInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	sparse := func(capacity uint64, values ...uint64) []byte {
+		data := append([]byte(binaryMagic), sparseVersion)
+		data = binary.LittleEndian.AppendUint64(data, capacity)
+
+		for _, v := range values {
+			data = binary.AppendUvarint(data, v)
+		}
+
+		return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+	}
+
+	b := NewBitArray(0)
+	assert.NoError(b.UnmarshalBinary(sparse(10, 2, 3, 5)))
+	assert.Equal("3,9", b.FormatRanges())
+
+	for _, data := range [][]byte{
+		sparse(10, 11),       // count beyond the capacity
+		sparse(10, 2, 3),     // missing delta
+		sparse(10, 2, 3, 6),  // index beyond the capacity
+		sparse(10, 1, 3, 0),  // trailing bytes
+		sparse(1<<63, 0),     // negative capacity
+		sparse(10, 2, 9, 0),  // after the last bit
+		sparse(10),           // missing count
+		sparse(1<<40, 1<<20), // more bits than bytes
+		sparse(uint64(MaxCapacity)+1, 0),
+	} {
+		assert.True(errors.Is(b.UnmarshalBinary(data), ErrFormat))
+	}
+
+	if strconv.IntSize == 64 {
+		// more than the address space, so the allocation fails
+		err := b.UnmarshalBinary(sparse(uint64(MaxCapacity), 0))
+		assert.True(errors.Is(err, ErrFormat))
+		assert.True(errors.Is(err, ErrCapacity))
+	}
+
+	assert.Equal("3,9", b.FormatRanges())
+}
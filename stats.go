@@ -0,0 +1,95 @@
+package bitarray
+
+import "sync/atomic"
+
+// Stats are the counters of the operations of an array collected under
+// WithStats, e.g. to follow the health of an allocator over time.
+type Stats struct {
+	// Marks is the number of bits set to true by the methods changing
+	// bits, words, ranges or the whole array, like Set, MarkFree, ApplyWord,
+	// ParseRanges, Fill or Or, and by Tx. Reset and decoding are not
+	// counted.
+	Marks int64
+
+	// Unmarks is the number of bits set to false, counted like Marks.
+	Unmarks int64
+
+	// FailedMarkFree is the number of MarkFree calls, and the likes of
+	// MarkFreeRandom and Tx.Reserve, that found no free bit or were
+	// rejected by the rate limit.
+	FailedMarkFree int64
+
+	// FullScans is the number of searches for a free bit that found none
+	// from the scan pointer to the end of the array and wrapped around to
+	// its first block.
+	FullScans int64
+
+	// Resets is the number of Reset calls.
+	Resets int64
+}
+
+// tally holds the counters of Stats.
+type tally struct {
+	marks   atomic.Int64
+	unmarks atomic.Int64
+	failed  atomic.Int64
+	wraps   atomic.Int64
+	resets  atomic.Int64
+}
+
+// WithStats makes the array count its operations, see Stats. The counters
+// cost an atomic addition per counted operation, and the bulk operations
+// process the array block by block, like for a Durable.
+func WithStats() Option {
+	return func(c *config) {
+		c.tally = new(tally)
+	}
+}
+
+// Stats returns the counters of the operations since the array was created
+// or ResetStats was called. They are zero unless the array was created with
+// WithStats.
+func (b *BitArray) Stats() Stats {
+	if b.tally == nil {
+		return Stats{}
+	}
+
+	t := b.tally
+
+	return Stats{
+		Marks:          t.marks.Load(),
+		Unmarks:        t.unmarks.Load(),
+		FailedMarkFree: t.failed.Load(),
+		FullScans:      t.wraps.Load(),
+		Resets:         t.resets.Load(),
+	}
+}
+
+// ResetStats returns the counters like Stats and sets them to zero, so the
+// counters of consecutive calls add up to the totals. Each counter is reset
+// atomically, but not all of them at once.
+func (b *BitArray) ResetStats() Stats {
+	if b.tally == nil {
+		return Stats{}
+	}
+
+	t := b.tally
+
+	return Stats{
+		Marks:          t.marks.Swap(0),
+		Unmarks:        t.unmarks.Swap(0),
+		FailedMarkFree: t.failed.Swap(0),
+		FullScans:      t.wraps.Swap(0),
+		Resets:         t.resets.Swap(0),
+	}
+}
+
+// tallied counts index as a failed allocation if it is BitBlockNotFound and
+// returns it.
+func (b *BitArray) tallied(index int64) int64 {
+	if index == BitBlockNotFound && b.tally != nil {
+		b.tally.failed.Add(1)
+	}
+
+	return index
+}
@@ -0,0 +1,74 @@
+package bitarray
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayStats(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10, WithStats())
+
+	for range 10 {
+		b.MarkFree()
+	}
+
+	assert.Equal(int64(BitBlockNotFound), b.MarkFree())
+	b.Mark(3)
+	b.Unmark(3)
+	b.Set(4, false)
+	assert.Equal(2, b.UnmarkMany([]int64{5, 6, 20}))
+	b.Reset()
+
+	assert.Equal(Stats{Marks: 10, Unmarks: 4, FailedMarkFree: 1, Resets: 1}, b.Stats())
+	assert.Equal(Stats{Marks: 10, Unmarks: 4, FailedMarkFree: 1, Resets: 1}, b.ResetStats())
+	assert.Equal(Stats{}, b.Stats())
+
+	assert.Equal(Stats{}, NewBitArray(10).Stats())
+	assert.Equal(Stats{}, NewBitArray(10).ResetStats())
+}
+
+func TestBitArrayStatsBlocks(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(200, WithStats())
+
+	assert.True(b.TryMarkMask(0, 0b11))
+	b.ApplyWord(0, 0b100, 0b1)
+	b.MapBlocks(func(w uint64) uint64 { return w | 1<<63 })
+	CopyRange(b, 100, newMarked(10, 1, 2), 0, 10)
+	assert.Equal(Stats{Marks: 8, Unmarks: 1}, b.ResetStats())
+
+	b.Xor(newMarked(200, 2, 3))
+	assert.Equal(Stats{Marks: 1, Unmarks: 1}, b.ResetStats())
+
+	n := b.Len64()
+	b.Fill()
+	assert.Equal(Stats{Marks: 200 - n}, b.ResetStats())
+
+	b.Xor(b)
+	assert.Equal(Stats{Unmarks: 200}, b.ResetStats())
+
+	b, err := ParseRanges("1-3,7", 10, WithStats())
+	assert.NoError(err)
+	assert.Equal(Stats{Marks: 4}, b.Stats())
+	assert.NoError(b.Validate())
+
+	b = NewBitArray(10, WithStats(), WithRateLimit(1, 1, RateLimitFail))
+	assert.NotEqual(int64(BitBlockNotFound), b.MarkFreeRandom(nil))
+	assert.Equal(int64(BitBlockNotFound), b.MarkFreeRandom(nil))
+	assert.Equal(Stats{Marks: 1, FailedMarkFree: 1}, b.Stats())
+}
+
+func TestBitArrayStatsFullScans(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(3*blockSize, WithStats(), WithRoundRobin(blockSize))
+	b.setRange(blockSize, 3*blockSize, bitBlockMark)
+
+	assert.EqualValues(0, b.MarkFree())
+	assert.EqualValues(1, b.MarkFree())
+	assert.EqualValues(1, b.Stats().FullScans)
+}
@@ -0,0 +1,121 @@
+package bitarray
+
+import (
+	"sync"
+	"time"
+)
+
+// SyncPolicy defines when a persistent array, such as Durable or Shared,
+// commits its changes to stable storage. The zero value commits on Close
+// and explicit Sync calls only.
+type SyncPolicy struct {
+	mode     syncMode
+	n        int
+	interval time.Duration
+}
+
+type syncMode int
+
+const (
+	syncOnClose syncMode = iota
+	syncAlways
+	syncEveryN
+	syncInterval
+)
+
+// SyncAlways commits every change before the modifying method returns.
+func SyncAlways() SyncPolicy {
+	return SyncPolicy{mode: syncAlways}
+}
+
+// SyncEvery commits after every n changes.
+func SyncEvery(n int) SyncPolicy {
+	if n <= 1 {
+		return SyncAlways()
+	}
+
+	return SyncPolicy{mode: syncEveryN, n: n}
+}
+
+// SyncInterval commits the pending changes every d in the background.
+func SyncInterval(d time.Duration) SyncPolicy {
+	return SyncPolicy{mode: syncInterval, interval: d}
+}
+
+// SyncOnClose commits the changes only on Close and explicit Sync calls.
+func SyncOnClose() SyncPolicy {
+	return SyncPolicy{}
+}
+
+// WithSyncPolicy sets the sync policy of a persistent array.
+func WithSyncPolicy(policy SyncPolicy) Option {
+	return func(c *config) {
+		c.sync = policy
+	}
+}
+
+// syncer counts the pending changes of a persistent array and decides when
+// they are committed. It is guarded by the lock of its owner.
+type syncer struct {
+	policy  SyncPolicy
+	pending int
+	stop    chan struct{}
+	once    sync.Once
+	done    sync.WaitGroup
+}
+
+// changed records a change and reports whether it must be committed now.
+func (s *syncer) changed() bool {
+	s.pending++
+
+	switch s.policy.mode {
+	case syncAlways:
+		return true
+
+	case syncEveryN:
+		return s.pending >= s.policy.n
+	}
+
+	return false
+}
+
+// start runs flush every interval of an interval policy, until stop is
+// called. flush takes the lock of the owner and commits the pending
+// changes, if any.
+func (s *syncer) start(flush func()) {
+	if s.policy.mode != syncInterval || s.policy.interval <= 0 {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.done.Add(1)
+
+	go func() {
+		defer s.done.Done()
+
+		ticker := time.NewTicker(s.policy.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				flush()
+
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// halt stops the background flushes and waits for a running one. Callers
+// must not hold the lock taken by flush.
+func (s *syncer) halt() {
+	s.once.Do(func() {
+		if s.stop != nil {
+			close(s.stop)
+		}
+	})
+
+	s.done.Wait()
+}
@@ -0,0 +1,55 @@
+package bitarray
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	s := syncer{policy: SyncAlways()}
+	assert.True(s.changed())
+
+	s = syncer{policy: SyncEvery(3)}
+	assert.False(s.changed())
+	assert.False(s.changed())
+	assert.True(s.changed())
+
+	s = syncer{policy: SyncOnClose()}
+	assert.False(s.changed())
+	assert.Equal(1, s.pending)
+
+	assert.Equal(SyncAlways(), SyncEvery(1))
+}
+
+func TestDurableSyncPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+
+	d, err := Recover(filepath.Join(dir, "every"), 100, WithSyncPolicy(SyncEvery(2)))
+	assert.NoError(err)
+
+	d.Mark(1)
+	assert.Equal(1, d.wal.sync.pending)
+	d.Mark(2)
+	assert.Equal(0, d.wal.sync.pending)
+	assert.NoError(d.Close())
+
+	d, err = Recover(filepath.Join(dir, "interval"), 100, WithSyncPolicy(SyncInterval(time.Millisecond)))
+	assert.NoError(err)
+
+	d.Mark(1)
+	assert.Eventually(func() bool {
+		d.lock()
+		defer d.unlock()
+
+		return d.wal.sync.pending == 0
+	}, time.Second, time.Millisecond)
+	assert.NoError(d.Close())
+	assert.NoError(d.Close())
+}
@@ -0,0 +1,133 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax is returned when a textual representation cannot be parsed.
+var ErrSyntax = errors.New("bitarray: invalid syntax")
+
+// Parse creates a BitArray from a string of '0' and '1' characters, where
+// the character at position i is the value of bit i. The capacity of the
+// array is the length of the string. Parse is the inverse of String.
+func Parse(s string, opts ...Option) (*BitArray, error) {
+	b := NewBitArray(int64(len(s)), opts...)
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '1':
+			j, k := bitIndexAndNum(int64(i))
+			b.blocks[j].mark(k)
+
+		case '0':
+			// bits are clear by default
+
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q at position %d", ErrSyntax, s[i], i)
+		}
+	}
+
+	b.recount()
+
+	return b, nil
+}
+
+// String returns the bits of the array as a string of '0' and '1'
+// characters, the lowest index first.
+func (b *BitArray) String() string {
+	b.rlock()
+	defer b.runlock()
+
+	var sb strings.Builder
+
+	capacity := b.capacity.Get64()
+	sb.Grow(toInt(capacity))
+
+	for index := int64(0); index < capacity; index++ {
+		i, j := bitIndexAndNum(index)
+
+		if b.blocks[i].value(j) {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+
+	return sb.String()
+}
+
+// FormatRanges returns the set bits in the range syntax used by Linux cpusets:
+// comma-separated indexes and inclusive ranges, e.g. "0-3,7,16-31".
+func (b *BitArray) FormatRanges() string {
+	b.rlock()
+	defer b.runlock()
+
+	var buf []byte
+
+	b.forEachRun(func(start, end int64) bool {
+		if len(buf) > 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = appendRange(buf, start, end)
+
+		return true
+	})
+
+	return string(buf)
+}
+
+// ParseRanges creates a BitArray of the specified capacity with the bits
+// listed in the range syntax of FormatRanges set. Whitespace around the
+// elements is ignored.
+func ParseRanges(s string, capacity int64, opts ...Option) (*BitArray, error) {
+	b, err := NewBitArrayE(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(s) == "" {
+		return b, nil
+	}
+
+	for _, elem := range strings.Split(s, ",") {
+		first, last, err := parseRange(strings.TrimSpace(elem))
+		if err != nil {
+			return nil, err
+		}
+
+		if ok, err := b.checkIndex(last, true); !ok {
+			if err == nil {
+				err = ErrOutOfRange
+			}
+
+			return nil, fmt.Errorf("%w: %q", err, elem)
+		}
+
+		b.setRange(first, last+1, bitBlockMark)
+	}
+
+	return b, nil
+}
+
+// parseRange parses "first-last" or a single index.
+func parseRange(s string) (first, last int64, err error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+
+	if first, err = strconv.ParseInt(lo, 10, 64); err == nil {
+		last = first
+
+		if isRange {
+			last, err = strconv.ParseInt(hi, 10, 64)
+		}
+	}
+
+	if err != nil || first < 0 || last < first {
+		return 0, 0, fmt.Errorf("%w: bad range %q", ErrSyntax, s)
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+
+	b, err := Parse("0101")
+	assert.NoError(err)
+	assert.Equal(4, b.Cap())
+	assert.Equal(2, b.Len())
+	assert.False(b.Get(0))
+	assert.True(b.Get(1))
+	assert.True(b.Get(3))
+
+	_, err = Parse("01x1")
+	assert.True(errors.Is(err, ErrSyntax))
+
+	b, err = Parse("")
+	assert.NoError(err)
+	assert.Zero(b.Cap())
+}
+
+func TestBitArrayString(t *testing.T) {
+	assert := assert.New(t)
+
+	const s = "1000000000000000000000000000000000000000000000000000000000000000011"
+
+	b, err := Parse(s)
+	assert.NoError(err)
+	assert.Equal(s, b.String())
+	assert.NoError(b.Validate())
+
+	assert.Equal("", NewBitArray(0).String())
+	assert.Equal("00100", newMarked(5, 2).String())
+}
+
+func TestBitArrayFormatRanges(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(300, 0, 1, 2, 3, 7, 64, 65, 299)
+	assert.Equal("0-3,7,64-65,299", b.FormatRanges())
+	assert.Equal("", NewBitArray(10).FormatRanges())
+	assert.Equal("0-9", NewBitArrayFull(10).FormatRanges())
+}
+
+func TestParseRanges(t *testing.T) {
+	assert := assert.New(t)
+
+	b, err := ParseRanges("0-3, 7,16-31,64-200", 256)
+	assert.NoError(err)
+	assert.Equal(4+1+16+137, b.Len())
+	assert.Equal("0-3,7,16-31,64-200", b.FormatRanges())
+	assert.NoError(b.Validate())
+
+	b, err = ParseRanges(" ", 10)
+	assert.NoError(err)
+	assert.Zero(b.Len())
+
+	for _, s := range []string{"1-", "a", "3-1", "-1", "1,,2"} {
+		_, err = ParseRanges(s, 10)
+		assert.True(errors.Is(err, ErrSyntax), s)
+	}
+
+	_, err = ParseRanges("5-10", 10)
+	assert.True(errors.Is(err, ErrOutOfRange))
+
+	b, err = ParseRanges("5-10", 0, WithAutoGrow())
+	assert.NoError(err)
+	assert.Equal(11, b.Cap())
+}
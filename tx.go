@@ -0,0 +1,161 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrFull is returned when an allocation finds no free bit.
+var ErrFull = errors.New("bitarray: no free bit")
+
+// Tx is a batch of operations applied by Update. It is valid only during the
+// call of the function passed to Update.
+type Tx struct {
+	b        *BitArray
+	undo     []txUndo
+	curIndex int64
+	reports  []txReport
+}
+
+// txUndo restores the previous value of a bit changed by a Tx.
+type txUndo struct {
+	index int64
+	mark  bool
+}
+
+// txReport is the outcome of a Reserve, held for the instrumentation until
+// the lock is released.
+type txReport struct {
+	index   int64
+	latency time.Duration
+}
+
+// Update runs fn with a Tx under the write lock of the array, so the
+// operations of the Tx are applied atomically with respect to the other
+// goroutines. If fn returns an error, or panics, every change of the Tx is
+// rolled back before the error is returned or the panic is propagated. A
+// capacity grown by the Tx is kept.
+func (b *BitArray) Update(fn func(tx *Tx) error) (err error) {
+	tx := &Tx{b: b}
+
+	committed := false
+	defer func() {
+		tx.report(committed) // after the unlock
+	}()
+
+	b.lock()
+	defer b.unlock()
+
+	tx.curIndex = b.curIndex
+
+	defer func() {
+		if !committed {
+			tx.rollback()
+		}
+	}()
+
+	if err = fn(tx); err == nil {
+		committed = true
+	}
+
+	return
+}
+
+// Get returns the value of the bit at the specified index, including the
+// changes of the Tx.
+func (tx *Tx) Get(index int64) bool {
+	res, _ := tx.b.get(tx.b.in(index))
+	return res
+}
+
+// Mark sets the bit at the specified index to true. Indexes are handled
+// like by MarkE.
+func (tx *Tx) Mark(index int64) error {
+	return tx.set(index, bitBlockMark)
+}
+
+// Unmark sets the bit at the specified index to false. Indexes are handled
+// like by UnmarkE.
+func (tx *Tx) Unmark(index int64) error {
+	return tx.set(index, bitBlockUnmark)
+}
+
+// Reserve sets a free bit to true like MarkFree and returns its index. It
+// returns ErrFull if the array has no room. The outcome is reported to the
+// instrumentation once Update released the lock, unless the allocation is
+// rolled back. Reserve is exempt from the rate limit of WithRateLimit, which
+// would otherwise wait with the lock held.
+func (tx *Tx) Reserve() (int64, error) {
+	if index := tx.reserve(); index != BitBlockNotFound {
+		return index, nil
+	}
+
+	return BitBlockNotFound, ErrFull
+}
+
+func (tx *Tx) reserve() int64 {
+	if tx.b.inst == nil {
+		return tx.b.tallied(tx.b.out(tx.allocate()))
+	}
+
+	start := time.Now()
+	index := tx.b.tallied(tx.b.out(tx.allocate()))
+	tx.reports = append(tx.reports, txReport{index, time.Since(start)})
+
+	return index
+}
+
+// report passes the outcomes of Reserve to the instrumentation, dropping the
+// allocations of a Tx that was rolled back.
+func (tx *Tx) report(committed bool) {
+	for _, r := range tx.reports {
+		switch {
+		case r.index == BitBlockNotFound:
+			tx.b.inst.Exhausted(r.latency)
+
+		case committed:
+			tx.b.inst.Allocated(r.index, r.latency)
+		}
+	}
+}
+
+func (tx *Tx) allocate() int64 {
+	b := tx.b
+
+	if !b.HasRoom() {
+		return BitBlockNotFound
+	}
+
+	index, cursor := b.peek()
+	if index != BitBlockNotFound {
+		b.advance(cursor)
+		b.claim(index)
+		tx.undo = append(tx.undo, txUndo{index, bitBlockUnmark})
+	}
+
+	return index
+}
+
+func (tx *Tx) set(index int64, mark bool) error {
+	changed, err := tx.b.set(tx.b.in(index), mark)
+	if err != nil {
+		return fmt.Errorf("%w: %d", err, index)
+	}
+
+	if changed {
+		tx.undo = append(tx.undo, txUndo{tx.b.in(index), !mark})
+	}
+
+	return nil
+}
+
+// rollback undoes the changes of the Tx in reverse order.
+func (tx *Tx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.b.set(tx.undo[i].index, tx.undo[i].mark)
+	}
+
+	tx.undo = nil
+	tx.b.curIndex = tx.curIndex
+}
@@ -0,0 +1,117 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(10, 0, 5)
+
+	err := b.Update(func(tx *Tx) error {
+		index, err := tx.Reserve()
+		assert.Equal(int64(1), index)
+		assert.True(tx.Get(1))
+
+		if err == nil {
+			err = tx.Unmark(5)
+		}
+
+		if err == nil {
+			err = tx.Mark(9)
+		}
+
+		return err
+	})
+	assert.NoError(err)
+	assert.Equal("0-1,9", b.FormatRanges())
+
+	failed := errors.New("failed")
+
+	err = b.Update(func(tx *Tx) error {
+		for {
+			if _, err := tx.Reserve(); err != nil {
+				assert.True(errors.Is(err, ErrFull))
+				break
+			}
+		}
+
+		tx.Unmark(0)
+		tx.Mark(0)
+		tx.Unmark(9)
+
+		return failed
+	})
+	assert.Equal(failed, err)
+	assert.Equal("0-1,9", b.FormatRanges())
+	assert.Equal(3, b.Len())
+	assert.Equal(int64(2), b.MarkFree())
+
+	err = b.Update(func(tx *Tx) error {
+		tx.Mark(3)
+		return tx.Mark(10)
+	})
+	assert.True(errors.Is(err, ErrOutOfRange))
+	assert.Equal("0-2,9", b.FormatRanges())
+	assert.NoError(b.Validate())
+}
+
+// lockingInstrumentation reads the array, which needs its lock.
+type lockingInstrumentation struct {
+	testInstrumentation
+	b *BitArray
+}
+
+func (i *lockingInstrumentation) Allocated(index int64, latency time.Duration) {
+	if i.b.Get(index) {
+		i.testInstrumentation.Allocated(index, latency)
+	}
+}
+
+func TestBitArrayUpdateInstrumentation(t *testing.T) {
+	assert := assert.New(t)
+
+	inst := &lockingInstrumentation{}
+	b := NewBitArray(3, WithInstrumentation(inst), WithRateLimit(1, 1, RateLimitFail))
+	inst.b = b
+
+	assert.NoError(b.Update(func(tx *Tx) error {
+		for range 4 {
+			tx.Reserve() // not throttled
+		}
+
+		assert.Empty(inst.allocated)
+		return nil
+	}))
+	assert.Equal([]int64{0, 1, 2}, inst.allocated)
+	assert.Equal(1, inst.exhausted)
+
+	b.Reset()
+	b.Update(func(tx *Tx) error {
+		tx.Reserve()
+		return errors.New("failed")
+	})
+	assert.Len(inst.allocated, 3) // rolled back
+}
+
+func TestBitArrayUpdatePanic(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(10)
+
+	assert.Panics(func() {
+		b.Update(func(tx *Tx) error {
+			tx.Mark(1)
+			panic("boom")
+		})
+	})
+
+	assert.Equal(0, b.Len())
+	b.Mark(2)
+	assert.Equal("2", b.FormatRanges())
+}
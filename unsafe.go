@@ -0,0 +1,44 @@
+//go:build !purego
+
+package bitarray
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+// overlay returns the number of blocks common to x and y and the number of
+// words overlaying them.
+func overlay(x, y []BitBlock) (n, w int) {
+	n = min(len(x), len(y))
+	if blockSize != wordSize {
+		return n, 0
+	}
+
+	return n, n
+}
+
+// asWords returns the first w words overlaying blocks.
+func asWords(blocks []BitBlock, w int) []uint64 {
+	return unsafe.Slice((*uint64)(unsafe.Pointer(&blocks[0])), w)
+}
+
+// asBlocks returns the blocks overlaying words, or nil if their bits do not
+// line up, which is the case of 32-bit blocks on big-endian platforms.
+func asBlocks(words []uint64) []BitBlock {
+	if len(words) == 0 || blockSize != wordSize && binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		return nil
+	}
+
+	return unsafe.Slice((*BitBlock)(unsafe.Pointer(unsafe.SliceData(words))), int64(len(words))*(wordSize/blockSize))
+}
+
+// blocksAddr returns the address of the first block.
+func blocksAddr(blocks []BitBlock) uintptr {
+	return uintptr(unsafe.Pointer(unsafe.SliceData(blocks)))
+}
+
+// addr returns the address of b, which orders the locks of several arrays.
+func addr(b *BitArray) uintptr {
+	return uintptr(unsafe.Pointer(b))
+}
@@ -0,0 +1,74 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalid is returned by Validate when the internal state of a BitArray
+// violates its invariants.
+var ErrInvalid = errors.New("bitarray: invalid state")
+
+// Validate checks the invariants of the array: the storage matches the
+// capacity, the number of set bits matches the counter and the count cache,
+// the scan pointer is in range and the bits beyond the capacity, up to the
+// end of the last block, are zero. It is intended for debug builds and for
+// data restored from untrusted sources.
+func (b *BitArray) Validate() error {
+	b.rlock()
+	defer b.runlock()
+
+	capacity := b.capacity.Get64()
+
+	if capacity < 0 {
+		return fmt.Errorf("%w: negative capacity %d", ErrInvalid, capacity)
+	}
+
+	if b.size != int64(len(b.blocks)) || b.size*blockSize < capacity {
+		return fmt.Errorf("%w: %d blocks of storage for capacity %d", ErrInvalid, len(b.blocks), capacity)
+	}
+
+	if b.curIndex < 0 || (b.curIndex >= b.size && b.curIndex != 0) {
+		return fmt.Errorf("%w: scan pointer %d outside of %d blocks", ErrInvalid, b.curIndex, b.size)
+	}
+
+	var n int64
+
+	for i := int64(0); i < b.size-1; i++ {
+		if b.blocks[i]&^b.validMask(i) != 0 {
+			return fmt.Errorf("%w: bits set beyond capacity in block %d", ErrInvalid, i)
+		}
+
+		n += b.blocks[i].popcount()
+	}
+
+	if b.size > 0 {
+		if tail := b.blocks[b.size-1]; tail&^b.tailMask() != 0 {
+			return fmt.Errorf("%w: bits set beyond capacity in the last block", ErrInvalid)
+		}
+
+		n += b.blocks[b.size-1].popcount()
+	}
+
+	if count := b.count.Get64(); count != n {
+		return fmt.Errorf("%w: count %d, but %d bits are set", ErrInvalid, count, n)
+	}
+
+	if b.counts != nil {
+		end := b.size * blockSize
+
+		if int64(len(b.counts)) != (end+superBlockSize-1)/superBlockSize {
+			return fmt.Errorf("%w: %d count cache entries for %d blocks", ErrInvalid, len(b.counts), b.size)
+		}
+
+		for k, c := range b.counts {
+			from := int64(k) * superBlockSize
+
+			if n := b.scanRange(from, min(from+superBlockSize, end)); int64(c) != n {
+				return fmt.Errorf("%w: count cache %d, but %d bits are set at %d", ErrInvalid, c, n, from)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitArrayValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100)
+	assert.NoError(b.Validate())
+
+	for i := 0; i < 100; i++ {
+		b.MarkFree()
+	}
+	b.Unmark(50)
+	assert.NoError(b.Validate())
+}
+
+func TestBitArrayValidateCount(t *testing.T) {
+	b := NewBitArray(100)
+	b.Mark(1)
+	b.count.Set(2)
+
+	assert.True(t, errors.Is(b.Validate(), ErrInvalid))
+}
+
+func TestBitArrayValidateTail(t *testing.T) {
+	b := NewBitArray(100)
+	b.blocks[1].mark(40)
+	b.count.Set(1)
+
+	assert.True(t, errors.Is(b.Validate(), ErrInvalid))
+}
+
+func TestBitArrayValidateScanPointer(t *testing.T) {
+	b := NewBitArray(100)
+	b.curIndex = 5
+
+	assert.True(t, errors.Is(b.Validate(), ErrInvalid))
+}
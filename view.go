@@ -0,0 +1,64 @@
+package bitarray
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrViewUnsupported is the panic of View if the build cannot overlay blocks
+// on words.
+var ErrViewUnsupported = errors.New("bitarray: views are not supported by this build")
+
+// View creates a BitArray of the specified capacity over words, which the
+// caller owns, without copying them, e.g. to work with a bitmap memory-mapped
+// by another system. Bit i of words[k] is index k*64+i, like in ForEachWord.
+// The bits beyond the capacity in the word holding the last bit are cleared;
+// the words following it are neither read nor written. It panics with an
+// error wrapping ErrCapacity if the capacity is negative or exceeds
+// len(words)*64, and with ErrViewUnsupported if the build cannot overlay
+// blocks on words: with the purego tag, or with 32-bit blocks on big-endian
+// platforms.
+//
+// The array reads and writes words in place as long as it does not need
+// storage of its own, which it allocates when it grows, is decoded into, or
+// is written while a ReadTxn is open. The options that configure the storage,
+// like WithBlockSource, apply only to such new storage.
+//
+// The array synchronizes its own accesses only. The caller must not access
+// words while the array is in use, unless it serializes those accesses with
+// the array's, e.g. with WithLockStrategy(LockNone) and a lock of its own.
+func View(words []uint64, capacity int64, opts ...Option) *BitArray {
+	if capacity < 0 || capacity > int64(len(words))*wordSize {
+		panic(fmt.Errorf("%w: %d over %d words", ErrCapacity, capacity, len(words)))
+	}
+
+	blocks := asBlocks(words)
+	if blocks == nil {
+		if len(words) > 0 {
+			panic(ErrViewUnsupported)
+		}
+
+		return NewBitArray(0, opts...)
+	}
+
+	return NewBitArray(capacity, append(opts[:len(opts):len(opts)], func(c *config) {
+		c.view = blocks
+	})...)
+}
+
+// useView installs blocks as the storage of the array with the capacity
+// already set, clearing the bits beyond the capacity in the last block it
+// uses.
+func (b *BitArray) useView(blocks []BitBlock) {
+	size := max((b.capacity.Get64()+blockSize-1)/blockSize, 1)
+
+	b.blocks = blocks[:size:size] // growing moves to new storage
+	b.size = size
+
+	// clearing only stray bits leaves read-only words alone
+	if stray := b.blocks[size-1] &^ b.tailMask(); stray != 0 {
+		b.blocks[size-1] &^= stray
+	}
+
+	b.recount()
+}
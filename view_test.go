@@ -0,0 +1,61 @@
+package bitarray
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestView(t *testing.T) {
+	assert := assert.New(t)
+
+	if asBlocks(make([]uint64, 1)) == nil {
+		t.Skip("no views in this build")
+	}
+
+	words := []uint64{1<<0 | 1<<63, 1 << 1, 0xff00}
+
+	b := View(words, 136)
+	assert.Equal(136, b.Cap())
+	assert.Equal(3, b.Len())
+	assert.Equal("0,63,65", b.FormatRanges())
+	assert.Equal(uint64(0), words[2]) // beyond the capacity
+	assert.NoError(b.Validate())
+
+	b.Mark(64)
+	b.Unmark(0)
+	assert.Equal(uint64(1<<63), words[0])
+	assert.Equal(uint64(1<<0|1<<1), words[1])
+
+	words[1] = 0
+	b = View(words, 128, WithCountCache())
+	assert.Equal(1, b.Len())
+	assert.EqualValues(1, b.CountRange(0, 128))
+
+	b = View(words[:2], 128, WithAutoGrow())
+	b.Mark(200)
+	b.Mark(1)
+	assert.Equal(uint64(1<<63), words[0]) // grown into storage of its own
+
+	assert.Zero(View(nil, 0).Cap())
+
+	words = []uint64{^uint64(0), 0xff}
+	assert.Equal(64, View(words, 64).Len())
+	assert.Equal(uint64(0xff), words[1]) // beyond the words of the capacity
+
+	assert.Panics(func() { View(words, 3*64+1) })
+	assert.Panics(func() { View(words, -1) })
+}
+
+func TestViewUnsupported(t *testing.T) {
+	if asBlocks(make([]uint64, 1)) != nil {
+		t.Skip("views are supported")
+	}
+
+	defer func() {
+		assert.True(t, errors.Is(recover().(error), ErrViewUnsupported))
+	}()
+
+	View(make([]uint64, 1), 64)
+}
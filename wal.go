@@ -0,0 +1,448 @@
+package bitarray
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// journal receives the changes of an array. The methods are called with the
+// write lock held.
+type journal interface {
+	bit(index int64, mark bool)
+	block(i int64, v BitBlock)
+	reset()
+	grow(capacity int64)
+	replace()
+}
+
+// journals fans the changes out to several journals.
+type journals []journal
+
+func (js journals) bit(index int64, mark bool) {
+	for _, j := range js {
+		j.bit(index, mark)
+	}
+}
+
+func (js journals) block(i int64, v BitBlock) {
+	for _, j := range js {
+		j.block(i, v)
+	}
+}
+
+func (js journals) reset() {
+	for _, j := range js {
+		j.reset()
+	}
+}
+
+func (js journals) grow(capacity int64) {
+	for _, j := range js {
+		j.grow(capacity)
+	}
+}
+
+func (js journals) replace() {
+	for _, j := range js {
+		j.replace()
+	}
+}
+
+// attach adds j to the journals of the array. Callers hold the write lock.
+func (b *BitArray) attach(j journal) {
+	switch js := b.journal.(type) {
+	case nil:
+		b.journal = j
+
+	case journals:
+		b.journal = append(js[:len(js):len(js)], j)
+
+	default:
+		b.journal = journals{js, j}
+	}
+}
+
+// detach removes j from the journals of the array and reports whether it
+// was attached. Callers hold the write lock.
+func (b *BitArray) detach(j journal) bool {
+	js, ok := b.journal.(journals)
+	if !ok {
+		js = journals{b.journal}
+	}
+
+	i := slices.Index(js, j)
+	if i < 0 {
+		return false
+	}
+
+	switch js = slices.Delete(slices.Clone(js), i, i+1); len(js) {
+	case 0:
+		b.journal = nil
+
+	case 1:
+		b.journal = js[0]
+
+	default:
+		b.journal = js
+	}
+
+	return true
+}
+
+// A WAL record consists of
+//
+//	op    uint8
+//	arg   uint64  little-endian index, block number or capacity
+//	value uint64  little-endian block value of walBlock
+//	crc   uint32  little-endian CRC-32 (IEEE) of the preceding bytes
+const (
+	walMark byte = iota + 1
+	walUnmark
+	walBlock
+	walReset
+	walGrow
+
+	walRecordLen = 1 + 8 + 8 + 4
+
+	defaultSnapshotEvery = 1 << 20
+)
+
+// Durable is a BitArray whose changes survive crashes. Every change is
+// appended to a write-ahead log before the modifying method returns, and the
+// state is periodically written to a snapshot, which truncates the log.
+// WithSyncPolicy sets when the log is synced to stable storage; by default
+// it is synced on Sync and Close only.
+//
+// The methods of the embedded BitArray cannot report I/O errors, so the
+// first failure is kept and reported by Err, Sync and Close; the changes
+// after a failure are not persisted.
+type Durable struct {
+	*BitArray
+	wal *wal
+}
+
+type wal struct {
+	b       *BitArray
+	path    string
+	file    *os.File
+	records int
+	every   int
+	err     error
+	sync    syncer
+	buf     [walRecordLen]byte
+}
+
+// WithSnapshotEvery sets the number of log records after which a Durable
+// array writes a snapshot and truncates its log, 1<<20 by default.
+func WithSnapshotEvery(n int) Option {
+	return func(c *config) {
+		c.snapshotEvery = n
+	}
+}
+
+// Recover opens the Durable array persisted under path, which names the
+// snapshot path+".snap" and the log path+".wal". The state is restored from
+// the snapshot followed by the records of the log; a torn record at the end
+// of the log, left by a crash during a write, is discarded. If there is no
+// snapshot, the array starts empty with the specified capacity.
+func Recover(path string, capacity int64, opts ...Option) (*Durable, error) {
+	b, err := NewBitArrayE(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	w := &wal{b: b, path: path, every: c.snapshotEvery, sync: syncer{policy: c.sync}}
+	if w.every <= 0 {
+		w.every = defaultSnapshotEvery
+	}
+
+	file, err := os.OpenFile(w.logPath(), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	w.file = file
+
+	switch data, err := os.ReadFile(w.snapPath()); {
+	case err == nil:
+		if err = b.UnmarshalBinary(data); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", w.snapPath(), err)
+		}
+
+		if err = w.replay(); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", w.logPath(), err)
+		}
+
+	case errors.Is(err, fs.ErrNotExist):
+		// a new array: persist the capacity before the first record
+		if err = w.snapshot(); err != nil {
+			file.Close()
+			return nil, err
+		}
+
+	default:
+		file.Close()
+		return nil, err
+	}
+
+	b.attach(w)
+	d := &Durable{BitArray: b, wal: w}
+
+	w.sync.start(func() {
+		d.lock()
+		w.commit()
+		d.unlock()
+	})
+
+	return d, nil
+}
+
+// Snapshot writes the current state to the snapshot file and truncates the
+// log.
+func (d *Durable) Snapshot() error {
+	d.lock()
+	defer d.unlock()
+
+	if d.wal.err == nil {
+		d.wal.err = d.wal.snapshot()
+	}
+
+	return d.wal.err
+}
+
+// Sync commits the log to stable storage.
+func (d *Durable) Sync() error {
+	d.lock()
+	defer d.unlock()
+
+	d.wal.flush()
+
+	return d.wal.err
+}
+
+// Err returns the first error that occurred while persisting the changes.
+func (d *Durable) Err() error {
+	d.rlock()
+	defer d.runlock()
+
+	return d.wal.err
+}
+
+// Close commits the log to stable storage and closes it. The array remains
+// usable, but its changes are no longer persisted.
+func (d *Durable) Close() error {
+	d.wal.sync.halt()
+
+	d.lock()
+	defer d.unlock()
+
+	if !d.detach(d.wal) {
+		return d.wal.err
+	}
+
+	d.wal.flush()
+
+	if err := d.wal.file.Close(); d.wal.err == nil {
+		d.wal.err = err
+	}
+
+	return d.wal.err
+}
+
+func (w *wal) snapPath() string {
+	return w.path + ".snap"
+}
+
+func (w *wal) logPath() string {
+	return w.path + ".wal"
+}
+
+// replay applies the records of the log to the array and truncates the log
+// after the last complete record.
+func (w *wal) replay() error {
+	data, err := os.ReadFile(w.logPath())
+	if err != nil {
+		return err
+	}
+
+	n := 0
+
+	for ; n+walRecordLen <= len(data); n += walRecordLen {
+		r := data[n : n+walRecordLen]
+		if crc32.ChecksumIEEE(r[:walRecordLen-4]) != binary.LittleEndian.Uint32(r[walRecordLen-4:]) {
+			break
+		}
+
+		arg := int64(binary.LittleEndian.Uint64(r[1:]))
+		value := binary.LittleEndian.Uint64(r[9:])
+
+		switch r[0] {
+		case walMark, walUnmark:
+			w.b.set(arg, r[0] == walMark)
+
+		case walBlock:
+			if arg < 0 || arg >= w.b.size {
+				return fmt.Errorf("%w: block %d out of range", ErrFormat, arg)
+			}
+
+			w.b.setBlock(arg, BitBlock(value))
+
+		case walReset:
+			w.b.Reset()
+
+		case walGrow:
+			w.b.grow(arg)
+
+		default:
+			return fmt.Errorf("%w: unknown record %d", ErrFormat, r[0])
+		}
+	}
+
+	w.records = n / walRecordLen
+
+	if err = w.file.Truncate(int64(n)); err == nil {
+		_, err = w.file.Seek(int64(n), 0)
+	}
+
+	return err
+}
+
+func (w *wal) bit(index int64, mark bool) {
+	op := walUnmark
+	if mark == bitBlockMark {
+		op = walMark
+	}
+
+	w.append(op, uint64(index), 0)
+}
+
+func (w *wal) block(i int64, v BitBlock) {
+	w.append(walBlock, uint64(i), uint64(v))
+}
+
+func (w *wal) reset() {
+	w.append(walReset, 0, 0)
+}
+
+func (w *wal) grow(capacity int64) {
+	w.append(walGrow, uint64(capacity), 0)
+}
+
+func (w *wal) replace() {
+	if w.err == nil {
+		w.err = w.snapshot()
+	}
+}
+
+func (w *wal) append(op byte, arg, value uint64) {
+	if w.err != nil {
+		return
+	}
+
+	w.buf[0] = op
+	binary.LittleEndian.PutUint64(w.buf[1:], arg)
+	binary.LittleEndian.PutUint64(w.buf[9:], value)
+	binary.LittleEndian.PutUint32(w.buf[17:], crc32.ChecksumIEEE(w.buf[:17]))
+
+	if _, w.err = w.file.Write(w.buf[:]); w.err != nil {
+		return
+	}
+
+	if w.records++; w.records >= w.every {
+		w.err = w.snapshot()
+	} else if w.sync.changed() {
+		w.commit()
+	}
+}
+
+// commit syncs the log if there are pending changes.
+func (w *wal) commit() {
+	if w.sync.pending > 0 {
+		w.flush()
+	}
+}
+
+// flush syncs the log.
+func (w *wal) flush() {
+	if w.err == nil {
+		w.err = w.file.Sync()
+		w.sync.pending = 0
+	}
+}
+
+// snapshot writes the state to a temporary file, renames it over the
+// snapshot and truncates the log, so a crash at any point leaves either the
+// previous or the new snapshot, each with a log that replays correctly.
+func (w *wal) snapshot() error {
+	tmp := w.snapPath() + ".tmp"
+
+	if err := writeFileSync(tmp, w.b.appendBinary(nil)); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, w.snapPath()); err != nil {
+		return err
+	}
+
+	if err := syncDir(filepath.Dir(w.path)); err != nil {
+		return err
+	}
+
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := w.file.Seek(0, 0); err != nil {
+		return err
+	}
+
+	w.records = 0
+	w.sync.pending = 0
+
+	return nil
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = f.Sync()
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
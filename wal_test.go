@@ -0,0 +1,158 @@
+package bitarray
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100)
+	assert.NoError(err)
+
+	d.Mark(5)
+	d.MarkFree()
+	d.Unmark(5)
+	d.MarkAll(70, 71)
+	d.MapBlocks(func(w uint64) uint64 { return w | 1<<40 })
+	assert.NoError(d.Sync())
+	// simulate a crash: the log is not closed
+
+	r, err := Recover(path, 0)
+	assert.NoError(err)
+	assert.Equal(d.String(), r.String())
+	assert.Equal(4, r.Len())
+	assert.NoError(r.Validate())
+	assert.NoError(r.Close())
+	assert.NoError(d.Close())
+}
+
+func TestRecoverGrowReset(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 10, WithAutoGrow())
+	assert.NoError(err)
+
+	d.Mark(3)
+	d.Reset()
+	d.Mark(500)
+	assert.NoError(d.Close())
+
+	r, err := Recover(path, 10)
+	assert.NoError(err)
+	assert.Equal(501, r.Cap())
+	assert.Equal("500", r.FormatRanges())
+	assert.NoError(r.Close())
+}
+
+func TestRecoverSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100, WithSnapshotEvery(3))
+	assert.NoError(err)
+
+	d.MarkAll(1, 2, 3, 4)
+	assert.NoError(d.Close())
+
+	info, err := os.Stat(path + ".wal")
+	assert.NoError(err)
+	assert.Equal(int64(walRecordLen), info.Size())
+
+	r, err := Recover(path, 100)
+	assert.NoError(err)
+	assert.Equal("1-4", r.FormatRanges())
+
+	assert.NoError(r.UnmarshalBinary(mustMarshal(t, newMarked(10, 9))))
+	assert.NoError(r.Close())
+
+	r, err = Recover(path, 100)
+	assert.NoError(err)
+	assert.Equal(10, r.Cap())
+	assert.Equal("9", r.FormatRanges())
+	assert.NoError(r.Close())
+}
+
+func TestRecoverTornRecord(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100)
+	assert.NoError(err)
+	d.MarkAll(1, 2)
+	assert.NoError(d.Close())
+
+	f, err := os.OpenFile(path+".wal", os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(err)
+	f.Write([]byte{walMark, 3, 0, 0})
+	f.Close()
+
+	r, err := Recover(path, 100)
+	assert.NoError(err)
+	assert.Equal("1-2", r.FormatRanges())
+
+	r.Mark(3)
+	assert.NoError(r.Close())
+
+	r, err = Recover(path, 100)
+	assert.NoError(err)
+	assert.Equal("1-3", r.FormatRanges())
+	assert.NoError(r.Close())
+}
+
+func mustMarshal(t *testing.T, b *BitArray) []byte {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestRecoverFill(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100)
+	assert.NoError(err)
+
+	d.Fill()
+	d.Unmark(50)
+	assert.NoError(d.Close())
+
+	r, err := Recover(path, 0)
+	assert.NoError(err)
+	assert.Equal("0-49,51-99", r.FormatRanges())
+	assert.NoError(r.Close())
+}
+
+func TestRecoverCopyFrom(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100)
+	assert.NoError(err)
+
+	d.MarkAll(1, 2, 90)
+	d.CopyFrom(newMarked(100, 2, 64))
+	assert.Equal("2,64", d.FormatRanges())
+	assert.NoError(d.Close())
+
+	r, err := Recover(path, 0)
+	assert.NoError(err)
+	assert.Equal("2,64", r.FormatRanges())
+	assert.Equal(2, r.Len())
+	assert.NoError(r.Close())
+}
@@ -0,0 +1,171 @@
+package bitarray
+
+// watchBuffer is the number of changes buffered for a watcher.
+const watchBuffer = 1024
+
+// Change is a change of a bit reported by Watch and Subscribe.
+type Change struct {
+	Index int64 // index of the bit
+	Mark  bool  // new value of the bit
+
+	// Dropped is the number of changes dropped before this one because the
+	// buffer of the watcher was full.
+	Dropped int64
+}
+
+// watch is the journal of a watcher. It keeps a copy of the watched blocks,
+// so the changes of bulk operations are found by comparing with it.
+type watch struct {
+	b        *BitArray
+	from, to int64
+	first    int64 // block of from
+	shadow   []BitBlock
+	ch       chan Change
+	dropped  int64
+	wait     bool // block the writers rather than dropping changes
+}
+
+// Watch returns a channel receiving the changes of the bits in the half-open
+// range [from, to), in the order they are made. Up to 1024 changes are
+// buffered; while the buffer is full, further changes are dropped and
+// counted in the Dropped field of the next delivered change, so a slow
+// receiver may resynchronize by reading the range. Bulk operations report
+// the bits they actually changed. The channel is closed by Unwatch.
+func (b *BitArray) Watch(from, to int64) <-chan Change {
+	from = max(b.in(from), 0)
+	to = max(b.in(to), from)
+
+	w := &watch{
+		b:     b,
+		from:  from,
+		to:    to,
+		first: from / blockSize,
+		ch:    make(chan Change, watchBuffer),
+	}
+
+	b.lock()
+	defer b.unlock()
+
+	if from < to {
+		w.grow(b.capacity.Get64()) // the range may end far beyond the capacity
+		w.sync(func(Change) {})
+	}
+
+	b.attach(w)
+
+	return w.ch
+}
+
+// Unwatch stops the watcher of Watch that receives from ch and closes the
+// channel.
+func (b *BitArray) Unwatch(ch <-chan Change) {
+	b.lock()
+	defer b.unlock()
+
+	var js journals
+
+	switch j := b.journal.(type) {
+	case journals:
+		js = j
+
+	default:
+		js = journals{j}
+	}
+
+	for _, j := range js {
+		if w, ok := j.(*watch); ok && w.ch == ch {
+			b.detach(w)
+			close(w.ch)
+
+			return
+		}
+	}
+}
+
+func (w *watch) bit(index int64, mark bool) {
+	if index < w.from || index >= w.to {
+		return
+	}
+
+	i, j := bitIndexAndNum(index)
+
+	if mark == bitBlockMark {
+		w.shadow[i-w.first].mark(j)
+	} else {
+		w.shadow[i-w.first].unmark(j)
+	}
+
+	w.send(Change{Index: index, Mark: mark})
+}
+
+func (w *watch) block(i int64, _ BitBlock) {
+	if k := i - w.first; k >= 0 && k < int64(len(w.shadow)) {
+		w.diff(i)
+	}
+}
+
+func (w *watch) reset() {
+	w.sync(w.send)
+}
+
+func (w *watch) grow(capacity int64) {
+	// the new bits are clear, but a watcher following the capacity needs
+	// copies of their blocks
+	if n := (min(w.to, capacity)-1)/blockSize - w.first + 1; n > int64(len(w.shadow)) {
+		w.shadow = append(w.shadow, make([]BitBlock, n-int64(len(w.shadow)))...)
+	}
+}
+
+func (w *watch) replace() {
+	w.grow(w.b.capacity.Get64())
+	w.sync(w.send)
+}
+
+// sync compares all the watched blocks with the copy.
+func (w *watch) sync(send func(Change)) {
+	for k := range w.shadow {
+		w.diffWith(w.first+int64(k), send)
+	}
+}
+
+func (w *watch) diff(i int64) {
+	w.diffWith(i, w.send)
+}
+
+// diffWith reports the watched bits of block i that differ from the copy to
+// send and updates the copy.
+func (w *watch) diffWith(i int64, send func(Change)) {
+	var block BitBlock
+	if i < w.b.size {
+		block = w.b.blocks[i] & rangeMask(i, w.from, w.to)
+	}
+
+	k := i - w.first
+
+	for x := block ^ w.shadow[k]; x != 0; x &= x - 1 {
+		j := x.ffs()
+		send(Change{Index: (i * blockSize) + j, Mark: block.value(j)})
+	}
+
+	w.shadow[k] = block
+}
+
+// send delivers c unless the buffer is full, or waits for room in the
+// buffer if the watcher is told to.
+func (w *watch) send(c Change) {
+	c.Index += w.b.base
+	c.Dropped = w.dropped
+
+	if w.wait {
+		w.ch <- c
+		return
+	}
+
+	select {
+	case w.ch <- c:
+		w.dropped = 0
+
+	default:
+		w.dropped++
+	}
+}
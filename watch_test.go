@@ -0,0 +1,123 @@
+package bitarray
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func receive(ch <-chan Change) (res []Change) {
+	for {
+		select {
+		case c := <-ch:
+			res = append(res, c)
+		default:
+			return
+		}
+	}
+}
+
+func TestBitArrayWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	b := newMarked(200, 10)
+	ch := b.Watch(10, 100)
+
+	b.Mark(5)
+	b.Mark(20)
+	b.Unmark(10)
+	b.Mark(100)
+	assert.Equal([]Change{{Index: 20, Mark: true}, {Index: 10}}, receive(ch))
+
+	assert.Equal(int64(0), b.MarkFree())
+	assert.Empty(receive(ch))
+
+	b.MarkAll(1, 2, 3, 4, 6, 7, 8, 9)
+	assert.Equal(int64(10), b.MarkFree())
+	assert.Equal([]Change{{Index: 10, Mark: true}}, receive(ch))
+
+	b.Or(newMarked(200, 30, 150))
+	b.Reset()
+	assert.Equal([]Change{
+		{Index: 30, Mark: true},
+		{Index: 10},
+		{Index: 20},
+		{Index: 30},
+	}, receive(ch))
+
+	b.Unwatch(ch)
+	b.Mark(50)
+
+	_, ok := <-ch
+	assert.False(ok)
+	assert.Nil(b.journal)
+}
+
+func TestBitArrayWatchOpenEnded(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(100, WithAutoGrow())
+	ch := b.Watch(50, math.MaxInt64)
+	assert.Len(b.journal.(*watch).shadow, int(99/blockSize-50/blockSize+1))
+
+	b.Mark(60)
+	b.Mark(1000) // grows
+	b.Or(newMarked(100, 70))
+	b.Reset()
+
+	assert.Equal([]Change{
+		{Index: 60, Mark: true},
+		{Index: 1000, Mark: true},
+		{Index: 70, Mark: true},
+		{Index: 60},
+		{Index: 70},
+		{Index: 1000},
+	}, receive(ch))
+
+	b.Unwatch(ch)
+
+	// a range beyond the capacity
+	ch = b.Watch(5000, math.MaxInt64)
+	b.Mark(6000)
+	assert.Equal([]Change{{Index: 6000, Mark: true}}, receive(ch))
+}
+
+func TestBitArrayWatchDropped(t *testing.T) {
+	assert := assert.New(t)
+
+	b := NewBitArray(2 * watchBuffer)
+	ch := b.Watch(0, 2*watchBuffer)
+
+	for i := int64(0); i < watchBuffer+5; i++ {
+		b.Mark(i)
+	}
+
+	assert.Len(receive(ch), watchBuffer)
+
+	b.Unmark(0)
+	assert.Equal([]Change{{Index: 0, Dropped: 5}}, receive(ch))
+	b.Unwatch(ch)
+}
+
+func TestRecoverWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "state")
+
+	d, err := Recover(path, 100)
+	assert.NoError(err)
+
+	ch := d.Watch(0, 100)
+	d.Mark(1)
+	assert.NoError(d.Close())
+	d.Mark(2)
+	assert.Equal([]Change{{Index: 1, Mark: true}, {Index: 2, Mark: true}}, receive(ch))
+	d.Unwatch(ch)
+
+	r, err := Recover(path, 0)
+	assert.NoError(err)
+	assert.Equal("1", r.FormatRanges())
+	assert.NoError(r.Close())
+}